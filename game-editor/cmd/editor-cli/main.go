@@ -0,0 +1,187 @@
+// Command editor-cli exposes the game editor services without the GUI so that
+// scripts and CI jobs can read and batch-edit a project.
+//
+// Usage:
+//
+//	editor-cli -project <dir> <resource> <action> [id] [-input file.json] [-cascade]
+//
+// Resources are pokemon, moves, trainers, trainerclasses, types, maps,
+// tilesets, overworlds, recovery, history, snapshots and project; running it
+// without arguments lists the actions of each. Results are written to stdout
+// as JSON, payloads are read from -input or stdin. Reports with errors, like
+// the one of project validate, exit with status 1.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+// errUsage is returned when the command line cannot be dispatched
+var errUsage = errors.New("usage: editor-cli -project <dir> <resource> <action> [id] [-input file.json] [-cascade]")
+
+// failer is implemented by results that should fail the command even though
+// the action itself succeeded, like a validation report with errors
+type failer interface {
+	HasErrors() bool
+}
+
+func main() {
+	// The editor services log progress with fmt.Printf, keep stdout for JSON only
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+
+	if err := run(os.Args[1:], os.Stdin, stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage())
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("editor-cli", flag.ContinueOnError)
+	projectDirectory := flags.String("project", ".", "path to the project directory")
+	inputPath := flags.String("input", "-", "JSON payload of the actions that read one, - reads stdin")
+	cascade := flags.Bool("cascade", false, "pokemon delete also removes the party pokemon, evolutions and encounters using the species")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	positional := flags.Args()
+	if len(positional) < 2 {
+		return errUsage
+	}
+	// Allow flags after the positional arguments as well
+	if err := flags.Parse(positional[2:]); err != nil {
+		return errUsage
+	}
+	resourceName, actionName := positional[0], positional[1]
+	var id string
+	if rest := flags.Args(); len(rest) > 0 {
+		id = rest[0]
+		if err := flags.Parse(rest[1:]); err != nil {
+			return errUsage
+		}
+	}
+
+	if info, err := os.Stat(*projectDirectory); err != nil || !info.IsDir() {
+		return fmt.Errorf("project directory %s does not exist", *projectDirectory)
+	}
+
+	cli := newServices(core.NewProjectApp(*projectDirectory))
+	resource, ok := cli.resources()[resourceName]
+	if !ok {
+		return fmt.Errorf("%w\nunknown resource %q, expected one of: %s", errUsage, resourceName, strings.Join(resourceNames, ", "))
+	}
+	action, ok := resource[actionName]
+	if !ok {
+		return fmt.Errorf("%s does not support %q, expected one of:\n%s", resourceName, actionName, actionUsage(resourceName, resource))
+	}
+	if action.needsID && id == "" {
+		return fmt.Errorf("%s %s requires an id, usage: %s", resourceName, actionName, action.synopsis(resourceName, actionName))
+	}
+
+	request := actionRequest{id: id, cascade: *cascade}
+	if action.readsInput {
+		payload, err := readInput(*inputPath, stdin)
+		if err != nil {
+			return err
+		}
+		request.payload = payload
+	}
+
+	if resourceName != "recovery" {
+		if pending, err := cli.app.PendingRecoveries(); err == nil && len(pending) > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %d interrupted save(s) found, see editor-cli recovery list\n", len(pending))
+		}
+	}
+
+	result, err := call(action.run, request)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	if result, ok := result.(failer); ok && result.HasErrors() {
+		return fmt.Errorf("%s %s found errors", resourceName, actionName)
+	}
+	return nil
+}
+
+// usage lists every action with its arguments. The actions are only listed,
+// never run, so they need no services.
+func usage() string {
+	resources := (&services{}).resources()
+	var b strings.Builder
+	for _, resourceName := range resourceNames {
+		b.WriteString(actionUsage(resourceName, resources[resourceName]))
+	}
+	return b.String()
+}
+
+// actionUsage lists the actions of one resource, one per line
+func actionUsage(resourceName string, resource map[string]action) string {
+	names := make([]string, 0, len(resource))
+	for name := range resource {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s\n", resource[name].synopsis(resourceName, name))
+	}
+	return b.String()
+}
+
+// synopsis is the command line of an action after the flags, e.g.
+// "trainers dropvariant <id> {name}"
+func (a action) synopsis(resourceName string, actionName string) string {
+	parts := []string{resourceName, actionName}
+	if a.needsID {
+		parts = append(parts, "<id>")
+	}
+	if a.usage != "" {
+		parts = append(parts, a.usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	var payload []byte
+	var err error
+	if path == "-" {
+		payload, err = io.ReadAll(stdin)
+	} else {
+		payload, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading input: %w", err)
+	}
+	if !json.Valid(payload) {
+		return nil, fmt.Errorf("input is not valid JSON")
+	}
+	return payload, nil
+}
+
+// call runs an action, turning panics from the editor services into errors
+func call(action actionFunc, request actionRequest) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return action(request)
+}
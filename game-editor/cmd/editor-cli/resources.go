@@ -0,0 +1,586 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	parsing "github.com/zenith110/pokemon-engine-tools/parsing"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	battleSimulator "github.com/zenith110/pokemon-engine-tools/tools/battle-simulator"
+	decompImporter "github.com/zenith110/pokemon-engine-tools/tools/decomp-importer"
+	"github.com/zenith110/pokemon-engine-tools/tools/export"
+	mapEditor "github.com/zenith110/pokemon-engine-tools/tools/map-editor"
+	moveEditor "github.com/zenith110/pokemon-engine-tools/tools/move-editor"
+	overworldEditor "github.com/zenith110/pokemon-engine-tools/tools/overworld-editor"
+	pokemonEditor "github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor"
+	trainerEditor "github.com/zenith110/pokemon-engine-tools/tools/trainer-editor"
+	typeEditor "github.com/zenith110/pokemon-engine-tools/tools/type-editor"
+	"github.com/zenith110/pokemon-engine-tools/tools/validator"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+var resourceNames = []string{"pokemon", "moves", "trainers", "trainerclasses", "types", "maps", "tilesets", "overworlds", "recovery", "history", "snapshots", "project"}
+
+type actionRequest struct {
+	id      string
+	payload []byte
+	cascade bool
+}
+
+type actionFunc func(request actionRequest) (any, error)
+
+// action is an actionFunc with what it expects on the command line, run
+// checks the id and reads the payload from it and usage lists it
+type action struct {
+	// needsID actions refuse to run without the id argument
+	needsID bool
+	// readsInput actions decode a JSON payload from -input or stdin
+	readsInput bool
+	// usage describes the optional id and the payload, and what the action
+	// does when its name does not say it
+	usage string
+	run   actionFunc
+}
+
+// services holds the same editor services that main.go registers with Wails
+type services struct {
+	app             *core.App
+	parsing         *parsing.ParsingApp
+	mapEditor       *mapEditor.MapEditorApp
+	moveEditor      *moveEditor.MoveEditorApp
+	pokemonEditor   *pokemonEditor.PokemonEditorApp
+	trainerEditor   *trainerEditor.TrainerEditorApp
+	overworldEditor *overworldEditor.OverworldEditorApp
+	validator       *validator.ValidatorApp
+	export          *export.ExportApp
+	decompImporter  *decompImporter.DecompImporterApp
+	battleSimulator *battleSimulator.BattleSimulatorApp
+	typeEditor      *typeEditor.TypeEditorApp
+}
+
+func newServices(app *core.App) *services {
+	return &services{
+		app:             app,
+		parsing:         parsing.NewParsingApp(app),
+		mapEditor:       mapEditor.NewMapEditorApp(app),
+		moveEditor:      moveEditor.NewMoveEditorApp(app),
+		pokemonEditor:   pokemonEditor.NewPokemonEditorApp(app),
+		trainerEditor:   trainerEditor.NewTrainerEditorApp(app),
+		overworldEditor: overworldEditor.NewOverworldEditorApp(app),
+		validator:       validator.NewValidatorApp(app),
+		export:          export.NewExportApp(app),
+		decompImporter:  decompImporter.NewDecompImporterApp(app),
+		battleSimulator: battleSimulator.NewBattleSimulatorApp(app),
+		typeEditor:      typeEditor.NewTypeEditorApp(app),
+	}
+}
+
+func (s *services) resources() map[string]map[string]action {
+	return map[string]map[string]action{
+		"pokemon": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.parsing.ParsePokemonData()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				return s.parsing.LoadPokemonById(request.id)
+			}},
+			"create": {readsInput: true, usage: "{species} allocates the next numeric ID", run: func(request actionRequest) (any, error) {
+				var species coreModels.SpeciesJson
+				if err := decode(request, &species); err != nil {
+					return nil, err
+				}
+				id, err := s.pokemonEditor.CreatePokemon(species)
+				return map[string]any{"id": id, "species": species}, err
+			}},
+			"update": {needsID: true, readsInput: true, usage: "{species}", run: func(request actionRequest) (any, error) {
+				var species coreModels.SpeciesJson
+				if err := decode(request, &species); err != nil {
+					return nil, err
+				}
+				return species, s.pokemonEditor.UpdatePokemon(request.id, species)
+			}},
+			"delete": {needsID: true, usage: "[-cascade] also removes the party pokemon, evolutions and encounters using it", run: func(request actionRequest) (any, error) {
+				return request.id, s.pokemonEditor.DeletePokemon(request.id, request.cascade)
+			}},
+			"clone": {needsID: true, usage: "copies the species under the next ID", run: func(request actionRequest) (any, error) {
+				return s.pokemonEditor.ClonePokemon(request.id)
+			}},
+			"references": {needsID: true, usage: "lists the trainers, evolutions and encounters using it", run: func(request actionRequest) (any, error) {
+				return s.pokemonEditor.GetPokemonReferences(request.id)
+			}},
+		},
+		"moves": {
+			"list": {run: func(actionRequest) (any, error) {
+				moves, err := s.moveEditor.ParseMoves()
+				return moves.Move, err
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				return repository.NewMoveRepository(s.app.DataDirectory).Find(request.id)
+			}},
+			"update": {readsInput: true, usage: "[id] {move}", run: func(request actionRequest) (any, error) {
+				var move coreModels.UpdatedMove
+				if err := decode(request, &move); err != nil {
+					return nil, err
+				}
+				if move.Id == "" {
+					move.Id = request.id
+				}
+				return move, s.moveEditor.UpdateMove(move)
+			}},
+			"create": {readsInput: true, usage: "{move} assigns one past the highest ID when it has none", run: func(request actionRequest) (any, error) {
+				var move Models.Move
+				if err := decode(request, &move); err != nil {
+					return nil, err
+				}
+				id, err := s.moveEditor.CreateMove(move)
+				move.ID = id
+				return move, err
+			}},
+			"delete": {needsID: true, usage: "refused while species or trainers use it", run: func(request actionRequest) (any, error) {
+				return request.id, s.moveEditor.DeleteMove(request.id)
+			}},
+			"damage": {needsID: true, readsInput: true, usage: "{attacker, defender, weather, critical}", run: func(request actionRequest) (any, error) {
+				var calculation battleSimulator.DamageCalculation
+				if err := decode(request, &calculation); err != nil {
+					return nil, err
+				}
+				move, err := repository.NewMoveRepository(s.app.DataDirectory).Find(request.id)
+				if err != nil {
+					return nil, err
+				}
+				calculation.Move = move.Name
+				return s.battleSimulator.CalculateDamage(calculation)
+			}},
+		},
+		"trainers": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.parsing.ParseTrainers()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				trainers, err := s.parsing.ParseTrainers()
+				if err != nil {
+					return nil, err
+				}
+				for _, trainer := range trainers {
+					if trainer.Id == request.id {
+						return trainer, nil
+					}
+				}
+				return nil, notFound("trainer", request.id)
+			}},
+			"create": {readsInput: true, usage: "{trainer} assigns a UUID when it has no id", run: func(request actionRequest) (any, error) {
+				var trainer coreModels.TrainerJson
+				if err := decode(request, &trainer); err != nil {
+					return nil, err
+				}
+				id, err := s.trainerEditor.CreateTrainerData(trainer)
+				trainer.Id = id
+				return trainer, err
+			}},
+			"update": {readsInput: true, usage: "[id] {trainer}", run: func(request actionRequest) (any, error) {
+				var trainer coreModels.TrainerJson
+				if err := decode(request, &trainer); err != nil {
+					return nil, err
+				}
+				if trainer.Id == "" {
+					trainer.Id = request.id
+				}
+				return trainer, s.trainerEditor.UpdateTrainer(trainer)
+			}},
+			"delete": {needsID: true, run: func(request actionRequest) (any, error) {
+				return request.id, s.trainerEditor.DeleteTrainer(request.id)
+			}},
+			"duplicate": {needsID: true, usage: "copies the trainer under a new UUID", run: func(request actionRequest) (any, error) {
+				return s.trainerEditor.DuplicateTrainer(request.id)
+			}},
+			"reorder": {readsInput: true, usage: "{ids} lists every trainer in its new order", run: func(request actionRequest) (any, error) {
+				var order struct {
+					IDs []string `json:"ids"`
+				}
+				if err := decode(request, &order); err != nil {
+					return nil, err
+				}
+				return order, s.trainerEditor.ReorderTrainers(order.IDs)
+			}},
+			"showdown": {needsID: true, usage: "prints the party as a Showdown paste", run: func(request actionRequest) (any, error) {
+				return s.trainerEditor.ExportTrainerShowdown(request.id)
+			}},
+			"import": {needsID: true, readsInput: true, usage: "{paste} replaces the party, fails on unknown names or values out of range", run: func(request actionRequest) (any, error) {
+				var team struct {
+					Paste string `json:"paste"`
+				}
+				if err := decode(request, &team); err != nil {
+					return nil, err
+				}
+				return s.trainerEditor.ImportTrainerShowdown(request.id, team.Paste)
+			}},
+			"recompute": {usage: "derives the stats of every party pokemon that stores its IVs again", run: func(actionRequest) (any, error) {
+				return s.trainerEditor.RecomputeTrainerStats()
+			}},
+			"variant": {needsID: true, readsInput: true, usage: "{name, levelOffset, evolve, upgradeMoves, save}", run: func(request actionRequest) (any, error) {
+				var options trainerEditor.VariantOptions
+				if err := decode(request, &options); err != nil {
+					return nil, err
+				}
+				return s.trainerEditor.GeneratePartyVariant(request.id, options)
+			}},
+			"dropvariant": {needsID: true, readsInput: true, usage: "{name}", run: func(request actionRequest) (any, error) {
+				var variant struct {
+					Name string `json:"name"`
+				}
+				if err := decode(request, &variant); err != nil {
+					return nil, err
+				}
+				return variant, s.trainerEditor.DeletePartyVariant(request.id, variant.Name)
+			}},
+			"ai": {needsID: true, usage: "prints the AI once the class profile is applied", run: func(request actionRequest) (any, error) {
+				return s.trainerEditor.GetTrainerAI(request.id)
+			}},
+			"setai": {needsID: true, readsInput: true, usage: "{flags, switching, items}", run: func(request actionRequest) (any, error) {
+				var profile coreModels.AIProfile
+				if err := decode(request, &profile); err != nil {
+					return nil, err
+				}
+				return profile, s.trainerEditor.UpdateTrainerAI(request.id, profile)
+			}},
+			"simulate": {needsID: true, readsInput: true, usage: "{party, playerTrainerId, variant, battles, seed}", run: func(request actionRequest) (any, error) {
+				var options battleSimulator.SimulationOptions
+				if err := decode(request, &options); err != nil {
+					return nil, err
+				}
+				options.TrainerID = request.id
+				return s.battleSimulator.SimulateBattles(options)
+			}},
+			"exportai": {usage: "writes trainerai.toml for the engine", run: func(actionRequest) (any, error) {
+				count, err := s.trainerEditor.ExportTrainerAI()
+				return map[string]any{"trainers": count}, err
+			}},
+		},
+		"trainerclasses": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.trainerEditor.GetTrainerClasses()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				return repository.NewTrainerClassRepository(s.app.DataDirectory).Find(request.id)
+			}},
+			"create": {readsInput: true, usage: "{class}", run: func(request actionRequest) (any, error) {
+				var class coreModels.TrainerClass
+				if err := decode(request, &class); err != nil {
+					return nil, err
+				}
+				return class, s.trainerEditor.CreateTrainerClass(class)
+			}},
+			"update": {readsInput: true, usage: "[name] {class} moves the trainers along on a rename", run: func(request actionRequest) (any, error) {
+				var class coreModels.TrainerClass
+				if err := decode(request, &class); err != nil {
+					return nil, err
+				}
+				name := request.id
+				if name == "" {
+					name = class.Name
+				}
+				moved, err := s.trainerEditor.UpdateTrainerClass(name, class)
+				return map[string]any{"class": class, "trainersMoved": moved}, err
+			}},
+			"delete": {needsID: true, usage: "refused while trainers use it", run: func(request actionRequest) (any, error) {
+				return request.id, s.trainerEditor.DeleteTrainerClass(request.id)
+			}},
+		},
+		"types": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.typeEditor.GetTypes()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				definitions, err := s.typeEditor.GetTypes()
+				if err != nil {
+					return nil, err
+				}
+				for _, definition := range definitions {
+					if strings.EqualFold(definition.Name, request.id) {
+						return definition, nil
+					}
+				}
+				return nil, &repository.Error{Kind: repository.ErrNotFound, File: "types.toml", ID: request.id}
+			}},
+			"create": {readsInput: true, usage: "{type}", run: func(request actionRequest) (any, error) {
+				var definition coreModels.TypeDefinition
+				if err := decode(request, &definition); err != nil {
+					return nil, err
+				}
+				return definition, s.typeEditor.CreateType(definition)
+			}},
+			"update": {readsInput: true, usage: "[name] {type} moves pokemon, moves and multipliers along on a rename", run: func(request actionRequest) (any, error) {
+				var definition coreModels.TypeDefinition
+				if err := decode(request, &definition); err != nil {
+					return nil, err
+				}
+				name := request.id
+				if name == "" {
+					name = definition.Name
+				}
+				moved, err := s.typeEditor.UpdateType(name, definition)
+				return map[string]any{"type": definition, "recordsMoved": moved}, err
+			}},
+			"delete": {needsID: true, usage: "refused while pokemon or moves use it", run: func(request actionRequest) (any, error) {
+				return request.id, s.typeEditor.DeleteType(request.id)
+			}},
+		},
+		"maps": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.parsing.GetAllMaps()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				id, err := strconv.Atoi(request.id)
+				if err != nil {
+					return nil, fmt.Errorf("map id must be a number, got %q", request.id)
+				}
+				return s.parsing.GetMapTomlByID(id)
+			}},
+			"create": {readsInput: true, usage: "{map}", run: func(request actionRequest) (any, error) {
+				var mapData coreModels.Map
+				if err := decode(request, &mapData); err != nil {
+					return nil, err
+				}
+				return serviceResult(s.mapEditor.CreateMap(coreModels.MapEditerMapData{Map: []coreModels.Map{mapData}}))
+			}},
+			"update": {readsInput: true, usage: "[id] {map}", run: func(request actionRequest) (any, error) {
+				var mapData coreModels.Map
+				if err := decode(request, &mapData); err != nil {
+					return nil, err
+				}
+				if request.id != "" {
+					id, err := strconv.Atoi(request.id)
+					if err != nil {
+						return nil, fmt.Errorf("map id must be a number, got %q", request.id)
+					}
+					mapData.ID = id
+				}
+				return serviceResult(s.mapEditor.UpdateTomlMapEntryByID(mapData))
+			}},
+			"delete": {needsID: true, run: func(request actionRequest) (any, error) {
+				id, err := strconv.Atoi(request.id)
+				if err != nil {
+					return nil, fmt.Errorf("map id must be a number, got %q", request.id)
+				}
+				return serviceResult(s.mapEditor.DeleteMapByID(id))
+			}},
+		},
+		"tilesets": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.parsing.GetAllTilesets()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				return repository.NewTilesetRepository(s.app.DataDirectory).Find(request.id)
+			}},
+			"create": {readsInput: true, usage: "{tileset}", run: func(request actionRequest) (any, error) {
+				var tileset coreModels.CreateNewTileset
+				if err := decode(request, &tileset); err != nil {
+					return nil, err
+				}
+				return serviceResult(s.mapEditor.CreateTileset(tileset))
+			}},
+			"update": {readsInput: true, usage: "[name] {tileset}", run: func(request actionRequest) (any, error) {
+				var tileset coreModels.Tileset
+				if err := decode(request, &tileset); err != nil {
+					return nil, err
+				}
+				name := request.id
+				if name == "" {
+					name = tileset.Name
+				}
+				return serviceResult(s.mapEditor.UpdateTileset(name, tileset))
+			}},
+			"delete": {needsID: true, run: func(request actionRequest) (any, error) {
+				return serviceResult(s.mapEditor.DeleteTileset(request.id))
+			}},
+		},
+		"overworlds": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.overworldEditor.ParseOverworldData()
+			}},
+			"get": {needsID: true, run: func(request actionRequest) (any, error) {
+				overworlds, err := s.overworldEditor.ParseOverworldData()
+				if err != nil {
+					return nil, err
+				}
+				for _, overworld := range overworlds {
+					if overworld.ID == request.id {
+						return overworld, nil
+					}
+				}
+				return nil, notFound("overworld", request.id)
+			}},
+			"create": {readsInput: true, usage: "{overworld}", run: func(request actionRequest) (any, error) {
+				var overworld coreModels.OverworldDataJson
+				if err := decode(request, &overworld); err != nil {
+					return nil, err
+				}
+				return overworld, s.overworldEditor.CreateOverworldTomlEntry(overworld)
+			}},
+			"update": {readsInput: true, usage: "[id] {overworld}", run: func(request actionRequest) (any, error) {
+				var overworld coreModels.OverworldDataJson
+				if err := decode(request, &overworld); err != nil {
+					return nil, err
+				}
+				if request.id != "" {
+					overworld.ID = request.id
+				}
+				return overworld, s.overworldEditor.UpdateOverworldTomlEntry(overworld)
+			}},
+			"delete": {needsID: true, run: func(request actionRequest) (any, error) {
+				return request.id, s.overworldEditor.DeleteOverworld(request.id)
+			}},
+		},
+		"recovery": {
+			"list": {usage: "lists interrupted saves", run: func(actionRequest) (any, error) {
+				return s.app.PendingRecoveries()
+			}},
+			"restore": {needsID: true, usage: "takes the temp file path", run: func(request actionRequest) (any, error) {
+				return request.id, s.app.ResolveRecovery(request.id, true)
+			}},
+			"discard": {needsID: true, usage: "takes the temp file path", run: func(request actionRequest) (any, error) {
+				return request.id, s.app.ResolveRecovery(request.id, false)
+			}},
+		},
+		"history": {
+			"list": {run: func(actionRequest) (any, error) {
+				return s.app.History()
+			}},
+			"undo": {run: func(actionRequest) (any, error) {
+				return s.app.Undo()
+			}},
+			"redo": {run: func(actionRequest) (any, error) {
+				return s.app.Redo()
+			}},
+		},
+		"snapshots": {
+			"list": {usage: "[file or type/id]", run: func(request actionRequest) (any, error) {
+				if request.id == "" {
+					return s.app.Snapshots()
+				}
+				// A record is named type/id, e.g. pokemon/1, anything else is a file
+				if entityType, id, ok := strings.Cut(request.id, "/"); ok && !strings.Contains(id, "/") && !strings.Contains(request.id, ".") {
+					return s.app.EntityHistory(entityType, id)
+				}
+				return s.app.FileHistory(request.id)
+			}},
+			"create": {readsInput: true, usage: "{message}", run: func(request actionRequest) (any, error) {
+				var snapshot struct {
+					Message string `json:"message"`
+				}
+				if err := decode(request, &snapshot); err != nil {
+					return nil, err
+				}
+				return s.app.CommitSnapshot(snapshot.Message)
+			}},
+			"diff": {usage: "[from..to] to defaults to the working files", run: func(request actionRequest) (any, error) {
+				from, to, _ := strings.Cut(request.id, "..")
+				if from == "" {
+					from = "HEAD"
+				}
+				return s.app.DiffSnapshots(from, to)
+			}},
+			"restore": {needsID: true, readsInput: true, usage: "{file} or {type, id}", run: func(request actionRequest) (any, error) {
+				var target struct {
+					File string `json:"file"`
+					Type string `json:"type"`
+					ID   string `json:"id"`
+				}
+				if err := decode(request, &target); err != nil {
+					return nil, err
+				}
+				if target.File != "" {
+					return target, s.app.RestoreFile(request.id, target.File)
+				}
+				return target, s.app.RestoreRecord(request.id, target.Type, target.ID)
+			}},
+		},
+		"project": {
+			"validate": {usage: "fails when it finds errors", run: func(actionRequest) (any, error) {
+				return s.validator.ValidateProject()
+			}},
+			"list": {run: func(actionRequest) (any, error) {
+				return s.app.Projects.Projects()
+			}},
+			"check": {usage: "reports projects whose folder is missing", run: func(actionRequest) (any, error) {
+				return s.app.CheckProjects()
+			}},
+			"rename": {needsID: true, readsInput: true, usage: "{name}", run: func(request actionRequest) (any, error) {
+				var project struct {
+					Name string `json:"name"`
+				}
+				if err := decode(request, &project); err != nil {
+					return nil, err
+				}
+				return project, s.app.RenameProject(request.id, project.Name)
+			}},
+			"relocate": {needsID: true, readsInput: true, usage: "{folder}", run: func(request actionRequest) (any, error) {
+				var project struct {
+					Folder string `json:"folder"`
+				}
+				if err := decode(request, &project); err != nil {
+					return nil, err
+				}
+				if project.Folder == "" {
+					return nil, fmt.Errorf("project relocate requires a folder")
+				}
+				return project, s.app.RelocateProject(request.id, project.Folder)
+			}},
+			"remove": {needsID: true, usage: "unregisters the project, its files are kept", run: func(request actionRequest) (any, error) {
+				return request.id, s.app.RemoveProject(request.id)
+			}},
+			"create": {readsInput: true, usage: "{name, directory, template}", run: func(request actionRequest) (any, error) {
+				var project coreModels.ProjectCreation
+				if err := decode(request, &project); err != nil {
+					return nil, err
+				}
+				return s.app.CreateProjectFromTemplate(project)
+			}},
+			"upgrade": {readsInput: true, usage: "{source, ref, base, dryRun, resolutions} fails on conflicts", run: func(request actionRequest) (any, error) {
+				var upgrade core.EngineUpgrade
+				if err := decode(request, &upgrade); err != nil {
+					return nil, err
+				}
+				return s.app.UpgradeEngine(upgrade)
+			}},
+			"export": {readsInput: true, usage: "{destination, version, compact, previous} fails when validation stops it", run: func(request actionRequest) (any, error) {
+				var options export.ExportOptions
+				if err := decode(request, &options); err != nil {
+					return nil, err
+				}
+				return s.export.ExportProject(options)
+			}},
+			"import": {readsInput: true, usage: "{source, dryRun, replace} converts a pokeemerald or pokefirered checkout", run: func(request actionRequest) (any, error) {
+				var decompImport decompImporter.DecompImport
+				if err := decode(request, &decompImport); err != nil {
+					return nil, err
+				}
+				return s.decompImporter.ImportDecomp(decompImport)
+			}},
+		},
+	}
+}
+
+func decode(request actionRequest, v any) error {
+	if err := json.Unmarshal(request.payload, v); err != nil {
+		return fmt.Errorf("error decoding input: %w", err)
+	}
+	return nil
+}
+
+func notFound(resource string, id string) error {
+	return fmt.Errorf("%s with ID %s not found", resource, id)
+}
+
+// serviceResult converts the success/errorMessage maps returned by the map
+// editor services into an error
+func serviceResult(result map[string]any) (any, error) {
+	if success, _ := result["success"].(bool); !success {
+		return nil, fmt.Errorf("%v", result["errorMessage"])
+	}
+	return result, nil
+}
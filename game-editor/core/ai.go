@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+)
+
+// AIFlag is a battle behaviour the engine's trainer AI can be given
+type AIFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AIFlags are the AI flags the engine knows, in the order of their bits in
+// trainerai.toml's flagMask. The editor defines this order and the engine
+// reads it, so new flags go at the end and existing ones never move.
+var AIFlags = []AIFlag{
+	{"check-bad-move", "avoids moves that fail or do nothing"},
+	{"try-to-faint", "prefers moves that knock the target out"},
+	{"check-viability", "scores moves by how useful they are right now"},
+	{"setup-first", "uses stat raising and status moves early"},
+	{"risky", "prefers high damage moves with drawbacks"},
+	{"prefer-strongest-move", "prefers the move that deals the most damage"},
+	{"prefer-baton-pass", "sets up and passes the boosts on with Baton Pass"},
+	{"double-battle", "targets and supports in double battles"},
+	{"hp-aware", "picks moves based on its own and the target's HP"},
+	{"try-sunny-day-start", "opens with Sunny Day"},
+}
+
+// Switch modes of an AI profile, the engine uses basic when none is set
+const (
+	SwitchNever = "never"
+	SwitchBasic = "basic"
+	SwitchSmart = "smart"
+)
+
+// SwitchModes are the switching logics a trainer AI can use
+var SwitchModes = []string{SwitchNever, SwitchBasic, SwitchSmart}
+
+// MaxAIItems is how many different items a trainer can use in battle and
+// MaxAIItemCount how often it can use one of them
+const (
+	MaxAIItems     = 4
+	MaxAIItemCount = 99
+)
+
+// AIFlagBit returns the bit of flag in a flag mask, or -1 for an unknown flag
+func AIFlagBit(flag string) int {
+	for bit, known := range AIFlags {
+		if known.Name == flag {
+			return bit
+		}
+	}
+	return -1
+}
+
+// CheckAIProfile returns the problems of an AI profile: unknown flags or
+// switch modes, repeated items and item counts out of range
+func CheckAIProfile(profile coreModels.AIProfile) []string {
+	var problems []string
+	seen := make(map[string]bool)
+	for _, flag := range profile.Flags {
+		if AIFlagBit(flag) == -1 {
+			problems = append(problems, fmt.Sprintf("AI flag %q does not exist", flag))
+		} else if seen[flag] {
+			problems = append(problems, fmt.Sprintf("AI flag %q is set twice", flag))
+		}
+		seen[flag] = true
+	}
+	if profile.Switching != "" {
+		known := false
+		for _, mode := range SwitchModes {
+			known = known || mode == profile.Switching
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("switch mode %q does not exist, use one of %s", profile.Switching, strings.Join(SwitchModes, ", ")))
+		}
+	}
+	if len(profile.Items) > MaxAIItems {
+		problems = append(problems, fmt.Sprintf("%d items are set, at most %d are allowed", len(profile.Items), MaxAIItems))
+	}
+	items := make(map[string]bool)
+	for _, item := range profile.Items {
+		key := strings.ToLower(item.Name)
+		switch {
+		case item.Name == "":
+			problems = append(problems, "an AI item has no name")
+		case items[key]:
+			problems = append(problems, fmt.Sprintf("item %s is listed twice", item.Name))
+		}
+		items[key] = true
+		if item.Count < 1 || item.Count > MaxAIItemCount {
+			problems = append(problems, fmt.Sprintf("item %s can be used %d times, it has to be between 1 and %d", item.Name, item.Count, MaxAIItemCount))
+		}
+	}
+	return problems
+}
+
+// ResolveAIProfile applies a trainer's AI profile to its class's. The trainer
+// adds flags to the class's, and its switch mode and items replace the
+// class's when set. Either profile may be nil.
+func ResolveAIProfile(class *coreModels.AIProfile, trainer *coreModels.AIProfile) coreModels.AIProfile {
+	resolved := coreModels.AIProfile{Flags: []string{}, Switching: SwitchBasic, Items: []coreModels.AIItem{}}
+	set := make(map[string]bool)
+	for _, profile := range []*coreModels.AIProfile{class, trainer} {
+		if profile == nil {
+			continue
+		}
+		for _, flag := range profile.Flags {
+			set[flag] = true
+		}
+		if profile.Switching != "" {
+			resolved.Switching = profile.Switching
+		}
+		if len(profile.Items) > 0 {
+			resolved.Items = profile.Items
+		}
+	}
+	for _, flag := range AIFlags {
+		if set[flag.Name] {
+			resolved.Flags = append(resolved.Flags, flag.Name)
+		}
+	}
+	return resolved
+}
+
+// AIFlagMask packs known flags into the bit mask the engine reads, bit n for
+// the nth flag of AIFlags. Unknown flags are left out.
+func AIFlagMask(flags []string) int {
+	mask := 0
+	for _, flag := range flags {
+		if bit := AIFlagBit(flag); bit != -1 {
+			mask |= 1 << bit
+		}
+	}
+	return mask
+}
@@ -3,48 +3,76 @@ package core
 import (
 	"context"
 	"fmt"
-	"io"
-	"os"
-
-	"github.com/pelletier/go-toml/v2"
-	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+	"strings"
 )
 
 // App struct
 type App struct {
 	Ctx           context.Context
 	DataDirectory string
+	Journal       *Journal
+	Watcher       *ProjectWatcher
+	Index         *ProjectIndex
+	Projects      *ProjectRegistry
+	// Events is set by main once the Wails application exists
+	Events EventEmitter
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	app := &App{}
+	app := &App{Projects: NewProjectRegistry()}
+	app.Journal = newJournal(app)
+	app.Watcher = newProjectWatcher(app)
+	app.Index = newProjectIndex(app)
 
 	// Load the last used project on app creation
-	projectLastUpdated, err := os.OpenFile("lastused.toml", os.O_CREATE, 0644)
+	project, ok, err := app.Projects.LastUsed()
 	if err != nil {
-		os.Create("lastused.toml")
-	} else {
-		defer projectLastUpdated.Close()
-		var projectsData Models.Project
-		b, err := io.ReadAll(projectLastUpdated)
-		if err != nil {
-			fmt.Printf("error while reading projects is %v", err)
-		} else {
-			err = toml.Unmarshal(b, &projectsData)
-			if err != nil {
-				fmt.Printf("error while unmarshaling last used project: %v", err)
-			} else if projectsData.FolderLocation != "" {
-				app.DataDirectory = projectsData.FolderLocation
-				fmt.Printf("Loaded last used project: %s at %s", projectsData.Name, projectsData.FolderLocation)
-			}
-		}
+		fmt.Printf("error while reading last used project: %v\n", err)
+	} else if ok && checkProjectFolder(project.FolderLocation) != ProjectHealthy {
+		fmt.Printf("not opening last used project %s: %s\n", project.Name, projectHealthMessage(checkProjectFolder(project.FolderLocation)))
+	} else if ok {
+		app.DataDirectory = project.FolderLocation
+		fmt.Printf("Loaded last used project: %s at %s\n", project.Name, project.FolderLocation)
+		app.openProject()
 	}
 
 	return app
 }
 
+// NewProjectApp creates an App bound to the project at dataDirectory without
+// opening the last used project, for use outside of the GUI
+func NewProjectApp(dataDirectory string) *App {
+	app := &App{
+		DataDirectory: strings.TrimSuffix(strings.ReplaceAll(dataDirectory, "\\", "/"), "/"),
+		Projects:      NewProjectRegistry(),
+	}
+	app.Journal = newJournal(app)
+	app.Watcher = newProjectWatcher(app)
+	app.Index = newProjectIndex(app)
+	return app
+}
+
 // Startup initializes the app with context (for Wails v3 compatibility)
 func (a *App) Startup(ctx context.Context) {
 	a.Ctx = ctx
 }
+
+// ServiceShutdown stops watching the project when the application exits
+func (a *App) ServiceShutdown() error {
+	a.Watcher.Stop()
+	return nil
+}
+
+// openProject points the project watcher and the index at the current project
+func (a *App) openProject() {
+	if a.DataDirectory == "" {
+		return
+	}
+	if err := a.Watcher.Watch(a.DataDirectory); err != nil {
+		fmt.Printf("error while watching project %s: %v\n", a.DataDirectory, err)
+	}
+	if err := a.Index.Build(); err != nil {
+		fmt.Printf("error while indexing project %s: %v\n", a.DataDirectory, err)
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// AssetURLPrefix is where the asset server serves the project's data/assets
+// folder, the frontend uses the URLs returned by AssetURL as they are
+const AssetURLPrefix = "/project-assets/"
+
+// assetContentTypes covers the audio formats Go does not know on every platform
+var assetContentTypes = map[string]string{
+	".wav": "audio/wav",
+	".ogg": "audio/ogg",
+	".mp3": "audio/mpeg",
+}
+
+// PokemonAssetFiles are the sprites, icon and cry of a species, relative to
+// data/assets. They are found by the species' ID.
+type PokemonAssetFiles struct {
+	Front      string
+	Back       string
+	ShinyFront string
+	ShinyBack  string
+	Icon       string
+	Cry        string
+}
+
+// PokemonAssets returns where the assets of the species with the given ID are
+func PokemonAssets(id string) PokemonAssetFiles {
+	return PokemonAssetFiles{
+		Front:      fmt.Sprintf("pokemon/front/%s_front.png", id),
+		Back:       fmt.Sprintf("pokemon/back/%s_back.png", id),
+		ShinyFront: fmt.Sprintf("pokemon/shinyfront/%s_front_shiny.png", id),
+		ShinyBack:  fmt.Sprintf("pokemon/shinyback/%s_shiny_back.png", id),
+		Icon:       fmt.Sprintf("pokemon/icons/%s/%s.gif", id, id),
+		Cry:        fmt.Sprintf("pokemon/cries/%s.wav", id),
+	}
+}
+
+// All lists the files in a fixed order
+func (f PokemonAssetFiles) All() []string {
+	return []string{f.Front, f.Back, f.ShinyFront, f.ShinyBack, f.Icon, f.Cry}
+}
+
+// AssetURL returns the URL of a file under data/assets of the project at
+// dataDirectory, e.g. AssetURL(dir, "pokemon/front/1_front.png"). The URL is
+// versioned with the file's modification time so it can be cached for good.
+// It is empty when the file does not exist.
+func AssetURL(dataDirectory string, asset string) string {
+	info, err := os.Stat(fmt.Sprintf("%s/data/assets/%s", dataDirectory, asset))
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	segments := strings.Split(asset, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s%s?v=%s", AssetURLPrefix, strings.Join(segments, "/"), strconv.FormatInt(info.ModTime().UnixNano(), 36))
+}
+
+// AssetServer serves the current project's assets to the frontend, with
+// caching headers and range requests so audio can seek without loading the
+// whole track
+type AssetServer struct {
+	app *App
+}
+
+// NewAssetServer creates a new AssetServer struct
+func NewAssetServer(app *App) *AssetServer {
+	return &AssetServer{
+		app: app,
+	}
+}
+
+// Middleware serves requests under AssetURLPrefix and passes everything else
+// on to next, it fits Wails' AssetOptions.Middleware
+func (s *AssetServer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, AssetURLPrefix) {
+			s.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *AssetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	asset := strings.TrimPrefix(r.URL.Path, AssetURLPrefix)
+	// Reject anything that would escape data/assets
+	if asset == "" || strings.Contains(asset, "\\") || path.Clean("/"+asset) != "/"+asset {
+		http.NotFound(w, r)
+		return
+	}
+	if s.app.DataDirectory == "" {
+		http.Error(w, "no project selected", http.StatusNotFound)
+		return
+	}
+	file, err := os.Open(fmt.Sprintf("%s/data/assets/%s", s.app.DataDirectory, asset))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Versioned URLs never change, unversioned ones are checked every time
+	if r.URL.Query().Get("v") != "" {
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("ETag", fmt.Sprintf("\"%x-%x\"", info.ModTime().UnixNano(), info.Size()))
+	if contentType, ok := assetContentTypes[strings.ToLower(path.Ext(asset))]; ok {
+		w.Header().Set("Content-Type", contentType)
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
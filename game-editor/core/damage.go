@@ -0,0 +1,109 @@
+package core
+
+import (
+	"slices"
+	"strings"
+)
+
+// Random damage factors of the mainline games, in percent
+const (
+	MinDamageRoll = 85
+	MaxDamageRoll = 100
+)
+
+// Weathers that change the damage of a hit
+const (
+	WeatherSun       = "sun"
+	WeatherRain      = "rain"
+	WeatherSandstorm = "sandstorm"
+	WeatherSnow      = "snow"
+)
+
+// Weathers lists the weathers in the order the editors offer them
+var Weathers = []string{WeatherSun, WeatherRain, WeatherSandstorm, WeatherSnow}
+
+// MaxStatStage is how far a stat can be raised or lowered in battle
+const MaxStatStage = 6
+
+// DamageInput is one hit of a damaging move. Attack and Defense are the
+// attacker's attacking and the defender's defending stat for the move's kind,
+// Weather is the multiplier of the weather with 0 counting as no weather.
+type DamageInput struct {
+	Level         int
+	Power         int
+	Attack        int
+	Defense       int
+	STAB          bool
+	Effectiveness float64
+	Weather       float64
+	Critical      bool
+}
+
+// Damage returns the damage of a hit with the mainline formula for a random
+// factor between MinDamageRoll and MaxDamageRoll. A hit that is not
+// ineffective deals at least 1.
+func Damage(input DamageInput, roll int) int {
+	if input.Power <= 0 || input.Effectiveness == 0 {
+		return 0
+	}
+	defense := max(input.Defense, 1)
+	damage := (2*input.Level/5+2)*input.Power*input.Attack/defense/50 + 2
+	if input.Weather != 0 {
+		damage = int(float64(damage) * input.Weather)
+	}
+	if input.Critical {
+		damage = damage * 3 / 2
+	}
+	damage = damage * roll / 100
+	if input.STAB {
+		damage = damage * 3 / 2
+	}
+	damage = int(float64(damage) * input.Effectiveness)
+	return max(damage, 1)
+}
+
+// DamageRolls returns the damage of a hit for every random factor, from the
+// lowest roll to the highest
+func DamageRolls(input DamageInput) []int {
+	rolls := make([]int, 0, MaxDamageRoll-MinDamageRoll+1)
+	for roll := MinDamageRoll; roll <= MaxDamageRoll; roll++ {
+		rolls = append(rolls, Damage(input, roll))
+	}
+	return rolls
+}
+
+// WeatherModifier returns the multiplier weather gives a move of moveType,
+// sun strengthens fire and weakens water moves and rain does the opposite
+func WeatherModifier(weather string, moveType string) float64 {
+	switch {
+	case weather == WeatherSun && strings.EqualFold(moveType, "fire"), weather == WeatherRain && strings.EqualFold(moveType, "water"):
+		return 1.5
+	case weather == WeatherSun && strings.EqualFold(moveType, "water"), weather == WeatherRain && strings.EqualFold(moveType, "fire"):
+		return 0.5
+	}
+	return 1
+}
+
+// WeatherDefense returns the multiplier weather gives the defending stat of a
+// pokemon of the given types, sandstorm raises the special defense of rock
+// types and snow the defense of ice types
+func WeatherDefense(weather string, types []string, special bool) float64 {
+	hasType := func(name string) bool {
+		return slices.ContainsFunc(types, func(pokemonType string) bool { return strings.EqualFold(pokemonType, name) })
+	}
+	switch {
+	case weather == WeatherSandstorm && special && hasType("rock"), weather == WeatherSnow && !special && hasType("ice"):
+		return 1.5
+	}
+	return 1
+}
+
+// StatStage returns a stat raised or lowered by a stage between
+// -MaxStatStage and MaxStatStage, stages out of range are clamped
+func StatStage(stat int, stage int) int {
+	stage = min(max(stage, -MaxStatStage), MaxStatStage)
+	if stage >= 0 {
+		return stat * (2 + stage) / 2
+	}
+	return stat * 2 / (2 - stage)
+}
@@ -0,0 +1,104 @@
+package core
+
+import "testing"
+
+func TestDamage(t *testing.T) {
+	// An ice move with STAB, 4x effective, as in the mainline damage example
+	iceFang := DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, STAB: true, Effectiveness: 4}
+	plain := DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 1}
+	tests := []struct {
+		name  string
+		input DamageInput
+		roll  int
+		want  int
+	}{
+		{name: "lowest roll", input: iceFang, roll: MinDamageRoll, want: 168},
+		{name: "highest roll", input: iceFang, roll: MaxDamageRoll, want: 196},
+		{name: "no modifiers", input: plain, roll: MaxDamageRoll, want: 33},
+		{name: "critical hit", input: DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 1, Critical: true}, roll: MaxDamageRoll, want: 49},
+		{name: "weather", input: DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 1, Weather: 0.5}, roll: MaxDamageRoll, want: 16},
+		{name: "no weather", input: DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 1, Weather: 0}, roll: MaxDamageRoll, want: 33},
+		{name: "not very effective", input: DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 0.5}, roll: MaxDamageRoll, want: 16},
+		{name: "ineffective", input: DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, Effectiveness: 0}, roll: MaxDamageRoll, want: 0},
+		{name: "status move", input: DamageInput{Level: 75, Attack: 123, Defense: 163, Effectiveness: 1}, roll: MaxDamageRoll, want: 0},
+		{name: "at least 1", input: DamageInput{Level: 1, Power: 10, Attack: 1, Defense: 500, Effectiveness: 0.25}, roll: MinDamageRoll, want: 1},
+		{name: "no defense", input: DamageInput{Level: 50, Power: 50, Attack: 100, Effectiveness: 1}, roll: MaxDamageRoll, want: 2202},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Damage(test.input, test.roll); got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDamageRolls(t *testing.T) {
+	rolls := DamageRolls(DamageInput{Level: 75, Power: 65, Attack: 123, Defense: 163, STAB: true, Effectiveness: 4})
+	if len(rolls) != MaxDamageRoll-MinDamageRoll+1 {
+		t.Fatalf("got %d rolls, want %d", len(rolls), MaxDamageRoll-MinDamageRoll+1)
+	}
+	if rolls[0] != 168 || rolls[len(rolls)-1] != 196 {
+		t.Errorf("rolls go from %d to %d, want 168 to 196", rolls[0], rolls[len(rolls)-1])
+	}
+}
+
+func TestWeather(t *testing.T) {
+	modifiers := []struct {
+		weather  string
+		moveType string
+		want     float64
+	}{
+		{WeatherSun, "Fire", 1.5},
+		{WeatherSun, "water", 0.5},
+		{WeatherRain, "water", 1.5},
+		{WeatherRain, "fire", 0.5},
+		{WeatherSandstorm, "fire", 1},
+		{"", "water", 1},
+	}
+	for _, test := range modifiers {
+		if got := WeatherModifier(test.weather, test.moveType); got != test.want {
+			t.Errorf("WeatherModifier(%q, %q) = %v, want %v", test.weather, test.moveType, got, test.want)
+		}
+	}
+
+	defenses := []struct {
+		weather string
+		types   []string
+		special bool
+		want    float64
+	}{
+		{WeatherSandstorm, []string{"Rock", "Ground"}, true, 1.5},
+		{WeatherSandstorm, []string{"rock"}, false, 1},
+		{WeatherSnow, []string{"ice"}, false, 1.5},
+		{WeatherSnow, []string{"ice"}, true, 1},
+		{WeatherSun, []string{"rock"}, true, 1},
+	}
+	for _, test := range defenses {
+		if got := WeatherDefense(test.weather, test.types, test.special); got != test.want {
+			t.Errorf("WeatherDefense(%q, %v, %v) = %v, want %v", test.weather, test.types, test.special, got, test.want)
+		}
+	}
+}
+
+func TestStatStage(t *testing.T) {
+	tests := []struct {
+		stage int
+		want  int
+	}{
+		{0, 100},
+		{1, 150},
+		{2, 200},
+		{6, 400},
+		{8, 400},
+		{-1, 66},
+		{-2, 50},
+		{-6, 25},
+		{-8, 25},
+	}
+	for _, test := range tests {
+		if got := StatStage(100, test.stage); got != test.want {
+			t.Errorf("StatStage(100, %d) = %d, want %d", test.stage, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !headless
+
+// Package dialogs opens the native file dialogs of the editor window. Building
+// with the headless tag, as editor-cli does, leaves Wails out and every
+// dialog fails with ErrHeadless.
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ErrHeadless is returned when there is no window to open a dialog in
+var ErrHeadless = errors.New("file dialogs need the editor window")
+
+// Directory asks for a folder, the path uses forward slashes and is empty
+// when the dialog was cancelled
+func Directory(ctx context.Context, title string) (string, error) {
+	selection, err := runtime.OpenDirectoryDialog(ctx, runtime.OpenDialogOptions{
+		Title: title,
+	})
+	return strings.ReplaceAll(selection, "\\", "/"), err
+}
+
+// File asks for a file matching pattern, e.g. "*.png", described as
+// displayName in the dialog's filter list
+func File(ctx context.Context, title string, displayName string, pattern string) (string, error) {
+	selection, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
+		Title: title,
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: displayName,
+				Pattern:     pattern,
+			},
+		},
+	})
+	return strings.ReplaceAll(selection, "\\", "/"), err
+}
@@ -0,0 +1,21 @@
+//go:build headless
+
+package dialogs
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrHeadless is returned when there is no window to open a dialog in
+var ErrHeadless = errors.New("file dialogs need the editor window")
+
+// Directory fails, a headless build has no window
+func Directory(ctx context.Context, title string) (string, error) {
+	return "", ErrHeadless
+}
+
+// File fails, a headless build has no window
+func File(ctx context.Context, title string, displayName string, pattern string) (string, error) {
+	return "", ErrHeadless
+}
@@ -3,16 +3,22 @@ module github.com/zenith110/pokemon-engine-tools/tools-core
 go 1.22.2
 
 require (
+	github.com/fsnotify/fsnotify v1.9.0
 	github.com/google/uuid v1.6.0
 	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/wailsapp/wails/v2 v2.10.1
 	github.com/zenith110/pokemon-engine-tools/models v0.0.0-00010101000000-000000000000
-	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250223140129-f44c17210e27
+	github.com/zenith110/pokemon-engine-tools/repository v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8
 	gopkg.in/src-d/go-git.v4 v4.13.1
 )
 
 replace github.com/zenith110/pokemon-engine-tools/models => ../models
+
 replace github.com/zenith110/pokemon-engine-tools/core => ../core
+
+replace github.com/zenith110/pokemon-engine-tools/repository => ../repository
+
 require (
 	github.com/Microsoft/go-winio v0.6.1 // indirect
 	github.com/emirpasic/gods v1.18.1 // indirect
@@ -0,0 +1,331 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// defaultPageSize and maxPageSize bound SearchRecords pages
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// IndexEntry is one record of the project index. Record is the parsed TOML
+// record, e.g. a Models.Pokemon, and is shared, callers must not modify it.
+type IndexEntry struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Record any    `json:"record"`
+}
+
+// IndexPage is one page of search results
+type IndexPage struct {
+	Items    []IndexEntry `json:"items"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+}
+
+// indexSource is a data file the index reads one entity type from
+type indexSource struct {
+	entityType string
+	file       string
+	decode     func(dataDirectory string, data []byte) ([]IndexEntry, error)
+	repository func(dataDirectory string) recordRestorer
+}
+
+// recordRestorer is the part of the typed repositories snapshots restore
+// single records with
+type recordRestorer interface {
+	Restore(data []byte, id string) error
+	Location() string
+}
+
+// location is the source's data file relative to the project, e.g.
+// data/toml/moves.toml
+func (s indexSource) location() string {
+	return s.repository("").Location()
+}
+
+var indexSources = []indexSource{
+	{"pokemon", "pokemon.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewPokemonRepository(dataDirectory).Decode(data)
+		return indexEntries("pokemon", records, err, func(r Models.Pokemon) (string, string) { return r.ID, r.Species })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewPokemonRepository(dataDirectory)
+	}},
+	{"moves", "moves.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewMoveRepository(dataDirectory).Decode(data)
+		return indexEntries("moves", records, err, func(r Models.Move) (string, string) { return strconv.Itoa(r.ID), r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewMoveRepository(dataDirectory)
+	}},
+	{"trainers", "trainers.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewTrainerRepository(dataDirectory).Decode(data)
+		return indexEntries("trainers", records, err, func(r coreModels.Trainer) (string, string) { return r.ID, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewTrainerRepository(dataDirectory)
+	}},
+	{"trainerclasses", "trainerclasses.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewTrainerClassRepository(dataDirectory).Decode(data)
+		return indexEntries("trainerclasses", records, err, func(r coreModels.TrainerClass) (string, string) { return r.Name, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewTrainerClassRepository(dataDirectory)
+	}},
+	{"types", "types.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewTypeRepository(dataDirectory).Decode(data)
+		return indexEntries("types", records, err, func(r coreModels.TypeDefinition) (string, string) { return r.Name, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewTypeRepository(dataDirectory)
+	}},
+	{"helditems", "helditems.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewHeldItemRepository(dataDirectory).Decode(data)
+		return indexEntries("helditems", records, err, func(r Models.HeldItems) (string, string) { return r.Name, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewHeldItemRepository(dataDirectory)
+	}},
+	{"maps", "maps.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewMapRepository(dataDirectory).Decode(data)
+		return indexEntries("maps", records, err, func(r coreModels.Map) (string, string) { return strconv.Itoa(r.ID), r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewMapRepository(dataDirectory)
+	}},
+	{"tilesets", "tilesets.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewTilesetRepository(dataDirectory).Decode(data)
+		return indexEntries("tilesets", records, err, func(r coreModels.Tileset) (string, string) { return r.Name, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewTilesetRepository(dataDirectory)
+	}},
+	{"overworlds", "overworlds.toml", func(dataDirectory string, data []byte) ([]IndexEntry, error) {
+		records, err := repository.NewOverworldRepository(dataDirectory).Decode(data)
+		return indexEntries("overworlds", records, err, func(r Models.Overworld) (string, string) { return r.ID, r.Name })
+	}, func(dataDirectory string) recordRestorer {
+		return repository.NewOverworldRepository(dataDirectory)
+	}},
+}
+
+func indexEntries[R any](entityType string, records []R, err error, describe func(R) (string, string)) ([]IndexEntry, error) {
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]IndexEntry, 0, len(records))
+	for _, record := range records {
+		id, name := describe(record)
+		entries = append(entries, IndexEntry{Type: entityType, ID: id, Name: name, Record: record})
+	}
+	return entries, nil
+}
+
+// indexTable holds the records of one data file in file order
+type indexTable struct {
+	file    string
+	entries []IndexEntry
+	byID    map[string]int
+	err     error
+}
+
+// ProjectIndex keeps every record of the current project in memory, keyed by
+// entity type and ID. It is built once per project and afterwards only the
+// data file that was written or changed on disk is parsed again.
+type ProjectIndex struct {
+	app *App
+
+	mu        sync.RWMutex
+	directory string
+	tables    map[string]*indexTable
+}
+
+func newProjectIndex(app *App) *ProjectIndex {
+	index := &ProjectIndex{app: app}
+	repository.AddWriteHook(index.written)
+	app.Watcher.Subscribe(index.changed)
+	return index
+}
+
+// Build parses every data file of the current project
+func (x *ProjectIndex) Build() error {
+	directory := x.app.DataDirectory
+	if directory == "" {
+		return errors.New("no project selected")
+	}
+	tables := make(map[string]*indexTable)
+	for _, source := range indexSources {
+		tables[source.entityType] = loadIndexTable(directory, source)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.directory = directory
+	x.tables = tables
+	return nil
+}
+
+func loadIndexTable(directory string, source indexSource) *indexTable {
+	data, err := os.ReadFile(fmt.Sprintf("%s/%s", directory, source.location()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return newIndexTable(source, nil, nil)
+	}
+	if err != nil {
+		return newIndexTable(source, nil, &repository.Error{Kind: repository.ErrMalformedFile, File: source.file, Err: err})
+	}
+	entries, err := source.decode(directory, data)
+	return newIndexTable(source, entries, err)
+}
+
+func newIndexTable(source indexSource, entries []IndexEntry, err error) *indexTable {
+	table := &indexTable{file: source.file, entries: entries, byID: make(map[string]int, len(entries)), err: err}
+	for position, entry := range entries {
+		if _, ok := table.byID[entry.ID]; !ok {
+			table.byID[entry.ID] = position
+		}
+	}
+	return table
+}
+
+// table returns the records of entityType, building the index first when the
+// project changed since it was built
+func (x *ProjectIndex) table(entityType string) (*indexTable, error) {
+	x.mu.RLock()
+	built := x.directory != "" && x.directory == x.app.DataDirectory
+	table, ok := x.tables[entityType]
+	x.mu.RUnlock()
+	if !built {
+		if err := x.Build(); err != nil {
+			return nil, err
+		}
+		x.mu.RLock()
+		table, ok = x.tables[entityType]
+		x.mu.RUnlock()
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown record type %q", entityType)
+	}
+	if table.err != nil {
+		return nil, table.err
+	}
+	return table, nil
+}
+
+// Lookup returns the record of entityType with the given ID
+func (x *ProjectIndex) Lookup(entityType string, id string) (IndexEntry, error) {
+	table, err := x.table(entityType)
+	if err != nil {
+		return IndexEntry{}, err
+	}
+	position, ok := table.byID[id]
+	if !ok {
+		return IndexEntry{}, &repository.Error{Kind: repository.ErrNotFound, File: table.file, ID: id}
+	}
+	return table.entries[position], nil
+}
+
+// All returns every record of entityType in file order
+func (x *ProjectIndex) All(entityType string) ([]IndexEntry, error) {
+	table, err := x.table(entityType)
+	if err != nil {
+		return nil, err
+	}
+	return table.entries, nil
+}
+
+// Search returns the records of entityType whose name or ID starts with
+// prefix, ignoring case, in file order. page starts at 1.
+func (x *ProjectIndex) Search(entityType string, prefix string, page int, pageSize int) (IndexPage, error) {
+	table, err := x.table(entityType)
+	if err != nil {
+		return IndexPage{}, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	prefix = strings.ToLower(prefix)
+	result := IndexPage{Items: []IndexEntry{}, Page: page, PageSize: pageSize}
+	start := (page - 1) * pageSize
+	for _, entry := range table.entries {
+		if !strings.HasPrefix(strings.ToLower(entry.Name), prefix) && !strings.HasPrefix(strings.ToLower(entry.ID), prefix) {
+			continue
+		}
+		if result.Total >= start && len(result.Items) < pageSize {
+			result.Items = append(result.Items, entry)
+		}
+		result.Total++
+	}
+	return result, nil
+}
+
+// written is the repository write hook, it reindexes a data file from the
+// contents the editor just saved
+func (x *ProjectIndex) written(path string, before []byte, after []byte) {
+	x.mu.RLock()
+	directory := x.directory
+	x.mu.RUnlock()
+	if directory == "" {
+		return
+	}
+	for _, source := range indexSources {
+		if cleanPath(path) != cleanPath(fmt.Sprintf("%s/%s", directory, source.location())) {
+			continue
+		}
+		table := newIndexTable(source, nil, nil)
+		if after != nil {
+			entries, err := source.decode(directory, after)
+			table = newIndexTable(source, entries, err)
+		}
+		x.replace(directory, source.entityType, table)
+		return
+	}
+}
+
+// changed reindexes a data file that was changed outside of the editor
+func (x *ProjectIndex) changed(change ProjectDataChange) {
+	x.mu.RLock()
+	directory := x.directory
+	x.mu.RUnlock()
+	if directory == "" {
+		return
+	}
+	for _, source := range indexSources {
+		if change.File == source.location() {
+			x.replace(directory, source.entityType, loadIndexTable(directory, source))
+			return
+		}
+	}
+}
+
+func (x *ProjectIndex) replace(directory string, entityType string, table *indexTable) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	// The project may have been switched while the file was parsed
+	if x.directory == directory {
+		x.tables[entityType] = table
+	}
+}
+
+// LookupRecord returns one record of the current project from the index.
+// entityType is one of pokemon, moves, trainers, trainerclasses, helditems,
+// maps, tilesets or overworlds.
+func (a *App) LookupRecord(entityType string, id string) (IndexEntry, error) {
+	return a.Index.Lookup(entityType, id)
+}
+
+// SearchRecords pages through the records of entityType whose name or ID
+// starts with prefix, an empty prefix lists all of them
+func (a *App) SearchRecords(entityType string, prefix string, page int, pageSize int) (IndexPage, error) {
+	return a.Index.Search(entityType, prefix, page, pageSize)
+}
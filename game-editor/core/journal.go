@@ -0,0 +1,388 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// journalLimit caps how many entries are kept for a project
+const journalLimit = 200
+
+// journalDirectory holds the journal inside a project, hidden from the data
+// folders the editors work on
+const journalDirectory = ".editor/journal"
+
+// FileChange is one file touched by a journal entry. Before and After name
+// snapshots in the journal's blob store, empty when the file did not exist.
+type FileChange struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// JournalEntry is one undoable edit, usually a single service call
+type JournalEntry struct {
+	ID      int          `json:"id"`
+	Label   string       `json:"label"`
+	Time    time.Time    `json:"time"`
+	Changes []FileChange `json:"changes"`
+	Undone  bool         `json:"undone"`
+}
+
+type journalState struct {
+	NextID  int            `json:"nextId"`
+	Entries []JournalEntry `json:"entries"`
+}
+
+// Journal records every change the editors make to the project's files as a
+// before/after snapshot so it can be undone and redone, across restarts
+type Journal struct {
+	app *App
+
+	mu         sync.Mutex
+	directory  string
+	state      journalState
+	group      *JournalEntry
+	groupDepth int
+	replaying  atomic.Bool
+}
+
+func newJournal(app *App) *Journal {
+	journal := &Journal{app: app}
+	repository.AddWriteHook(journal.record)
+	return journal
+}
+
+// Begin groups every write until the returned function is called into one
+// entry, so that e.g. creating a map undoes both its JSON and maps.toml
+//
+//	defer a.app.Journal.Begin("Create map")()
+func (j *Journal) Begin(label string) func() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.groupDepth++
+	if j.groupDepth == 1 {
+		j.group = &JournalEntry{Label: label}
+	}
+	return j.end
+}
+
+func (j *Journal) end() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.groupDepth--
+	if j.groupDepth > 0 {
+		return
+	}
+	group := j.group
+	j.group = nil
+	if group != nil && len(group.Changes) > 0 {
+		if err := j.commit(*group); err != nil {
+			fmt.Printf("error while saving the journal: %v\n", err)
+		}
+	}
+}
+
+// record is the repository write hook
+func (j *Journal) record(path string, before []byte, after []byte) {
+	if j.replaying.Load() {
+		return
+	}
+	relative, ok := j.relative(path)
+	if !ok {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		fmt.Printf("error while loading the journal: %v\n", err)
+		return
+	}
+	beforeBlob, err := j.storeBlob(before)
+	if err != nil {
+		fmt.Printf("error while journaling %s: %v\n", relative, err)
+		return
+	}
+	afterBlob, err := j.storeBlob(after)
+	if err != nil {
+		fmt.Printf("error while journaling %s: %v\n", relative, err)
+		return
+	}
+
+	if j.group == nil {
+		entry := JournalEntry{
+			Label:   fmt.Sprintf("Save %s", relative),
+			Changes: []FileChange{{Path: relative, Before: beforeBlob, After: afterBlob}},
+		}
+		if err := j.commit(entry); err != nil {
+			fmt.Printf("error while saving the journal: %v\n", err)
+		}
+		return
+	}
+	// A file written twice in one group keeps its first before snapshot
+	for index := range j.group.Changes {
+		if j.group.Changes[index].Path == relative {
+			j.group.Changes[index].After = afterBlob
+			return
+		}
+	}
+	j.group.Changes = append(j.group.Changes, FileChange{Path: relative, Before: beforeBlob, After: afterBlob})
+}
+
+// relative maps an absolute path to one inside the current project, skipping
+// files outside of it and the journal's own files
+func (j *Journal) relative(path string) (string, bool) {
+	projectDirectory := j.app.DataDirectory
+	if projectDirectory == "" || !strings.HasPrefix(path, projectDirectory+"/") {
+		return "", false
+	}
+	relative := strings.TrimPrefix(path, projectDirectory+"/")
+	if strings.HasPrefix(relative, ".editor/") {
+		return "", false
+	}
+	return relative, true
+}
+
+// Undo reverts the latest entry that has not been undone yet
+func (j *Journal) Undo() (JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return JournalEntry{}, err
+	}
+	index := -1
+	for i := len(j.state.Entries) - 1; i >= 0; i-- {
+		if !j.state.Entries[i].Undone {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return JournalEntry{}, errors.New("nothing to undo")
+	}
+	entry := &j.state.Entries[index]
+	changes := make([]FileChange, len(entry.Changes))
+	for i, change := range entry.Changes {
+		changes[len(changes)-1-i] = FileChange{Path: change.Path, Before: change.After, After: change.Before}
+	}
+	if err := j.apply(changes); err != nil {
+		return JournalEntry{}, fmt.Errorf("error undoing %q: %w", entry.Label, err)
+	}
+	entry.Undone = true
+	return *entry, j.save()
+}
+
+// Redo reapplies the oldest undone entry
+func (j *Journal) Redo() (JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return JournalEntry{}, err
+	}
+	index := -1
+	for i := range j.state.Entries {
+		if j.state.Entries[i].Undone {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return JournalEntry{}, errors.New("nothing to redo")
+	}
+	entry := &j.state.Entries[index]
+	if err := j.apply(entry.Changes); err != nil {
+		return JournalEntry{}, fmt.Errorf("error redoing %q: %w", entry.Label, err)
+	}
+	entry.Undone = false
+	return *entry, j.save()
+}
+
+// History returns the journal newest first
+func (j *Journal) History() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	history := make([]JournalEntry, 0, len(j.state.Entries))
+	for i := len(j.state.Entries) - 1; i >= 0; i-- {
+		history = append(history, j.state.Entries[i])
+	}
+	return history, nil
+}
+
+// apply moves every file from its Before snapshot to its After snapshot,
+// refusing when a file no longer matches Before because it was edited since
+func (j *Journal) apply(changes []FileChange) error {
+	for _, change := range changes {
+		current, err := os.ReadFile(j.projectPath(change.Path))
+		if errors.Is(err, fs.ErrNotExist) {
+			current = nil
+		} else if err != nil {
+			return err
+		}
+		if blobName(current) != change.Before {
+			return fmt.Errorf("%s was changed since, undo or redo the newer edits first", change.Path)
+		}
+	}
+
+	j.replaying.Store(true)
+	defer j.replaying.Store(false)
+	for _, change := range changes {
+		path := j.projectPath(change.Path)
+		if change.After == "" {
+			if err := repository.RemoveFile(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		data, err := os.ReadFile(j.blobPath(change.After))
+		if err != nil {
+			return fmt.Errorf("snapshot of %s is missing: %w", change.Path, err)
+		}
+		if err := repository.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit appends entry, dropping the redo tail and the oldest entries over
+// journalLimit
+func (j *Journal) commit(entry JournalEntry) error {
+	entries := j.state.Entries[:0]
+	for _, existing := range j.state.Entries {
+		if !existing.Undone {
+			entries = append(entries, existing)
+		}
+	}
+	j.state.NextID++
+	entry.ID = j.state.NextID
+	entry.Time = time.Now().UTC()
+	entries = append(entries, entry)
+	trimmed := len(entries) > journalLimit
+	if trimmed {
+		entries = entries[len(entries)-journalLimit:]
+	}
+	j.state.Entries = entries
+	if err := j.save(); err != nil {
+		return err
+	}
+	if trimmed {
+		j.collectBlobs()
+	}
+	return nil
+}
+
+// load reads the journal of the current project, once per project
+func (j *Journal) load() error {
+	projectDirectory := j.app.DataDirectory
+	if projectDirectory == "" {
+		return errors.New("no project selected")
+	}
+	if j.directory == projectDirectory {
+		return nil
+	}
+	var state journalState
+	b, err := os.ReadFile(j.statePathFor(projectDirectory))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &state); err != nil {
+			return fmt.Errorf("journal is malformed: %w", err)
+		}
+	}
+	j.directory = projectDirectory
+	j.state = state
+	return nil
+}
+
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return repository.WriteFile(j.statePathFor(j.directory), data, 0644)
+}
+
+// storeBlob saves a snapshot under its content hash
+func (j *Journal) storeBlob(data []byte) (string, error) {
+	name := blobName(data)
+	if name == "" {
+		return "", nil
+	}
+	path := j.blobPath(name)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return name, nil
+	}
+	return name, repository.WriteFile(path, data, 0644)
+}
+
+// collectBlobs deletes snapshots no entry refers to anymore
+func (j *Journal) collectBlobs() {
+	referenced := make(map[string]bool)
+	for _, entry := range j.state.Entries {
+		for _, change := range entry.Changes {
+			referenced[change.Before] = true
+			referenced[change.After] = true
+		}
+	}
+	blobs, err := os.ReadDir(fmt.Sprintf("%s/%s/blobs", j.directory, journalDirectory))
+	if err != nil {
+		return
+	}
+	for _, blob := range blobs {
+		if !referenced[blob.Name()] {
+			os.Remove(j.blobPath(blob.Name()))
+		}
+	}
+}
+
+func blobName(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (j *Journal) projectPath(relative string) string {
+	return fmt.Sprintf("%s/%s", j.directory, relative)
+}
+
+func (j *Journal) blobPath(name string) string {
+	return fmt.Sprintf("%s/%s/blobs/%s", j.directory, journalDirectory, name)
+}
+
+func (j *Journal) statePathFor(projectDirectory string) string {
+	return fmt.Sprintf("%s/%s/journal.json", projectDirectory, journalDirectory)
+}
+
+// Undo reverts the most recent edit to the project
+func (a *App) Undo() (JournalEntry, error) {
+	return a.Journal.Undo()
+}
+
+// Redo reapplies the most recently undone edit
+func (a *App) Redo() (JournalEntry, error) {
+	return a.Journal.Redo()
+}
+
+// History lists the project's undoable edits, newest first
+func (a *App) History() ([]JournalEntry, error) {
+	return a.Journal.History()
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// journalProject returns an app on an empty project and a function reading
+// a project file, "-" when it does not exist
+func journalProject(t *testing.T) (*App, func(name string) string) {
+	t.Helper()
+	t.Setenv(RegistryEnvironment, filepath.Join(t.TempDir(), "projects.json"))
+	directory := filepath.ToSlash(t.TempDir())
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(directory, name))
+		if os.IsNotExist(err) {
+			return "-"
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	return NewProjectApp(directory), read
+}
+
+func write(t *testing.T, app *App, name string, data string) {
+	t.Helper()
+	if err := repository.WriteFile(filepath.Join(app.DataDirectory, name), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func historyLabels(t *testing.T, app *App) []string {
+	t.Helper()
+	history, err := app.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := []string{}
+	for _, entry := range history {
+		label := entry.Label
+		if entry.Undone {
+			label += " (undone)"
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	app, read := journalProject(t)
+	write(t, app, "a.toml", "1")
+	func() {
+		defer app.Journal.Begin("Edit both")()
+		write(t, app, "a.toml", "2")
+		write(t, app, "a.toml", "3")
+		write(t, app, "b.toml", "x")
+	}()
+	if got, want := historyLabels(t, app), []string{"Edit both", "Save a.toml"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("history is %v, want %v", got, want)
+	}
+
+	steps := []struct {
+		name  string
+		step  func() (JournalEntry, error)
+		label string
+		err   string
+		a, b  string
+	}{
+		{name: "undo the group", step: app.Undo, label: "Edit both", a: "1", b: "-"},
+		{name: "undo the first save", step: app.Undo, label: "Save a.toml", a: "-", b: "-"},
+		{name: "nothing left to undo", step: app.Undo, err: "nothing to undo", a: "-", b: "-"},
+		{name: "redo the first save", step: app.Redo, label: "Save a.toml", a: "1", b: "-"},
+		{name: "redo the group", step: app.Redo, label: "Edit both", a: "3", b: "x"},
+		{name: "nothing left to redo", step: app.Redo, err: "nothing to redo", a: "3", b: "x"},
+	}
+	for _, step := range steps {
+		entry, err := step.step()
+		switch {
+		case step.err != "" && (err == nil || err.Error() != step.err):
+			t.Errorf("%s: got error %v, want %q", step.name, err, step.err)
+		case step.err == "" && err != nil:
+			t.Errorf("%s: %v", step.name, err)
+		case entry.Label != step.label:
+			t.Errorf("%s: got entry %q, want %q", step.name, entry.Label, step.label)
+		}
+		if a, b := read("a.toml"), read("b.toml"); a != step.a || b != step.b {
+			t.Errorf("%s: files hold %q and %q, want %q and %q", step.name, a, b, step.a, step.b)
+		}
+	}
+}
+
+func TestJournalDropsRedoOnNewEdit(t *testing.T) {
+	app, read := journalProject(t)
+	write(t, app, "a.toml", "1")
+	write(t, app, "a.toml", "2")
+	if _, err := app.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := historyLabels(t, app), []string{"Save a.toml (undone)", "Save a.toml"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("history is %v, want %v", got, want)
+	}
+	write(t, app, "b.toml", "x")
+	if got, want := historyLabels(t, app), []string{"Save b.toml", "Save a.toml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("history is %v, want %v", got, want)
+	}
+	if _, err := app.Redo(); err == nil {
+		t.Error("redo after a new edit succeeded")
+	}
+	if got := read("a.toml"); got != "1" {
+		t.Errorf("a.toml holds %q, want 1", got)
+	}
+}
+
+func TestJournalRefusesFilesChangedSince(t *testing.T) {
+	app, read := journalProject(t)
+	write(t, app, "a.toml", "1")
+	// Edited outside the editor, so not journaled
+	if err := os.WriteFile(filepath.Join(app.DataDirectory, "a.toml"), []byte("outside"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := app.Undo()
+	if err == nil || !strings.Contains(err.Error(), "a.toml was changed since") {
+		t.Errorf("undo returned %v, want a.toml was changed since", err)
+	}
+	if got := read("a.toml"); got != "outside" {
+		t.Errorf("a.toml holds %q after a refused undo, want outside", got)
+	}
+}
+
+func TestJournalSkipsOtherFiles(t *testing.T) {
+	app, _ := journalProject(t)
+	write(t, app, ".editor/state.json", "{}")
+	if err := repository.WriteFile(filepath.Join(t.TempDir(), "elsewhere.toml"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := historyLabels(t, app); len(got) != 0 {
+		t.Errorf("history is %v, want it empty", got)
+	}
+}
+
+func TestJournalPersists(t *testing.T) {
+	app, read := journalProject(t)
+	write(t, app, "a.toml", "1")
+	write(t, app, "a.toml", "2")
+
+	// A new app on the same project, as after a restart
+	reopened := NewProjectApp(app.DataDirectory)
+	app.DataDirectory = ""
+	if _, err := reopened.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("a.toml"); got != "1" {
+		t.Errorf("a.toml holds %q after undoing in a reopened project, want 1", got)
+	}
+}
@@ -0,0 +1,279 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// recordKeys are the fields that identify a record of a TOML array of tables,
+// tried in order as repository.ChangedRecords does
+var recordKeys = []string{"id", "name"}
+
+// MergeConflict is a file, or a record inside a TOML data file, that both the
+// project and the new engine changed differently. Key identifies it when
+// resolving, e.g. data/toml/moves.toml#move/12.
+type MergeConflict struct {
+	Key    string `json:"key"`
+	File   string `json:"file"`
+	Record string `json:"record"`
+	Reason string `json:"reason"`
+}
+
+// mergeOutcome is the merged contents of one file. A nil data removes it.
+type mergeOutcome struct {
+	data      []byte
+	conflicts []MergeConflict
+	// records counts records taken from the new engine
+	records int
+}
+
+// mergeFile merges one file present in the old engine (base), the project
+// (ours) or the new engine (theirs), a nil slice meaning it is absent. Data
+// files are merged record by record, everything else as a whole. Conflicts
+// keep the project's side unless resolutions says "theirs" for their key.
+func mergeFile(file string, base, ours, theirs []byte, resolutions map[string]string) mergeOutcome {
+	switch {
+	case sameFile(ours, theirs):
+		return mergeOutcome{data: ours}
+	case sameFile(base, ours):
+		return mergeOutcome{data: theirs}
+	case sameFile(base, theirs):
+		return mergeOutcome{data: ours}
+	}
+	if ours != nil && theirs != nil && strings.HasSuffix(file, ".toml") {
+		if outcome, ok := mergeTomlFile(file, base, ours, theirs, resolutions); ok {
+			return outcome
+		}
+	}
+
+	reason := "changed in the project and in the engine"
+	switch {
+	case ours == nil:
+		reason = "deleted in the project, changed in the engine"
+	case theirs == nil:
+		reason = "changed in the project, deleted in the engine"
+	case base == nil:
+		reason = "added by both the project and the engine"
+	}
+	conflict := MergeConflict{Key: file, File: file, Reason: reason}
+	if resolutions[conflict.Key] == "theirs" {
+		return mergeOutcome{data: theirs}
+	}
+	if resolutions[conflict.Key] == "ours" {
+		return mergeOutcome{data: ours}
+	}
+	return mergeOutcome{data: ours, conflicts: []MergeConflict{conflict}}
+}
+
+func sameFile(a, b []byte) bool {
+	return (a == nil) == (b == nil) && bytes.Equal(a, b)
+}
+
+// mergeTomlFile merges the top level keys of a TOML file, and arrays of tables
+// record by record. It reports false when a side cannot be parsed so the file
+// is merged as a whole instead.
+func mergeTomlFile(file string, base, ours, theirs []byte, resolutions map[string]string) (mergeOutcome, bool) {
+	var baseDocument, ourDocument, theirDocument map[string]any
+	if base != nil {
+		if err := toml.Unmarshal(base, &baseDocument); err != nil {
+			return mergeOutcome{}, false
+		}
+	}
+	if err := toml.Unmarshal(ours, &ourDocument); err != nil {
+		return mergeOutcome{}, false
+	}
+	if err := toml.Unmarshal(theirs, &theirDocument); err != nil {
+		return mergeOutcome{}, false
+	}
+
+	var outcome mergeOutcome
+	merged := make(map[string]any)
+	for _, key := range unionKeys(baseDocument, ourDocument, theirDocument) {
+		baseValue, inBase := baseDocument[key]
+		ourValue, inOurs := ourDocument[key]
+		theirValue, inTheirs := theirDocument[key]
+		if baseRecords, ourRecords, theirRecords, ok := recordTables(baseValue, ourValue, theirValue); ok {
+			records, taken, conflicts := mergeRecords(file, key, baseRecords, ourRecords, theirRecords, resolutions)
+			outcome.records += taken
+			outcome.conflicts = append(outcome.conflicts, conflicts...)
+			if inOurs || len(records) > 0 {
+				merged[key] = records
+			}
+			continue
+		}
+		value, present, taken, conflict := mergeValue(baseValue, inBase, ourValue, inOurs, theirValue, inTheirs)
+		if conflict {
+			conflictKey := fmt.Sprintf("%s#%s", file, key)
+			switch resolutions[conflictKey] {
+			case "theirs":
+				value, present, taken = theirValue, inTheirs, true
+			case "ours":
+			default:
+				outcome.conflicts = append(outcome.conflicts, MergeConflict{Key: conflictKey, File: file, Record: key, Reason: "changed in the project and in the engine"})
+			}
+		}
+		if taken {
+			outcome.records++
+		}
+		if present {
+			merged[key] = value
+		}
+	}
+
+	// Keep the project's file byte for byte when nothing of the engine's is taken
+	if outcome.records == 0 {
+		outcome.data = ours
+		return outcome, true
+	}
+	data, err := toml.Marshal(merged)
+	if err != nil {
+		return mergeOutcome{}, false
+	}
+	outcome.data = data
+	return outcome, true
+}
+
+// mergeValue three-way merges one value, reporting whether it is present in
+// the result, whether it was taken from the new engine and whether both sides
+// changed it differently, in which case the project's value is returned
+func mergeValue(base any, inBase bool, ours any, inOurs bool, theirs any, inTheirs bool) (any, bool, bool, bool) {
+	switch {
+	case inOurs == inTheirs && reflect.DeepEqual(ours, theirs):
+		return ours, inOurs, false, false
+	case inBase == inOurs && reflect.DeepEqual(base, ours):
+		return theirs, inTheirs, true, false
+	case inBase == inTheirs && reflect.DeepEqual(base, theirs):
+		return ours, inOurs, false, false
+	}
+	return ours, inOurs, false, true
+}
+
+// mergeRecords merges an array of tables by record key. The result keeps the
+// project's order with records new in the engine appended in the engine's
+// order.
+func mergeRecords(file string, table string, base, ours, theirs []map[string]any, resolutions map[string]string) ([]map[string]any, int, []MergeConflict) {
+	baseByKey, ourByKey, theirByKey := recordsByKey(base), recordsByKey(ours), recordsByKey(theirs)
+	order := make([]string, 0, len(ours)+len(theirs))
+	seen := make(map[string]bool)
+	for _, records := range [][]map[string]any{ours, theirs} {
+		for _, record := range records {
+			key := recordKey(record)
+			if !seen[key] {
+				seen[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+	// Records the project deleted are in neither list but may still conflict
+	for _, record := range base {
+		key := recordKey(record)
+		if !seen[key] {
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+
+	var merged []map[string]any
+	var conflicts []MergeConflict
+	taken := 0
+	for _, key := range order {
+		baseRecord, inBase := baseByKey[key]
+		ourRecord, inOurs := ourByKey[key]
+		theirRecord, inTheirs := theirByKey[key]
+		value, present, fromTheirs, conflict := mergeValue(baseRecord, inBase, ourRecord, inOurs, theirRecord, inTheirs)
+		if conflict {
+			conflictKey := fmt.Sprintf("%s#%s/%s", file, table, key)
+			switch resolutions[conflictKey] {
+			case "theirs":
+				value, present, fromTheirs = theirRecord, inTheirs, true
+			case "ours":
+			default:
+				reason := "changed in the project and in the engine"
+				switch {
+				case !inOurs:
+					reason = "deleted in the project, changed in the engine"
+				case !inTheirs:
+					reason = "changed in the project, deleted in the engine"
+				case !inBase:
+					reason = "added by both the project and the engine"
+				}
+				conflicts = append(conflicts, MergeConflict{Key: conflictKey, File: file, Record: fmt.Sprintf("%s/%s", table, key), Reason: reason})
+			}
+		}
+		if fromTheirs {
+			taken++
+		}
+		if present {
+			merged = append(merged, value.(map[string]any))
+		}
+	}
+	return merged, taken, conflicts
+}
+
+// recordTables reports whether the present values are all arrays of tables
+// whose records have unique keys, so they can be merged record by record
+func recordTables(values ...any) (base, ours, theirs []map[string]any, ok bool) {
+	tables := make([][]map[string]any, len(values))
+	found := false
+	for index, value := range values {
+		if value == nil {
+			continue
+		}
+		list, isList := value.([]any)
+		if !isList {
+			return nil, nil, nil, false
+		}
+		keys := make(map[string]bool)
+		for _, item := range list {
+			record, isRecord := item.(map[string]any)
+			if !isRecord {
+				return nil, nil, nil, false
+			}
+			key := recordKey(record)
+			if key == "" || keys[key] {
+				return nil, nil, nil, false
+			}
+			keys[key] = true
+			tables[index] = append(tables[index], record)
+		}
+		found = true
+	}
+	return tables[0], tables[1], tables[2], found
+}
+
+func recordKey(record map[string]any) string {
+	for _, field := range recordKeys {
+		if value, ok := record[field]; ok {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+func recordsByKey(records []map[string]any) map[string]any {
+	byKey := make(map[string]any, len(records))
+	for _, record := range records {
+		byKey[recordKey(record)] = record
+	}
+	return byKey
+}
+
+func unionKeys(documents ...map[string]any) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, document := range documents {
+		for key := range document {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func move(id int, name string) map[string]any {
+	return map[string]any{"id": int64(id), "name": name}
+}
+
+func moves(records ...map[string]any) []map[string]any {
+	return records
+}
+
+func TestMergeRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        []map[string]any
+		ours        []map[string]any
+		theirs      []map[string]any
+		resolutions map[string]string
+		want        []string
+		taken       int
+		conflicts   []string
+	}{
+		{
+			name:   "unchanged",
+			base:   moves(move(1, "Tackle")),
+			ours:   moves(move(1, "Tackle")),
+			theirs: moves(move(1, "Tackle")),
+			want:   []string{"1 Tackle"},
+		},
+		{
+			name:   "changed in the project",
+			base:   moves(move(1, "Tackle")),
+			ours:   moves(move(1, "Slam")),
+			theirs: moves(move(1, "Tackle")),
+			want:   []string{"1 Slam"},
+		},
+		{
+			name:   "changed in the engine",
+			base:   moves(move(1, "Tackle")),
+			ours:   moves(move(1, "Tackle")),
+			theirs: moves(move(1, "Slam")),
+			want:   []string{"1 Slam"},
+			taken:  1,
+		},
+		{
+			name:   "changed the same way on both sides",
+			base:   moves(move(1, "Tackle")),
+			ours:   moves(move(1, "Slam")),
+			theirs: moves(move(1, "Slam")),
+			want:   []string{"1 Slam"},
+		},
+		{
+			name:   "deleted in the project",
+			base:   moves(move(1, "Tackle"), move(2, "Pound")),
+			ours:   moves(move(2, "Pound")),
+			theirs: moves(move(1, "Tackle"), move(2, "Pound")),
+			want:   []string{"2 Pound"},
+		},
+		{
+			name:   "deleted in the engine",
+			base:   moves(move(1, "Tackle"), move(2, "Pound")),
+			ours:   moves(move(1, "Tackle"), move(2, "Pound")),
+			theirs: moves(move(2, "Pound")),
+			want:   []string{"2 Pound"},
+			taken:  1,
+		},
+		{
+			name:      "deleted in the project and changed in the engine",
+			base:      moves(move(1, "Tackle")),
+			ours:      moves(),
+			theirs:    moves(move(1, "Slam")),
+			want:      []string{},
+			conflicts: []string{"moves.toml#move/1 deleted in the project, changed in the engine"},
+		},
+		{
+			name:      "changed in the project and deleted in the engine",
+			base:      moves(move(1, "Tackle")),
+			ours:      moves(move(1, "Slam")),
+			theirs:    moves(),
+			want:      []string{"1 Slam"},
+			conflicts: []string{"moves.toml#move/1 changed in the project, deleted in the engine"},
+		},
+		{
+			name:      "changed differently on both sides",
+			base:      moves(move(1, "Tackle")),
+			ours:      moves(move(1, "Slam")),
+			theirs:    moves(move(1, "Pound")),
+			want:      []string{"1 Slam"},
+			conflicts: []string{"moves.toml#move/1 changed in the project and in the engine"},
+		},
+		{
+			name:   "added the same way on both sides",
+			ours:   moves(move(1, "Tackle")),
+			theirs: moves(move(1, "Tackle")),
+			want:   []string{"1 Tackle"},
+		},
+		{
+			name:      "added differently on both sides",
+			ours:      moves(move(1, "Tackle")),
+			theirs:    moves(move(1, "Pound")),
+			want:      []string{"1 Tackle"},
+			conflicts: []string{"moves.toml#move/1 added by both the project and the engine"},
+		},
+		{
+			name:        "conflict resolved with the engine's side",
+			base:        moves(move(1, "Tackle")),
+			ours:        moves(move(1, "Slam")),
+			theirs:      moves(move(1, "Pound")),
+			resolutions: map[string]string{"moves.toml#move/1": "theirs"},
+			want:        []string{"1 Pound"},
+			taken:       1,
+		},
+		{
+			name:        "conflict resolved with the project's side",
+			base:        moves(move(1, "Tackle")),
+			ours:        moves(move(1, "Slam")),
+			theirs:      moves(move(1, "Pound")),
+			resolutions: map[string]string{"moves.toml#move/1": "ours"},
+			want:        []string{"1 Slam"},
+		},
+		{
+			name:        "deletion resolved with the engine's side",
+			base:        moves(move(1, "Tackle")),
+			ours:        moves(move(1, "Slam")),
+			theirs:      moves(),
+			resolutions: map[string]string{"moves.toml#move/1": "theirs"},
+			want:        []string{},
+			taken:       1,
+		},
+		{
+			name:   "records new in the engine are appended in its order",
+			base:   moves(move(1, "Tackle")),
+			ours:   moves(move(3, "Ember"), move(1, "Tackle")),
+			theirs: moves(move(5, "Surf"), move(1, "Tackle"), move(4, "Growl")),
+			want:   []string{"3 Ember", "1 Tackle", "5 Surf", "4 Growl"},
+			taken:  2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, taken, conflicts := mergeRecords("moves.toml", "move", test.base, test.ours, test.theirs, test.resolutions)
+			got := []string{}
+			for _, record := range merged {
+				got = append(got, fmt.Sprintf("%v %v", record["id"], record["name"]))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("merged %v, want %v", got, test.want)
+			}
+			if taken != test.taken {
+				t.Errorf("took %d records from the engine, want %d", taken, test.taken)
+			}
+			gotConflicts := []string{}
+			for _, conflict := range conflicts {
+				gotConflicts = append(gotConflicts, conflict.Key+" "+conflict.Reason)
+			}
+			if test.conflicts == nil {
+				test.conflicts = []string{}
+			}
+			if !reflect.DeepEqual(gotConflicts, test.conflicts) {
+				t.Errorf("conflicts %v, want %v", gotConflicts, test.conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeFile(t *testing.T) {
+	const base = "[[move]]\nid = 1\nname = \"Tackle\"\n\n[[move]]\nid = 2\nname = \"Pound\"\n"
+	tests := []struct {
+		name        string
+		file        string
+		base        []byte
+		ours        []byte
+		theirs      []byte
+		resolutions map[string]string
+		want        []byte
+		conflict    string
+	}{
+		{
+			name:   "only the engine changed",
+			file:   "scripts/intro.txt",
+			base:   []byte("a"),
+			ours:   []byte("a"),
+			theirs: []byte("b"),
+			want:   []byte("b"),
+		},
+		{
+			name:     "both changed a file that is not TOML",
+			file:     "scripts/intro.txt",
+			base:     []byte("a"),
+			ours:     []byte("b"),
+			theirs:   []byte("c"),
+			want:     []byte("b"),
+			conflict: "changed in the project and in the engine",
+		},
+		{
+			name:     "deleted in the project, changed in the engine",
+			file:     "scripts/intro.txt",
+			base:     []byte("a"),
+			theirs:   []byte("c"),
+			conflict: "deleted in the project, changed in the engine",
+		},
+		{
+			name:     "added by both",
+			file:     "scripts/intro.txt",
+			ours:     []byte("b"),
+			theirs:   []byte("c"),
+			want:     []byte("b"),
+			conflict: "added by both the project and the engine",
+		},
+		{
+			name:        "resolved with the engine's side",
+			file:        "scripts/intro.txt",
+			base:        []byte("a"),
+			ours:        []byte("b"),
+			theirs:      []byte("c"),
+			resolutions: map[string]string{"scripts/intro.txt": "theirs"},
+			want:        []byte("c"),
+		},
+		{
+			name:     "TOML that cannot be parsed is merged as a whole",
+			file:     "data/toml/moves.toml",
+			base:     []byte(base),
+			ours:     []byte("[[move]\n"),
+			theirs:   []byte(base + "\n[[move]]\nid = 3\nname = \"Ember\"\n"),
+			want:     []byte("[[move]\n"),
+			conflict: "changed in the project and in the engine",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outcome := mergeFile(test.file, test.base, test.ours, test.theirs, test.resolutions)
+			if !sameFile(outcome.data, test.want) {
+				t.Errorf("merged %q, want %q", outcome.data, test.want)
+			}
+			conflict := ""
+			if len(outcome.conflicts) > 0 {
+				conflict = outcome.conflicts[0].Reason
+			}
+			if len(outcome.conflicts) > 1 || conflict != test.conflict {
+				t.Errorf("conflicts %v, want %q", outcome.conflicts, test.conflict)
+			}
+		})
+	}
+
+	t.Run("records changed on different sides are both kept", func(t *testing.T) {
+		ours := strings.Replace(base, "Tackle", "Slam", 1)
+		theirs := strings.Replace(base, "Pound", "Ember", 1)
+		outcome := mergeFile("data/toml/moves.toml", []byte(base), []byte(ours), []byte(theirs), nil)
+		if len(outcome.conflicts) > 0 || outcome.records != 1 {
+			t.Fatalf("got %d records from the engine and conflicts %v, want 1 and none", outcome.records, outcome.conflicts)
+		}
+		var merged struct {
+			Move []struct {
+				ID   int    `toml:"id"`
+				Name string `toml:"name"`
+			} `toml:"move"`
+		}
+		if err := toml.Unmarshal(outcome.data, &merged); err != nil {
+			t.Fatal(err)
+		}
+		if len(merged.Move) != 2 || merged.Move[0].Name != "Slam" || merged.Move[1].Name != "Ember" {
+			t.Errorf("merged %+v, want Slam and Ember", merged.Move)
+		}
+	})
+}
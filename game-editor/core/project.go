@@ -1,247 +1,239 @@
 package core
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/pelletier/go-toml/v2"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/tools-core/dialogs"
 	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
-	"gopkg.in/src-d/go-git.v4"
 )
 
 func (a *App) GrabProjectWorkspace() string {
-	selection, err := runtime.OpenDirectoryDialog(a.Ctx, runtime.OpenDialogOptions{
-		Title: "Select engine base directory",
-	})
+	selection, err := dialogs.Directory(a.Ctx, "Select engine base directory")
 	if err != nil {
 		panic(err)
 	}
-	selectionUpdated := strings.ReplaceAll(selection, "\\", "/")
-	return selectionUpdated
+	return selection
 }
 
-func (a *App) CreateProject(projectCreationData coreModels.ProjectCreation) bool {
-	githubEngineUrl := "https://api.github.com/repos/zenith110/pokemon-go-engine/git/refs/heads/main"
-	req, err := http.Get(githubEngineUrl)
+// GrabProjectTemplate asks for an engine template archive to create a project
+// from, a local engine checkout is picked with GrabProjectWorkspace
+func (a *App) GrabProjectTemplate() string {
+	selection, err := dialogs.File(a.Ctx, "Select engine template", "Engine templates (*.zip, *.tar.gz, *.tgz)", "*.zip;*.tar.gz;*.tgz")
 	if err != nil {
-		fmt.Printf("error is %v", err)
+		fmt.Printf("error is %v while selecting a template!\n", err)
+		return ""
 	}
-	bodyData, _ := io.ReadAll(req.Body)
+	return selection
+}
 
-	var project coreModels.GithubInfo
-	if err := json.Unmarshal(bodyData, &project); err != nil {
-		panic(err)
+func (a *App) CreateProject(projectCreationData coreModels.ProjectCreation) bool {
+	if _, err := a.CreateProjectFromTemplate(projectCreationData); err != nil {
+		fmt.Printf("error is: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// CreateProjectFromTemplate creates a project from projectCreationData.Template,
+// a local engine checkout or a zip/tar.gz archive of one, without touching the
+// network. Without a template it uses $POKEMON_ENGINE_TEMPLATE and otherwise
+// clones the latest engine from GitHub.
+func (a *App) CreateProjectFromTemplate(projectCreationData coreModels.ProjectCreation) (Models.Project, error) {
+	if projectCreationData.Name == "" {
+		return Models.Project{}, errors.New("project name is empty")
 	}
 	updatedDataDirectory := strings.ReplaceAll(projectCreationData.Directory, "\\", "/")
 	fullPath := fmt.Sprintf("%s/%s", updatedDataDirectory, projectCreationData.Name)
-	os.Mkdir(fullPath, 0755)
+	if existing, err := a.Projects.FindByPath(fullPath); err == nil {
+		return Models.Project{}, fmt.Errorf("%w: %s is registered as %s", ErrProjectRegistered, fullPath, existing.Name)
+	}
+	if err := os.Mkdir(fullPath, 0755); err != nil {
+		return Models.Project{}, fmt.Errorf("error creating project folder: %w", err)
+	}
 
-	_, err = git.PlainClone(fullPath, false, &git.CloneOptions{
-		URL:      "https://github.com/zenith110/pokemon-go-engine",
-		Progress: os.Stdout,
-	})
+	template := projectCreationData.Template
+	if template == "" {
+		template = os.Getenv(TemplateEnvironment)
+	}
+	var version string
+	var err error
+	if template != "" {
+		version, err = installTemplate(strings.ReplaceAll(template, "\\", "/"), fullPath)
+	} else {
+		version, err = cloneEngine(fullPath)
+	}
 	if err != nil {
-		fmt.Printf("error is: %v", err)
-		return false
+		os.RemoveAll(fullPath)
+		return Models.Project{}, err
+	}
+	// Projects are git repositories so they can be snapshotted from the editor
+	gitRepository, err := initProjectRepository(fullPath)
+	if err == nil {
+		_, err = commitProject(gitRepository, fmt.Sprintf("Create project from engine %s", version))
 	}
-
-	err = os.RemoveAll(fmt.Sprintf("%s/.git", fullPath))
 	if err != nil {
-		fmt.Printf("error is: %v", err)
-		return false
+		os.RemoveAll(fullPath)
+		return Models.Project{}, err
 	}
 
+	if projectCreationData.ID == "" {
+		projectCreationData.ID = uuid.New().String()
+	}
 	currentTime := time.Now()
 
 	projectData := Models.Project{
 		Name:            projectCreationData.Name,
 		FolderLocation:  fullPath,
-		VersionOfEngine: project.Object.Sha,
+		VersionOfEngine: version,
 		CreatedDateTime: currentTime.UTC().String(),
 		ID:              projectCreationData.ID,
 		LastUsed:        "N/A",
 	}
-	var projects []Models.Project
-	projects = append(projects, projectData)
-	projectDataToml := Models.Projects{
-		Project: projects,
-	}
-	data, err := toml.Marshal(projectDataToml)
-	if err != nil {
-		fmt.Printf("error while creating new project file! %v\n", err)
-	}
-
-	f, err := os.OpenFile("projects.toml", os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		log.Fatalf("Error occured while opening file %v\n", err)
+	if err := a.Projects.Add(projectData); err != nil {
+		return Models.Project{}, fmt.Errorf("error occured while writing data %w", err)
 	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v\n", err)
+	if projectData, err = a.Projects.Touch(projectData.ID); err != nil {
+		return Models.Project{}, err
 	}
 	a.DataDirectory = fullPath
-	return true
+	a.openProject()
+	return projectData, nil
 }
 
+// ParseProjects lists the registered projects
 func (a *App) ParseProjects() []Models.Project {
-	projectsPath := "projects.toml"
-	projects, err := os.Open(projectsPath)
+	projects, err := a.Projects.Projects()
 	if err != nil {
-		os.Create(projectsPath)
-	}
-	defer projects.Close()
-	var projectsData Models.Projects
-	b, err := io.ReadAll(projects)
-	if err != nil {
-		fmt.Printf("error while reading projects is %v", err)
-	}
-	var projectsArray []Models.Project
-
-	err = toml.Unmarshal(b, &projectsData)
-	if err != nil {
-		panic(err)
-	}
-	for projectsIndex := range projectsData.Project {
-		project := Models.Project{
-			Name:            projectsData.Project[projectsIndex].Name,
-			FolderLocation:  projectsData.Project[projectsIndex].FolderLocation,
-			VersionOfEngine: projectsData.Project[projectsIndex].VersionOfEngine,
-			CreatedDateTime: projectsData.Project[projectsIndex].CreatedDateTime,
-			LastUsed:        projectsData.Project[projectsIndex].LastUsed,
-			ID:              projectsData.Project[projectsIndex].ID,
-		}
-		projectsArray = append(projectsArray, project)
+		fmt.Printf("error while reading projects is %v\n", err)
+		return []Models.Project{}
 	}
-	return projectsArray
+	return projects
 }
 
-func (a *App) SelectProject(project coreModels.ProjectSelect) {
-	a.DataDirectory = project.FolderLocation
-	projectsPath := "projects.toml"
-	projects, err := os.Open(projectsPath)
+// SelectProject opens a registered project and makes it the last used one
+func (a *App) SelectProject(project coreModels.ProjectSelect) error {
+	selected, err := a.Projects.Find(project.ID)
 	if err != nil {
-		os.Create(projectsPath)
+		return err
 	}
-	defer projects.Close()
-	var projectsData Models.Projects
-	b, err := io.ReadAll(projects)
-	if err != nil {
-		fmt.Printf("error while reading projects is %v", err)
+	if health := checkProjectFolder(selected.FolderLocation); health != ProjectHealthy {
+		return fmt.Errorf("cannot open %s: %s", selected.Name, projectHealthMessage(health))
 	}
-
-	err = toml.Unmarshal(b, &projectsData)
-	if err != nil {
-		panic(err)
+	if _, err := a.Projects.Touch(project.ID); err != nil {
+		return err
 	}
-	for projectsIndex := range projectsData.Project {
-		if projectsData.Project[projectsIndex].ID == project.ID {
-			currentTime := time.Now()
-			projectsData.Project[projectsIndex].LastUsed = currentTime.UTC().String()
-			lastUsedFileName := "lastused.toml"
-			data, err := toml.Marshal(projectsData.Project[projectsIndex])
-			if err != nil {
-				panic(fmt.Errorf("error had occured while creating last used data!\n%v", err))
-			}
-			f, err := os.OpenFile("lastused.toml", os.O_CREATE, 0644)
-			if err != nil {
-				os.Remove(lastUsedFileName)
-				os.Create(lastUsedFileName)
-			}
-			defer f.Close()
-			if _, err := f.Write(data); err != nil {
-				fmt.Printf("Error occured while writing data %v\n", err)
-			}
+	a.DataDirectory = selected.FolderLocation
+	a.openProject()
+	return nil
+}
+
+// ImportProject registers an existing engine folder. Importing a folder that
+// is already registered selects its project instead of adding it twice.
+func (a *App) ImportProject() error {
+	selection, err := dialogs.Directory(a.Ctx, "Select engine base directory")
+	if err != nil {
+		return fmt.Errorf("error while importing a project: %w", err)
+	}
+	if selection == "" {
+		return nil
+	}
+	selectionUpdated := strings.TrimSuffix(selection, "/")
+	project, err := a.Projects.FindByPath(selectionUpdated)
+	if errors.Is(err, ErrProjectNotFound) {
+		projectNameSplit := strings.Split(selectionUpdated, "/")
+		project = Models.Project{
+			Name:            projectNameSplit[len(projectNameSplit)-1],
+			FolderLocation:  selectionUpdated,
+			CreatedDateTime: time.Now().UTC().String(),
+			ID:              uuid.New().String(),
+			LastUsed:        "N/A",
 		}
+		// Best effort, upgrading later needs to know the engine version
+		if version, err := engineVersion(selectionUpdated, selectionUpdated); err == nil {
+			project.VersionOfEngine = version
+		}
+		err = a.Projects.Add(project)
 	}
-	data, err := toml.Marshal(projectsData)
-	if err != nil {
-		panic(fmt.Errorf("error had occured while creating move data!\n%v", err))
-	}
-	os.Remove(projectsPath)
-	f, err := os.OpenFile(projectsPath, os.O_CREATE, 0644)
 	if err != nil {
-		log.Fatalf("Error occured while opening file %v\n", err)
+		return err
 	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v\n", err)
+	if _, err := a.Projects.Touch(project.ID); err != nil {
+		return err
 	}
+	a.DataDirectory = selectionUpdated
+	a.openProject()
+	return nil
 }
 
-func (a *App) ImportProject() {
-	selection, err := runtime.OpenDirectoryDialog(a.Ctx, runtime.OpenDialogOptions{
-		Title: "Select engine base directory",
-	})
+// GetCurrentProject returns the last used project, the one the editor opens
+// on startup
+func (a *App) GetCurrentProject() Models.Project {
+	project, _, err := a.Projects.LastUsed()
 	if err != nil {
-		fmt.Printf("error is %v while importing a project!\n", err)
+		fmt.Printf("error while reading last used project: %v\n", err)
 	}
+	return project
+}
 
-	id := uuid.New()
-	selectionUpdated := strings.ReplaceAll(selection, "\\", "/")
-	currentTime := time.Now()
-	projectNameSplit := strings.Split(selectionUpdated, "/")
-	projectName := projectNameSplit[len(projectNameSplit)-1]
-	projectData := Models.Project{
-		Name:            projectName,
-		FolderLocation:  selectionUpdated,
-		VersionOfEngine: "",
-		CreatedDateTime: currentTime.UTC().String(),
-		ID:              id.String(),
-		LastUsed:        "N/A",
-	}
-	var projects []Models.Project
-	projects = append(projects, projectData)
-	projectDataToml := Models.Projects{
-		Project: projects,
-	}
-	data, err := toml.Marshal(projectDataToml)
-	if err != nil {
-		fmt.Printf("error while creating new project file! %v\n", err)
-	}
+// RenameProject changes the name a project is listed under
+func (a *App) RenameProject(id string, name string) error {
+	return a.Projects.Rename(id, name)
+}
 
-	f, err := os.OpenFile("projects.toml", os.O_CREATE|os.O_APPEND, 0644)
+// RemoveProject unregisters a project, its files are left alone. Removing
+// the open project closes it.
+func (a *App) RemoveProject(id string) error {
+	project, err := a.Projects.Find(id)
 	if err != nil {
-		log.Fatalf("Error occured while opening file %v\n", err)
+		return err
 	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v\n", err)
+	if err := a.Projects.Remove(id); err != nil {
+		return err
 	}
-	a.DataDirectory = selectionUpdated
+	if a.DataDirectory != "" && samePath(project.FolderLocation, a.DataDirectory) {
+		a.Watcher.Stop()
+		a.DataDirectory = ""
+	}
+	return nil
 }
 
-func (a *App) GetCurrentProject() Models.Project {
-	lastUsedFileName := "lastused.toml"
-	projectLastUpdated, err := os.OpenFile(lastUsedFileName, os.O_CREATE, 0644)
-	if err != nil {
-		os.Create(lastUsedFileName)
-		return Models.Project{}
+// RelocateProject points a project at the folder it was moved to, asking for
+// the folder when none is given
+func (a *App) RelocateProject(id string, folder string) error {
+	if folder == "" {
+		folder = a.GrabProjectWorkspace()
+		if folder == "" {
+			return nil
+		}
 	}
-	defer projectLastUpdated.Close()
-
-	var projectsData Models.Project
-	b, err := io.ReadAll(projectLastUpdated)
+	project, err := a.Projects.Find(id)
 	if err != nil {
-		fmt.Printf("error while reading last used project: %v", err)
-		return Models.Project{}
+		return err
 	}
-
-	err = toml.Unmarshal(b, &projectsData)
-	if err != nil {
-		fmt.Printf("error while unmarshaling last used project: %v", err)
-		return Models.Project{}
+	if err := a.Projects.Relocate(id, folder); err != nil {
+		return err
+	}
+	if a.DataDirectory != "" && samePath(project.FolderLocation, a.DataDirectory) {
+		relocated, err := a.Projects.Find(id)
+		if err != nil {
+			return err
+		}
+		a.DataDirectory = relocated.FolderLocation
+		a.openProject()
 	}
+	return nil
+}
 
-	return projectsData
+// CheckProjects reports which registered projects are missing their folder
+// or no longer look like engine projects
+func (a *App) CheckProjects() ([]ProjectHealth, error) {
+	return a.Projects.Check()
 }
 
 // IsProjectSelected checks if a project is currently selected
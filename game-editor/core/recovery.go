@@ -0,0 +1,34 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// PendingRecoveries lists the saves that were interrupted before they could
+// replace their file, both for the editor's project list and for the current
+// project. The frontend asks the user what to do with each on startup.
+func (a *App) PendingRecoveries() ([]repository.PendingWrite, error) {
+	pending, err := repository.PendingWritesOf(a.Projects.Path())
+	if err != nil {
+		return nil, err
+	}
+	if a.DataDirectory == "" {
+		return pending, nil
+	}
+	projectPending, err := repository.PendingWrites(fmt.Sprintf("%s/data", a.DataDirectory))
+	if err != nil {
+		return nil, err
+	}
+	return append(pending, projectPending...), nil
+}
+
+// ResolveRecovery either finishes an interrupted save by restoring its temp
+// file over the original, or throws the temp file away
+func (a *App) ResolveRecovery(tempPath string, restore bool) error {
+	if restore {
+		return repository.RestorePendingWrite(tempPath)
+	}
+	return repository.DiscardPendingWrite(tempPath)
+}
@@ -0,0 +1,364 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// RegistryEnvironment overrides where the project registry is kept, e.g. for
+// scripts that should not touch the user's own project list
+const RegistryEnvironment = "POKEMON_ENGINE_REGISTRY"
+
+// Kinds of registry failures, match them with errors.Is
+var (
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrProjectRegistered = errors.New("project folder is already registered")
+)
+
+// Health states reported by CheckProjects
+const (
+	ProjectHealthy       = "ok"
+	ProjectFolderMissing = "missing"
+	ProjectNotEngine     = "not-engine-project"
+)
+
+// ProjectHealth tells whether a registered project can still be opened
+type ProjectHealth struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// registryDocument is the registry file. LastUsed is the ID of the project
+// the editor opens on startup.
+type registryDocument struct {
+	LastUsed string           `toml:"lastUsed"`
+	Project  []Models.Project `toml:"project"`
+}
+
+// ProjectRegistry is the list of known projects, kept in the user config
+// directory so it does not depend on where the editor is started from. Each
+// project folder is registered once.
+type ProjectRegistry struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewProjectRegistry opens the registry at $POKEMON_ENGINE_REGISTRY or in the
+// user config directory
+func NewProjectRegistry() *ProjectRegistry {
+	path := os.Getenv(RegistryEnvironment)
+	if path == "" {
+		configDirectory, err := os.UserConfigDir()
+		if err != nil {
+			fmt.Printf("error finding the config directory, keeping projects in the working directory: %v\n", err)
+			configDirectory = "."
+		}
+		path = filepath.Join(configDirectory, "pokemon-engine-tools", "projects.toml")
+	}
+	return &ProjectRegistry{path: filepath.ToSlash(path)}
+}
+
+// Path returns where the registry file is
+func (r *ProjectRegistry) Path() string {
+	return r.path
+}
+
+// load reads the registry. The first time it adopts the projects.toml and
+// lastused.toml older versions kept in the working directory.
+func (r *ProjectRegistry) load() (registryDocument, error) {
+	var document registryDocument
+	b, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r.migrate()
+	}
+	if err != nil {
+		return document, err
+	}
+	if err := toml.Unmarshal(b, &document); err != nil {
+		return document, fmt.Errorf("project registry %s is malformed: %w", r.path, err)
+	}
+	return document, nil
+}
+
+func (r *ProjectRegistry) migrate() (registryDocument, error) {
+	var document registryDocument
+	var legacy Models.Projects
+	if b, err := os.ReadFile("projects.toml"); err == nil && cleanPath(r.path) != cleanPath("projects.toml") {
+		if err := toml.Unmarshal(b, &legacy); err != nil {
+			fmt.Printf("error reading the old projects.toml, starting with no projects: %v\n", err)
+		}
+	}
+	for _, project := range legacy.Project {
+		if project.FolderLocation == "" || document.find(project.FolderLocation) != -1 {
+			continue
+		}
+		document.Project = append(document.Project, project)
+	}
+	var lastUsed Models.Project
+	if b, err := os.ReadFile("lastused.toml"); err == nil && toml.Unmarshal(b, &lastUsed) == nil {
+		if index := document.find(lastUsed.FolderLocation); index != -1 {
+			document.LastUsed = document.Project[index].ID
+		}
+	}
+	if len(document.Project) == 0 {
+		return document, nil
+	}
+	return document, r.save(document)
+}
+
+func (r *ProjectRegistry) save(document registryDocument) error {
+	data, err := toml.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return repository.WriteFile(r.path, data, 0644)
+}
+
+// update loads the registry, lets change edit it and saves the result unless
+// change returns an error
+func (r *ProjectRegistry) update(change func(document *registryDocument) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	document, err := r.load()
+	if err != nil {
+		return err
+	}
+	if err := change(&document); err != nil {
+		return err
+	}
+	return r.save(document)
+}
+
+func (r *ProjectRegistry) read() (registryDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+// find returns the index of the project in folder, or -1
+func (d *registryDocument) find(folder string) int {
+	for index, project := range d.Project {
+		if samePath(project.FolderLocation, folder) {
+			return index
+		}
+	}
+	return -1
+}
+
+// byID returns the index of the project with the given ID
+func (d *registryDocument) byID(id string) (int, error) {
+	for index, project := range d.Project {
+		if project.ID == id {
+			return index, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %s", ErrProjectNotFound, id)
+}
+
+// samePath compares folders the way the file system does
+func samePath(a string, b string) bool {
+	a, b = cleanPath(a), cleanPath(b)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// Projects returns every registered project
+func (r *ProjectRegistry) Projects() ([]Models.Project, error) {
+	document, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	return document.Project, nil
+}
+
+// Find returns the project with the given ID
+func (r *ProjectRegistry) Find(id string) (Models.Project, error) {
+	document, err := r.read()
+	if err != nil {
+		return Models.Project{}, err
+	}
+	index, err := document.byID(id)
+	if err != nil {
+		return Models.Project{}, err
+	}
+	return document.Project[index], nil
+}
+
+// FindByPath returns the project registered for folder
+func (r *ProjectRegistry) FindByPath(folder string) (Models.Project, error) {
+	document, err := r.read()
+	if err != nil {
+		return Models.Project{}, err
+	}
+	index := document.find(folder)
+	if index == -1 {
+		return Models.Project{}, fmt.Errorf("%w: no project is registered for %s", ErrProjectNotFound, folder)
+	}
+	return document.Project[index], nil
+}
+
+// Add registers project, refusing a folder or ID that is already registered
+func (r *ProjectRegistry) Add(project Models.Project) error {
+	return r.update(func(document *registryDocument) error {
+		if index := document.find(project.FolderLocation); index != -1 {
+			return fmt.Errorf("%w: %s is registered as %s", ErrProjectRegistered, project.FolderLocation, document.Project[index].Name)
+		}
+		if _, err := document.byID(project.ID); err == nil {
+			return fmt.Errorf("project ID %s is already taken", project.ID)
+		}
+		document.Project = append(document.Project, project)
+		return nil
+	})
+}
+
+// Rename changes the display name of a project, its folder stays where it is
+func (r *ProjectRegistry) Rename(id string, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("project name is empty")
+	}
+	return r.update(func(document *registryDocument) error {
+		index, err := document.byID(id)
+		if err != nil {
+			return err
+		}
+		document.Project[index].Name = name
+		return nil
+	})
+}
+
+// Remove unregisters a project without touching its files
+func (r *ProjectRegistry) Remove(id string) error {
+	return r.update(func(document *registryDocument) error {
+		index, err := document.byID(id)
+		if err != nil {
+			return err
+		}
+		document.Project = append(document.Project[:index], document.Project[index+1:]...)
+		if document.LastUsed == id {
+			document.LastUsed = ""
+		}
+		return nil
+	})
+}
+
+// Relocate points a project at folder after it was moved on disk
+func (r *ProjectRegistry) Relocate(id string, folder string) error {
+	folder = strings.TrimSuffix(strings.ReplaceAll(folder, "\\", "/"), "/")
+	if health := checkProjectFolder(folder); health != ProjectHealthy {
+		return fmt.Errorf("cannot relocate to %s: %s", folder, projectHealthMessage(health))
+	}
+	return r.update(func(document *registryDocument) error {
+		index, err := document.byID(id)
+		if err != nil {
+			return err
+		}
+		if other := document.find(folder); other != -1 && other != index {
+			return fmt.Errorf("%w: %s is registered as %s", ErrProjectRegistered, folder, document.Project[other].Name)
+		}
+		document.Project[index].FolderLocation = folder
+		return nil
+	})
+}
+
+// Touch marks a project as the last used one
+func (r *ProjectRegistry) Touch(id string) (Models.Project, error) {
+	var project Models.Project
+	err := r.update(func(document *registryDocument) error {
+		index, err := document.byID(id)
+		if err != nil {
+			return err
+		}
+		document.Project[index].LastUsed = time.Now().UTC().String()
+		document.LastUsed = id
+		project = document.Project[index]
+		return nil
+	})
+	return project, err
+}
+
+// LastUsed returns the project the editor had open last, false when there is
+// none
+func (r *ProjectRegistry) LastUsed() (Models.Project, bool, error) {
+	document, err := r.read()
+	if err != nil || document.LastUsed == "" {
+		return Models.Project{}, false, err
+	}
+	index, err := document.byID(document.LastUsed)
+	if err != nil {
+		return Models.Project{}, false, nil
+	}
+	return document.Project[index], true, nil
+}
+
+// SetEngineVersion records the engine version a project is on
+func (r *ProjectRegistry) SetEngineVersion(id string, version string) error {
+	return r.update(func(document *registryDocument) error {
+		index, err := document.byID(id)
+		if err != nil {
+			return err
+		}
+		document.Project[index].VersionOfEngine = version
+		return nil
+	})
+}
+
+// Check reports for every project whether its folder still exists and still
+// looks like an engine project
+func (r *ProjectRegistry) Check() ([]ProjectHealth, error) {
+	projects, err := r.Projects()
+	if err != nil {
+		return nil, err
+	}
+	health := make([]ProjectHealth, 0, len(projects))
+	for _, project := range projects {
+		status := checkProjectFolder(project.FolderLocation)
+		health = append(health, ProjectHealth{
+			ID:      project.ID,
+			Name:    project.Name,
+			Status:  status,
+			Message: projectHealthMessage(status),
+		})
+	}
+	return health, nil
+}
+
+// checkProjectFolder tells whether folder exists and has the engine's data
+// folder or manifest
+func checkProjectFolder(folder string) string {
+	if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+		return ProjectFolderMissing
+	}
+	if info, err := os.Stat(fmt.Sprintf("%s/data/toml", folder)); err == nil && info.IsDir() {
+		return ProjectHealthy
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s/%s", folder, engineManifestFile)); err == nil {
+		return ProjectHealthy
+	}
+	return ProjectNotEngine
+}
+
+func projectHealthMessage(status string) string {
+	switch status {
+	case ProjectFolderMissing:
+		return "the project folder does not exist, it may have been moved or deleted"
+	case ProjectNotEngine:
+		return fmt.Sprintf("the folder has neither data/toml nor %s", engineManifestFile)
+	}
+	return "ok"
+}
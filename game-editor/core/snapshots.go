@@ -0,0 +1,644 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// projectIgnore keeps the journal and the atomic save's temp files and
+// backups out of snapshots
+var projectIgnore = []string{".editor/", "*.bak", "*.tmp"}
+
+// ErrNotVersioned is returned when a project is not a git repository yet, it
+// becomes one on its first snapshot
+var ErrNotVersioned = errors.New("the project has no snapshots yet")
+
+// snapshotAuthor signs the commits the editor makes
+var snapshotAuthor = object.Signature{Name: "Pokemon Engine Tools", Email: "editor@pokemon-engine-tools"}
+
+// Snapshot is one commit of the project's git repository
+type Snapshot struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+}
+
+// FieldChange is one field of a record that differs between snapshots, the
+// field is a dotted path such as stats.attack
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SnapshotChange is a file, or a record of a data file, that differs between
+// two snapshots. Kind is added, removed or changed. Summary reads like
+// "Bulbasaur stats.attack 49 -> 55".
+type SnapshotChange struct {
+	File    string        `json:"file"`
+	Record  string        `json:"record"`
+	Name    string        `json:"name"`
+	Kind    string        `json:"kind"`
+	Fields  []FieldChange `json:"fields"`
+	Summary string        `json:"summary"`
+}
+
+// initProjectRepository makes directory a git repository, if it is not one
+// yet, and returns it. Its .gitignore gets the projectIgnore patterns it is
+// missing, an engine template may ship one without them.
+func initProjectRepository(directory string) (*git.Repository, error) {
+	gitRepository, err := git.PlainOpen(directory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		if gitRepository, err = git.PlainInit(directory, false); err != nil {
+			return nil, fmt.Errorf("error creating the project repository: %w", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProjectIgnore(directory); err != nil {
+		return nil, err
+	}
+	return gitRepository, nil
+}
+
+// ensureProjectIgnore appends the projectIgnore patterns missing from the
+// project's .gitignore, creating it when there is none
+func ensureProjectIgnore(directory string) error {
+	ignorePath := fmt.Sprintf("%s/.gitignore", directory)
+	data, err := os.ReadFile(ignorePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, pattern := range projectIgnore {
+		if !present[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, strings.Join(missing, "\n")+"\n"...)
+	return os.WriteFile(ignorePath, data, 0644)
+}
+
+// ignoredByEditor reports whether path, relative to the project, is one of
+// the files projectIgnore keeps out of snapshots
+func ignoredByEditor(path string) bool {
+	return strings.HasPrefix(path, ".editor/") || strings.HasSuffix(path, ".bak") || strings.HasSuffix(path, ".tmp")
+}
+
+// commitProject stages every change of the project and commits it
+func commitProject(gitRepository *git.Repository, message string) (Snapshot, error) {
+	worktree, err := gitRepository.Worktree()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if status.IsClean() {
+		return Snapshot{}, errors.New("nothing changed since the last snapshot")
+	}
+	paths := make([]string, 0, len(status))
+	for path := range status {
+		// The journal and atomic save files stay out even when a .gitignore
+		// written before the editor's did not exclude them
+		if !ignoredByEditor(filepath.ToSlash(path)) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return Snapshot{}, errors.New("nothing changed since the last snapshot")
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		// Add stages deletions as well
+		if _, err := worktree.Add(path); err != nil {
+			return Snapshot{}, fmt.Errorf("error staging %s: %w", path, err)
+		}
+	}
+	author := snapshotAuthor
+	author.When = time.Now()
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: &author})
+	if err != nil {
+		return Snapshot{}, err
+	}
+	commit, err := gitRepository.CommitObject(hash)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return newSnapshot(commit), nil
+}
+
+func newSnapshot(commit *object.Commit) Snapshot {
+	return Snapshot{
+		Hash:    commit.Hash.String(),
+		Message: strings.TrimSpace(commit.Message),
+		Author:  commit.Author.Name,
+		Time:    commit.Author.When,
+	}
+}
+
+// projectRepository opens the project's git repository for reading, it never
+// creates one
+func (a *App) projectRepository() (*git.Repository, error) {
+	if a.DataDirectory == "" {
+		return nil, errors.New("no project selected")
+	}
+	gitRepository, err := git.PlainOpen(a.DataDirectory)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, ErrNotVersioned
+	}
+	return gitRepository, err
+}
+
+// CommitSnapshot commits the current state of the project with message.
+// Projects that are not a git repository yet, like imported ones, become one.
+func (a *App) CommitSnapshot(message string) (Snapshot, error) {
+	if strings.TrimSpace(message) == "" {
+		return Snapshot{}, errors.New("snapshot message is empty")
+	}
+	if a.DataDirectory == "" {
+		return Snapshot{}, errors.New("no project selected")
+	}
+	gitRepository, err := initProjectRepository(a.DataDirectory)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return commitProject(gitRepository, message)
+}
+
+// Snapshots lists every snapshot of the project, newest first
+func (a *App) Snapshots() ([]Snapshot, error) {
+	return a.snapshotsWhere(func(*object.Commit, *object.Commit) (bool, error) {
+		return true, nil
+	})
+}
+
+// FileHistory lists the snapshots that changed file, a path relative to the
+// project such as data/toml/moves.toml, newest first
+func (a *App) FileHistory(file string) ([]Snapshot, error) {
+	return a.snapshotsWhere(func(commit *object.Commit, parent *object.Commit) (bool, error) {
+		data, err := snapshotFile(commit, file)
+		if err != nil {
+			return false, err
+		}
+		previous, err := snapshotFile(parent, file)
+		if err != nil {
+			return false, err
+		}
+		return !sameFile(data, previous), nil
+	})
+}
+
+// EntityHistory lists the snapshots that changed one record, e.g. the
+// pokemon with ID 1, newest first. entityType is one of the index's types.
+func (a *App) EntityHistory(entityType string, id string) ([]Snapshot, error) {
+	source, err := indexSourceOf(entityType)
+	if err != nil {
+		return nil, err
+	}
+	return a.snapshotsWhere(func(commit *object.Commit, parent *object.Commit) (bool, error) {
+		record, err := a.snapshotRecord(commit, source, id)
+		if err != nil {
+			return false, err
+		}
+		previous, err := a.snapshotRecord(parent, source, id)
+		if err != nil {
+			return false, err
+		}
+		return !reflect.DeepEqual(record, previous), nil
+	})
+}
+
+// snapshotsWhere walks the history from HEAD, keeping the commits changed
+// reports true for given their first parent, nil for the root commit
+func (a *App) snapshotsWhere(changed func(commit *object.Commit, parent *object.Commit) (bool, error)) ([]Snapshot, error) {
+	gitRepository, err := a.projectRepository()
+	if errors.Is(err, ErrNotVersioned) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	head, err := gitRepository.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	commits, err := gitRepository.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []Snapshot{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		var parent *object.Commit
+		if commit.NumParents() > 0 {
+			var err error
+			if parent, err = commit.Parent(0); err != nil {
+				return err
+			}
+		}
+		keep, err := changed(commit, parent)
+		if err != nil {
+			return err
+		}
+		if keep {
+			snapshots = append(snapshots, newSnapshot(commit))
+		}
+		return nil
+	})
+	return snapshots, err
+}
+
+// snapshotFile returns the contents of file in commit, nil when it did not
+// exist then
+func snapshotFile(commit *object.Commit, file string) ([]byte, error) {
+	if commit == nil {
+		return nil, nil
+	}
+	entry, err := commit.File(file)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	reader, err := entry.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// snapshotRecord returns the typed record of source with the given ID in
+// commit, nil when it did not exist or its data file did not parse
+func (a *App) snapshotRecord(commit *object.Commit, source indexSource, id string) (any, error) {
+	data, err := snapshotFile(commit, source.location())
+	if err != nil || data == nil {
+		return nil, err
+	}
+	entries, err := source.decode(a.DataDirectory, data)
+	if err != nil {
+		return nil, nil
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry.Record, nil
+		}
+	}
+	return nil, nil
+}
+
+// isDataFile tells whether path, relative to the project, is a TOML data
+// file whose records can be diffed
+func isDataFile(path string) bool {
+	if strings.HasPrefix(path, "data/toml/") && strings.HasSuffix(path, ".toml") {
+		return true
+	}
+	for _, source := range indexSources {
+		if source.location() == path {
+			return true
+		}
+	}
+	return false
+}
+
+func indexSourceOf(entityType string) (indexSource, error) {
+	for _, source := range indexSources {
+		if source.entityType == entityType {
+			return source, nil
+		}
+	}
+	return indexSource{}, fmt.Errorf("unknown record type %q", entityType)
+}
+
+// resolveSnapshot finds the commit a hash, branch, tag or HEAD~n names
+func resolveSnapshot(gitRepository *git.Repository, snapshot string) (*object.Commit, error) {
+	hash, err := gitRepository.ResolveRevision(plumbing.Revision(snapshot))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s not found: %w", snapshot, err)
+	}
+	return gitRepository.CommitObject(*hash)
+}
+
+// DiffSnapshots compares two snapshots, or a snapshot with the project as it
+// is now when to is empty. Data files are compared record by record and
+// field by field, other files only report that they changed.
+func (a *App) DiffSnapshots(from string, to string) ([]SnapshotChange, error) {
+	gitRepository, err := a.projectRepository()
+	if err != nil {
+		return nil, err
+	}
+	fromCommit, err := resolveSnapshot(gitRepository, from)
+	if err != nil {
+		return nil, err
+	}
+	fromTree, err := commitFiles(fromCommit)
+	if err != nil {
+		return nil, err
+	}
+	var toTree engineTree
+	if to == "" {
+		toTree, err = directoryTree(a.DataDirectory)
+		for path := range toTree {
+			if strings.HasSuffix(path, ".bak") || strings.HasSuffix(path, ".tmp") {
+				delete(toTree, path)
+			}
+		}
+	} else {
+		var toCommit *object.Commit
+		if toCommit, err = resolveSnapshot(gitRepository, to); err == nil {
+			toTree, err = commitFiles(toCommit)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for path := range fromTree {
+		paths = append(paths, path)
+	}
+	for path := range toTree {
+		if _, ok := fromTree[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := []SnapshotChange{}
+	for _, path := range paths {
+		before, after := fromTree[path], toTree[path]
+		if sameFile(before, after) {
+			continue
+		}
+		if isDataFile(path) {
+			if recordChanges, ok := diffRecords(path, before, after); ok {
+				changes = append(changes, recordChanges...)
+				continue
+			}
+		}
+		change := SnapshotChange{File: path, Kind: "changed"}
+		switch {
+		case before == nil:
+			change.Kind = "added"
+		case after == nil:
+			change.Kind = "removed"
+		}
+		change.Summary = fmt.Sprintf("%s %s", path, change.Kind)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// commitFiles reads every file of commit
+func commitFiles(commit *object.Commit) (engineTree, error) {
+	files, err := commit.Files()
+	if err != nil {
+		return nil, err
+	}
+	tree := make(engineTree)
+	err = files.ForEach(func(file *object.File) error {
+		reader, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		tree[file.Name] = data
+		return nil
+	})
+	return tree, err
+}
+
+// diffRecords compares two versions of a data file record by record. It
+// reports false when a version does not parse.
+func diffRecords(file string, before []byte, after []byte) ([]SnapshotChange, bool) {
+	beforeRecords, beforeOrder, ok := snapshotRecords(before)
+	if !ok {
+		return nil, false
+	}
+	afterRecords, afterOrder, ok := snapshotRecords(after)
+	if !ok {
+		return nil, false
+	}
+	keys := afterOrder
+	for _, key := range beforeOrder {
+		if _, ok := afterRecords[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	var changes []SnapshotChange
+	for _, key := range keys {
+		beforeRecord, inBefore := beforeRecords[key]
+		afterRecord, inAfter := afterRecords[key]
+		if reflect.DeepEqual(beforeRecord, afterRecord) {
+			continue
+		}
+		record := afterRecord
+		if !inAfter {
+			record = beforeRecord
+		}
+		change := SnapshotChange{File: file, Record: key, Name: recordName(record, key), Kind: "changed"}
+		switch {
+		case !inBefore:
+			change.Kind = "added"
+			change.Summary = fmt.Sprintf("%s added", change.Name)
+		case !inAfter:
+			change.Kind = "removed"
+			change.Summary = fmt.Sprintf("%s removed", change.Name)
+		default:
+			beforeFields, afterFields := make(map[string]string), make(map[string]string)
+			flattenFields("", beforeRecord, beforeFields)
+			flattenFields("", afterRecord, afterFields)
+			var summaries []string
+			for _, field := range unionFields(beforeFields, afterFields) {
+				if beforeFields[field] == afterFields[field] {
+					continue
+				}
+				change.Fields = append(change.Fields, FieldChange{Field: field, Before: beforeFields[field], After: afterFields[field]})
+				summaries = append(summaries, fmt.Sprintf("%s %s -> %s", field, beforeFields[field], afterFields[field]))
+			}
+			change.Summary = fmt.Sprintf("%s %s", change.Name, strings.Join(summaries, ", "))
+		}
+		changes = append(changes, change)
+	}
+	return changes, true
+}
+
+// snapshotRecords reads the records of every top level array of a data file
+// keyed like repository.ChangedRecords, prefixed by the array's name
+func snapshotRecords(data []byte) (map[string]map[string]any, []string, bool) {
+	records := make(map[string]map[string]any)
+	var order []string
+	if data == nil {
+		return records, order, true
+	}
+	var document map[string]any
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, nil, false
+	}
+	for _, table := range unionKeys(document) {
+		list, ok := document[table].([]any)
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			record, ok := item.(map[string]any)
+			if !ok || recordKey(record) == "" {
+				continue
+			}
+			key := fmt.Sprintf("%s/%s", table, recordKey(record))
+			if _, taken := records[key]; !taken {
+				order = append(order, key)
+			}
+			records[key] = record
+		}
+	}
+	return records, order, true
+}
+
+// recordName is what a record is called in summaries
+func recordName(record map[string]any, key string) string {
+	for _, field := range []string{"name", "species"} {
+		if name, ok := record[field].(string); ok && name != "" {
+			return name
+		}
+	}
+	return key
+}
+
+// flattenFields turns a record into dotted field paths and their values.
+// Arrays are indexed, stats.attack or moves[2].level.
+func flattenFields(prefix string, value any, fields map[string]string) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenFields(path, field, fields)
+		}
+	case []any:
+		scalars := true
+		for _, item := range value {
+			switch item.(type) {
+			case map[string]any, []any:
+				scalars = false
+			}
+		}
+		// Lists of plain values such as types read better whole
+		if scalars {
+			fields[prefix] = fmt.Sprint(value)
+			return
+		}
+		for index, item := range value {
+			flattenFields(fmt.Sprintf("%s[%d]", prefix, index), item, fields)
+		}
+	default:
+		fields[prefix] = fmt.Sprint(value)
+	}
+}
+
+func unionFields(before map[string]string, after map[string]string) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, values := range []map[string]string{before, after} {
+		for field := range values {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// RestoreFile puts file back the way it is in snapshot, deleting it when it
+// did not exist then. The restore is one undoable journal entry.
+func (a *App) RestoreFile(snapshot string, file string) error {
+	file = filepath.ToSlash(file)
+	if file == "" || strings.HasPrefix(file, "/") || filepath.IsAbs(file) || repository.UnsafePath(file) {
+		return fmt.Errorf("%s is not a file inside the project", file)
+	}
+	gitRepository, err := a.projectRepository()
+	if err != nil {
+		return err
+	}
+	commit, err := resolveSnapshot(gitRepository, snapshot)
+	if err != nil {
+		return err
+	}
+	data, err := snapshotFile(commit, file)
+	if err != nil {
+		return err
+	}
+	defer a.Journal.Begin(fmt.Sprintf("Restore %s from %s", file, commit.Hash.String()[:7]))()
+	path := fmt.Sprintf("%s/%s", a.DataDirectory, file)
+	if data == nil {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s exists neither in the snapshot nor in the project", file)
+		}
+		return repository.RemoveFile(path)
+	}
+	return repository.WriteFile(path, data, 0644)
+}
+
+// RestoreRecord puts one record, e.g. the pokemon with ID 1, back the way it
+// is in snapshot and leaves the rest of its data file alone. A record that
+// did not exist then is deleted.
+func (a *App) RestoreRecord(snapshot string, entityType string, id string) error {
+	source, err := indexSourceOf(entityType)
+	if err != nil {
+		return err
+	}
+	gitRepository, err := a.projectRepository()
+	if err != nil {
+		return err
+	}
+	commit, err := resolveSnapshot(gitRepository, snapshot)
+	if err != nil {
+		return err
+	}
+	data, err := snapshotFile(commit, source.location())
+	if err != nil {
+		return err
+	}
+	defer a.Journal.Begin(fmt.Sprintf("Restore %s %s from %s", entityType, id, commit.Hash.String()[:7]))()
+	return source.repository(a.DataDirectory).Restore(data, id)
+}
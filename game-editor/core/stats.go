@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// Natures maps each nature to the stat it raises and the stat it lowers, the
+// neutral natures raise and lower nothing
+var Natures = map[string][2]string{
+	"hardy":   {"", ""},
+	"lonely":  {"attack", "defense"},
+	"brave":   {"attack", "speed"},
+	"adamant": {"attack", "special-attack"},
+	"naughty": {"attack", "special-defense"},
+	"bold":    {"defense", "attack"},
+	"docile":  {"", ""},
+	"relaxed": {"defense", "speed"},
+	"impish":  {"defense", "special-attack"},
+	"lax":     {"defense", "special-defense"},
+	"timid":   {"speed", "attack"},
+	"hasty":   {"speed", "defense"},
+	"serious": {"", ""},
+	"jolly":   {"speed", "special-attack"},
+	"naive":   {"speed", "special-defense"},
+	"modest":  {"special-attack", "attack"},
+	"mild":    {"special-attack", "defense"},
+	"quiet":   {"special-attack", "speed"},
+	"bashful": {"", ""},
+	"rash":    {"special-attack", "special-defense"},
+	"calm":    {"special-defense", "attack"},
+	"gentle":  {"special-defense", "defense"},
+	"sassy":   {"special-defense", "speed"},
+	"careful": {"special-defense", "special-attack"},
+	"quirky":  {"", ""},
+}
+
+// Level, IV and EV limits of the mainline games
+const (
+	MinLevel    = 1
+	MaxLevel    = 100
+	MaxIV       = 31
+	MaxEV       = 255
+	MaxTotalEVs = 510
+)
+
+// PerfectIVs is the spread used when a trainer pokemon does not set its IVs
+var PerfectIVs = coreModels.StatSpread{HP: MaxIV, Attack: MaxIV, Defense: MaxIV, Speed: MaxIV, SpecialAttack: MaxIV, SpecialDefense: MaxIV}
+
+// CalculateStats derives a pokemon's stats from its species' base stats,
+// level, IVs, EVs and nature with the mainline formulas. An empty or unknown
+// nature is neutral.
+func CalculateStats(base Models.Stats, level int, ivs coreModels.StatSpread, evs coreModels.StatSpread, nature string) coreModels.StatSpread {
+	modifier := Natures[strings.ToLower(nature)]
+	stat := func(name string, base int, iv int, ev int) int {
+		value := (2*base+iv+ev/4)*level/100 + 5
+		switch name {
+		case modifier[0]:
+			return value * 110 / 100
+		case modifier[1]:
+			return value * 90 / 100
+		}
+		return value
+	}
+	return coreModels.StatSpread{
+		HP:             (2*base.Hp+ivs.HP+evs.HP/4)*level/100 + level + 10,
+		Attack:         stat("attack", base.Attack, ivs.Attack, evs.Attack),
+		Defense:        stat("defense", base.Defense, ivs.Defense, evs.Defense),
+		Speed:          stat("speed", base.Speed, ivs.Speed, evs.Speed),
+		SpecialAttack:  stat("special-attack", base.SpecialAttack, ivs.SpecialAttack, evs.SpecialAttack),
+		SpecialDefense: stat("special-defense", base.SpecialDefense, ivs.SpecialDefense, evs.SpecialDefense),
+	}
+}
+
+// CheckSpread returns what is out of range in a party pokemon's level, IVs,
+// EVs and nature. Nil IVs or EVs and an empty nature are not checked.
+func CheckSpread(level int, ivs *coreModels.StatSpread, evs *coreModels.StatSpread, nature string) error {
+	if err := CheckLevel(level); err != nil {
+		return err
+	}
+	if nature != "" {
+		if _, ok := Natures[strings.ToLower(nature)]; !ok {
+			return fmt.Errorf("nature %q does not exist", nature)
+		}
+	}
+	if ivs != nil {
+		if err := CheckIVs(*ivs); err != nil {
+			return err
+		}
+	}
+	if evs != nil {
+		return CheckEVs(*evs)
+	}
+	return nil
+}
+
+// CheckLevel returns an error when level is not between MinLevel and MaxLevel
+func CheckLevel(level int) error {
+	if level < MinLevel || level > MaxLevel {
+		return fmt.Errorf("level %d is not between %d and %d", level, MinLevel, MaxLevel)
+	}
+	return nil
+}
+
+// CheckIVs returns an error for the first IV that is not between 0 and MaxIV
+func CheckIVs(ivs coreModels.StatSpread) error {
+	for _, stat := range spreadStats(ivs) {
+		if stat.value < 0 || stat.value > MaxIV {
+			return fmt.Errorf("%s IV %d is not between 0 and %d", stat.name, stat.value, MaxIV)
+		}
+	}
+	return nil
+}
+
+// CheckEVs returns an error for the first EV that is not between 0 and MaxEV
+// or when they add up to more than MaxTotalEVs
+func CheckEVs(evs coreModels.StatSpread) error {
+	total := 0
+	for _, stat := range spreadStats(evs) {
+		if stat.value < 0 || stat.value > MaxEV {
+			return fmt.Errorf("%s EV %d is not between 0 and %d", stat.name, stat.value, MaxEV)
+		}
+		total += stat.value
+	}
+	if total > MaxTotalEVs {
+		return fmt.Errorf("%d EVs in total, at most %d are allowed", total, MaxTotalEVs)
+	}
+	return nil
+}
+
+type spreadStat struct {
+	name  string
+	value int
+}
+
+func spreadStats(spread coreModels.StatSpread) []spreadStat {
+	return []spreadStat{
+		{"hp", spread.HP},
+		{"attack", spread.Attack},
+		{"defense", spread.Defense},
+		{"speed", spread.Speed},
+		{"special attack", spread.SpecialAttack},
+		{"special defense", spread.SpecialDefense},
+	}
+}
+
+// ApplyStats sets the stats of a trainer pokemon that stores its IVs from the
+// species' base stats. Pokemon with hand typed stats are left alone, it
+// reports whether the stats changed.
+func ApplyStats(pokemon *coreModels.TrainerPokemon, base Models.Stats) bool {
+	if pokemon.IVs == nil {
+		return false
+	}
+	var evs coreModels.StatSpread
+	if pokemon.EVs != nil {
+		evs = *pokemon.EVs
+	}
+	stats := CalculateStats(base, pokemon.Level, *pokemon.IVs, evs, pokemon.Nature)
+	current := coreModels.StatSpread{
+		HP:             pokemon.HP,
+		Attack:         pokemon.Attack,
+		Defense:        pokemon.Defense,
+		Speed:          pokemon.Speed,
+		SpecialAttack:  pokemon.SpecialAttack,
+		SpecialDefense: pokemon.SpecialDefense,
+	}
+	if stats == current {
+		return false
+	}
+	pokemon.HP = stats.HP
+	pokemon.Attack = stats.Attack
+	pokemon.Defense = stats.Defense
+	pokemon.Speed = stats.Speed
+	pokemon.SpecialAttack = stats.SpecialAttack
+	pokemon.SpecialDefense = stats.SpecialDefense
+	return true
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"testing"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+func TestCalculateStats(t *testing.T) {
+	garchomp := Models.Stats{Hp: 108, Attack: 130, Defense: 95, SpecialAttack: 80, SpecialDefense: 85, Speed: 102}
+	even := Models.Stats{Hp: 100, Attack: 100, Defense: 100, SpecialAttack: 100, SpecialDefense: 100, Speed: 100}
+	tests := []struct {
+		name   string
+		base   Models.Stats
+		level  int
+		ivs    coreModels.StatSpread
+		evs    coreModels.StatSpread
+		nature string
+		want   coreModels.StatSpread
+	}{
+		{
+			name:   "raised and lowered stats",
+			base:   garchomp,
+			level:  78,
+			ivs:    coreModels.StatSpread{HP: 24, Attack: 12, Defense: 30, SpecialAttack: 16, SpecialDefense: 23, Speed: 5},
+			evs:    coreModels.StatSpread{HP: 74, Attack: 190, Defense: 91, SpecialAttack: 48, SpecialDefense: 84, Speed: 23},
+			nature: "Adamant",
+			want:   coreModels.StatSpread{HP: 289, Attack: 278, Defense: 193, SpecialAttack: 135, SpecialDefense: 171, Speed: 171},
+		},
+		{
+			name:   "neutral nature",
+			base:   even,
+			level:  100,
+			ivs:    PerfectIVs,
+			nature: "hardy",
+			want:   coreModels.StatSpread{HP: 341, Attack: 236, Defense: 236, SpecialAttack: 236, SpecialDefense: 236, Speed: 236},
+		},
+		{
+			name:   "unknown nature is neutral",
+			base:   even,
+			level:  100,
+			ivs:    PerfectIVs,
+			nature: "grumpy",
+			want:   coreModels.StatSpread{HP: 341, Attack: 236, Defense: 236, SpecialAttack: 236, SpecialDefense: 236, Speed: 236},
+		},
+		{
+			name:   "maxed EVs",
+			base:   even,
+			level:  100,
+			ivs:    PerfectIVs,
+			evs:    coreModels.StatSpread{Attack: 252, Speed: 252, HP: 4},
+			nature: "JOLLY",
+			want:   coreModels.StatSpread{HP: 342, Attack: 299, Defense: 236, SpecialAttack: 212, SpecialDefense: 236, Speed: 328},
+		},
+		{
+			name:  "level 1",
+			base:  Models.Stats{Hp: 1, Attack: 1, Defense: 1, SpecialAttack: 1, SpecialDefense: 1, Speed: 1},
+			level: 1,
+			want:  coreModels.StatSpread{HP: 11, Attack: 5, Defense: 5, SpecialAttack: 5, SpecialDefense: 5, Speed: 5},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CalculateStats(test.base, test.level, test.ivs, test.evs, test.nature); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckSpread(t *testing.T) {
+	spread := func(hp, attack int) *coreModels.StatSpread {
+		return &coreModels.StatSpread{HP: hp, Attack: attack}
+	}
+	tests := []struct {
+		name   string
+		level  int
+		ivs    *coreModels.StatSpread
+		evs    *coreModels.StatSpread
+		nature string
+		want   string
+	}{
+		{name: "in range", level: 50, ivs: spread(31, 0), evs: spread(255, 255), nature: "Timid"},
+		{name: "nothing set", level: 1},
+		{name: "level too low", level: 0, want: "level 0 is not between 1 and 100"},
+		{name: "level too high", level: 101, want: "level 101 is not between 1 and 100"},
+		{name: "unknown nature", level: 50, nature: "grumpy", want: `nature "grumpy" does not exist`},
+		{name: "IV too high", level: 50, ivs: spread(0, 32), want: "attack IV 32 is not between 0 and 31"},
+		{name: "negative IV", level: 50, ivs: spread(-1, 0), want: "hp IV -1 is not between 0 and 31"},
+		{name: "EV too high", level: 50, evs: spread(256, 0), want: "hp EV 256 is not between 0 and 255"},
+		{name: "too many EVs in total", level: 50, evs: &coreModels.StatSpread{HP: 255, Attack: 255, Speed: 1}, want: "511 EVs in total, at most 510 are allowed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckSpread(test.level, test.ivs, test.evs, test.nature)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyStats(t *testing.T) {
+	base := Models.Stats{Hp: 100, Attack: 100, Defense: 100, SpecialAttack: 100, SpecialDefense: 100, Speed: 100}
+	hand := coreModels.TrainerPokemon{Pokemons: Models.Pokemons{Level: 100, HP: 1, Attack: 2}}
+	if ApplyStats(&hand, base) || hand.HP != 1 || hand.Attack != 2 {
+		t.Errorf("hand typed stats were changed to %+v", hand)
+	}
+	ivs := PerfectIVs
+	derived := coreModels.TrainerPokemon{Pokemons: Models.Pokemons{Level: 100}, IVs: &ivs}
+	if !ApplyStats(&derived, base) || derived.HP != 341 || derived.Speed != 236 {
+		t.Errorf("stats derived from IVs are %+v, want 341 HP and 236 speed", derived)
+	}
+	if ApplyStats(&derived, base) {
+		t.Error("applying the same stats again reported a change")
+	}
+}
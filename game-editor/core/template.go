@@ -0,0 +1,270 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// engineManifestFile sits at the root of an engine checkout or template
+// archive and names the engine version projects created from it are on
+const engineManifestFile = "engine.toml"
+
+// TemplateEnvironment names a template used when a project is created
+// without one, so air-gapped machines never reach for GitHub
+const TemplateEnvironment = "POKEMON_ENGINE_TEMPLATE"
+
+type engineManifest struct {
+	Name    string `toml:"name"`
+	Version string `toml:"version"`
+}
+
+// installTemplate fills the empty directory destination from template, a
+// local engine checkout or a .zip, .tar.gz or .tgz archive of one, and
+// returns the engine version
+func installTemplate(template string, destination string) (string, error) {
+	info, err := os.Stat(template)
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", template, err)
+	}
+	if info.IsDir() {
+		if err := copyDirectory(template, destination); err != nil {
+			return "", err
+		}
+		return engineVersion(destination, template)
+	}
+
+	// Archives are unpacked next to the project so the result can be renamed
+	// into place, dropping the single top level folder archives usually have
+	staging, err := os.MkdirTemp(filepath.Dir(destination), ".template-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+	lowerName := strings.ToLower(template)
+	switch {
+	case strings.HasSuffix(lowerName, ".zip"):
+		err = extractZip(template, staging)
+	case strings.HasSuffix(lowerName, ".tar.gz") || strings.HasSuffix(lowerName, ".tgz"):
+		err = extractTarGz(template, staging)
+	default:
+		err = fmt.Errorf("template %s is not a directory, .zip, .tar.gz or .tgz", template)
+	}
+	if err != nil {
+		return "", err
+	}
+	root := staging
+	if _, err := os.Stat(filepath.Join(staging, engineManifestFile)); errors.Is(err, fs.ErrNotExist) {
+		if entries, err := os.ReadDir(staging); err == nil && len(entries) == 1 && entries[0].IsDir() {
+			root = filepath.Join(staging, entries[0].Name())
+		}
+	}
+	if err := os.Remove(destination); err != nil {
+		return "", err
+	}
+	if err := os.Rename(root, destination); err != nil {
+		return "", err
+	}
+	if err := os.RemoveAll(filepath.Join(destination, ".git")); err != nil {
+		return "", err
+	}
+	return engineVersion(destination, "")
+}
+
+// engineVersion reads the version from the manifest in root, falling back to
+// the HEAD commit when checkout is a git repository
+func engineVersion(root string, checkout string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(root, engineManifestFile))
+	if err == nil {
+		var manifest engineManifest
+		if err := toml.Unmarshal(b, &manifest); err != nil {
+			return "", fmt.Errorf("%s is malformed: %w", engineManifestFile, err)
+		}
+		if manifest.Version == "" {
+			return "", fmt.Errorf("%s has no version", engineManifestFile)
+		}
+		return manifest.Version, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if checkout != "" {
+		if repository, err := git.PlainOpen(checkout); err == nil {
+			if head, err := repository.Head(); err == nil {
+				return head.Hash().String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("template has no %s naming its engine version", engineManifestFile)
+}
+
+// copyDirectory copies the tree at source into destination, leaving out the
+// source's git metadata
+func copyDirectory(source string, destination string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		// Skip git metadata, and the project itself when it is created inside
+		// the template
+		if entry.IsDir() && (entry.Name() == ".git" || filepath.Clean(path) == filepath.Clean(destination)) {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(destination, relative)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return writeTemplateFile(target, file, info.Mode().Perm())
+	})
+}
+
+func extractZip(archive string, destination string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		target, err := archiveTarget(destination, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		contents, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeTemplateFile(target, contents, file.Mode().Perm())
+		contents.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive string, destination string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	decompressed, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+	reader := tar.NewReader(decompressed)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := archiveTarget(destination, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeTemplateFile(target, reader, fs.FileMode(header.Mode).Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archiveTarget maps an archive entry into destination, refusing entries that
+// would be written outside of it
+func archiveTarget(destination string, name string) (string, error) {
+	target := filepath.Join(destination, filepath.FromSlash(name))
+	if target != destination && !strings.HasPrefix(target, destination+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %s points outside of the project", name)
+	}
+	return target, nil
+}
+
+func writeTemplateFile(target string, contents io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, contents); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// cloneEngine clones the latest engine from GitHub into destination and
+// returns the commit it is on
+func cloneEngine(destination string) (string, error) {
+	githubEngineUrl := "https://api.github.com/repos/zenith110/pokemon-go-engine/git/refs/heads/main"
+	response, err := http.Get(githubEngineUrl)
+	if err != nil {
+		return "", fmt.Errorf("error looking up the latest engine version: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error looking up the latest engine version: %s", response.Status)
+	}
+	var project coreModels.GithubInfo
+	if err := json.NewDecoder(response.Body).Decode(&project); err != nil {
+		return "", fmt.Errorf("error reading the latest engine version: %w", err)
+	}
+
+	_, err = git.PlainClone(destination, false, &git.CloneOptions{
+		URL:      "https://github.com/zenith110/pokemon-go-engine",
+		Progress: os.Stdout,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error cloning the engine: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(destination, ".git")); err != nil {
+		return "", err
+	}
+	return project.Object.Sha, nil
+}
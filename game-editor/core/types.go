@@ -0,0 +1,150 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// TypeChart maps an attacking type to the defending types it is not neutral
+// against and their multiplier, types are keyed in lower case
+type TypeChart map[string]map[string]float64
+
+// DefaultTypes are the types of the mainline games since the sixth generation
+var DefaultTypes = []string{
+	"Normal", "Fire", "Water", "Electric", "Grass", "Ice", "Fighting", "Poison", "Ground",
+	"Flying", "Psychic", "Bug", "Rock", "Ghost", "Dragon", "Dark", "Steel", "Fairy",
+}
+
+// DefaultTypeColors are the colors the editors show the default types in
+var DefaultTypeColors = map[string]string{
+	"Normal": "#9ca3af", "Fire": "#f97316", "Water": "#3b82f6", "Electric": "#facc15", "Grass": "#4ade80", "Ice": "#bfdbfe",
+	"Fighting": "#dc2626", "Poison": "#a855f7", "Ground": "#ca8a04", "Flying": "#818cf8", "Psychic": "#ec4899", "Bug": "#22c55e",
+	"Rock": "#a16207", "Ghost": "#c084fc", "Dragon": "#9333ea", "Dark": "#1f2937", "Steel": "#6b7280", "Fairy": "#f472b6",
+}
+
+// DefaultTypeChart is the type chart of the mainline games since the sixth
+// generation
+var DefaultTypeChart = TypeChart{
+	"normal":   {"rock": 0.5, "ghost": 0, "steel": 0.5},
+	"fire":     {"fire": 0.5, "water": 0.5, "grass": 2, "ice": 2, "bug": 2, "rock": 0.5, "dragon": 0.5, "steel": 2},
+	"water":    {"fire": 2, "water": 0.5, "grass": 0.5, "ground": 2, "rock": 2, "dragon": 0.5},
+	"electric": {"water": 2, "electric": 0.5, "grass": 0.5, "ground": 0, "flying": 2, "dragon": 0.5},
+	"grass":    {"fire": 0.5, "water": 2, "grass": 0.5, "poison": 0.5, "ground": 2, "flying": 0.5, "bug": 0.5, "rock": 2, "dragon": 0.5, "steel": 0.5},
+	"ice":      {"fire": 0.5, "water": 0.5, "grass": 2, "ice": 0.5, "ground": 2, "flying": 2, "dragon": 2, "steel": 0.5},
+	"fighting": {"normal": 2, "ice": 2, "poison": 0.5, "flying": 0.5, "psychic": 0.5, "bug": 0.5, "rock": 2, "ghost": 0, "dark": 2, "steel": 2, "fairy": 0.5},
+	"poison":   {"grass": 2, "poison": 0.5, "ground": 0.5, "rock": 0.5, "ghost": 0.5, "steel": 0, "fairy": 2},
+	"ground":   {"fire": 2, "electric": 2, "grass": 0.5, "poison": 2, "flying": 0, "bug": 0.5, "rock": 2, "steel": 2},
+	"flying":   {"electric": 0.5, "grass": 2, "fighting": 2, "bug": 2, "rock": 0.5, "steel": 0.5},
+	"psychic":  {"fighting": 2, "poison": 2, "psychic": 0.5, "dark": 0, "steel": 0.5},
+	"bug":      {"fire": 0.5, "grass": 2, "fighting": 0.5, "poison": 0.5, "flying": 0.5, "psychic": 2, "ghost": 0.5, "dark": 2, "steel": 0.5, "fairy": 0.5},
+	"rock":     {"fire": 2, "ice": 2, "fighting": 0.5, "ground": 0.5, "flying": 2, "bug": 2, "steel": 0.5},
+	"ghost":    {"normal": 0, "psychic": 2, "ghost": 2, "dark": 0.5},
+	"dragon":   {"dragon": 2, "steel": 0.5, "fairy": 0},
+	"dark":     {"fighting": 0.5, "psychic": 2, "ghost": 2, "dark": 0.5, "fairy": 0.5},
+	"steel":    {"fire": 0.5, "water": 0.5, "electric": 0.5, "ice": 2, "rock": 2, "steel": 0.5, "fairy": 2},
+	"fairy":    {"fire": 0.5, "fighting": 2, "poison": 0.5, "dragon": 2, "dark": 2, "steel": 0.5},
+}
+
+// Effectiveness returns the multiplier of a move of moveType against a
+// pokemon of the defending types. Unknown and empty types are neutral.
+func (chart TypeChart) Effectiveness(moveType string, defending []string) float64 {
+	multiplier := 1.0
+	against := chart[strings.ToLower(moveType)]
+	for _, defendingType := range defending {
+		if value, ok := against[strings.ToLower(defendingType)]; ok {
+			multiplier *= value
+		}
+	}
+	return multiplier
+}
+
+// typeColor is the #rrggbb form of a type's color
+var typeColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// DefaultTypeDefinitions returns the default types with their colors and the
+// default type chart, as a new types.toml starts out
+func DefaultTypeDefinitions() []coreModels.TypeDefinition {
+	names := make(map[string]string)
+	for _, name := range DefaultTypes {
+		names[strings.ToLower(name)] = name
+	}
+	definitions := make([]coreModels.TypeDefinition, 0, len(DefaultTypes))
+	for _, name := range DefaultTypes {
+		effectiveness := make(map[string]float64)
+		for defending, multiplier := range DefaultTypeChart[strings.ToLower(name)] {
+			effectiveness[names[defending]] = multiplier
+		}
+		definitions = append(definitions, coreModels.TypeDefinition{Name: name, Color: DefaultTypeColors[name], Effectiveness: effectiveness})
+	}
+	return definitions
+}
+
+// NewTypeChart builds the type chart of a project's type definitions
+func NewTypeChart(definitions []coreModels.TypeDefinition) TypeChart {
+	chart := make(TypeChart)
+	for _, definition := range definitions {
+		against := make(map[string]float64)
+		for defending, multiplier := range definition.Effectiveness {
+			against[strings.ToLower(defending)] = multiplier
+		}
+		chart[strings.ToLower(definition.Name)] = against
+	}
+	return chart
+}
+
+// ProjectTypes returns the types of the project at dataDirectory, the default
+// types when it has no types.toml
+func ProjectTypes(dataDirectory string) ([]coreModels.TypeDefinition, error) {
+	definitions, err := repository.NewTypeRepository(dataDirectory).All()
+	if errors.Is(err, repository.ErrMissingFile) {
+		return DefaultTypeDefinitions(), nil
+	}
+	return definitions, err
+}
+
+// CheckTypes returns what is wrong with a project's type definitions: names
+// that are empty or used twice, colors that are not #rrggbb and multipliers
+// against unknown types or below 0
+func CheckTypes(definitions []coreModels.TypeDefinition) []string {
+	var problems []string
+	names := make(map[string]bool)
+	for _, definition := range definitions {
+		names[strings.ToLower(definition.Name)] = true
+	}
+	seen := make(map[string]bool)
+	for _, definition := range definitions {
+		key := strings.ToLower(definition.Name)
+		switch {
+		case strings.TrimSpace(definition.Name) == "":
+			problems = append(problems, "a type has no name")
+			continue
+		case seen[key]:
+			problems = append(problems, fmt.Sprintf("type %s is defined twice", definition.Name))
+		}
+		seen[key] = true
+		if !typeColor.MatchString(definition.Color) {
+			problems = append(problems, fmt.Sprintf("the color of %s is %q, colors are written as #rrggbb", definition.Name, definition.Color))
+		}
+		defendingTypes := make([]string, 0, len(definition.Effectiveness))
+		for defending := range definition.Effectiveness {
+			defendingTypes = append(defendingTypes, defending)
+		}
+		sort.Strings(defendingTypes)
+		for _, defending := range defendingTypes {
+			multiplier := definition.Effectiveness[defending]
+			if !names[strings.ToLower(defending)] {
+				problems = append(problems, fmt.Sprintf("%s has a multiplier against %s, which is not a type", definition.Name, defending))
+			}
+			if multiplier < 0 {
+				problems = append(problems, fmt.Sprintf("%s's multiplier against %s is %g, multipliers cannot be negative", definition.Name, defending, multiplier))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,349 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+// EngineUpgrade asks to move the current project to a newer engine.
+//
+// Source is a local engine checkout, a .zip or .tar.gz template or a git
+// remote URL. Ref optionally picks a branch, tag or commit of a git source
+// instead of its checked out tree. Base is the template the project was
+// created from, only needed when Source has no git history containing the
+// project's engine version. Resolutions settles conflicts by key with "ours"
+// or "theirs".
+type EngineUpgrade struct {
+	Source      string            `json:"source"`
+	Ref         string            `json:"ref"`
+	Base        string            `json:"base"`
+	DryRun      bool              `json:"dryRun"`
+	Resolutions map[string]string `json:"resolutions"`
+}
+
+// EngineUpgradeReport lists what an engine upgrade changed, or would change
+// when it was a dry run or has unresolved conflicts. Nothing is written
+// while Conflicts is not empty.
+type EngineUpgradeReport struct {
+	FromVersion string          `json:"fromVersion"`
+	ToVersion   string          `json:"toVersion"`
+	Added       []string        `json:"added"`
+	Updated     []string        `json:"updated"`
+	Removed     []string        `json:"removed"`
+	Merged      []string        `json:"merged"`
+	Conflicts   []MergeConflict `json:"conflicts"`
+	Applied     bool            `json:"applied"`
+}
+
+// HasErrors reports unresolved conflicts, failing the CLI until they are
+// settled
+func (r EngineUpgradeReport) HasErrors() bool {
+	return len(r.Conflicts) > 0
+}
+
+// engineTree is every file of one engine version keyed by slash separated
+// path
+type engineTree map[string][]byte
+
+// UpgradeEngine three-way merges the engine version the project was created
+// from, the new engine and the project. Files the project did not touch are
+// replaced, data files both sides changed are merged record by record and
+// anything changed differently by both is reported as a conflict. The project
+// is only written, as one undoable journal entry, when there are no conflicts
+// left, after which its VersionOfEngine is updated.
+func (a *App) UpgradeEngine(upgrade EngineUpgrade) (EngineUpgradeReport, error) {
+	var report EngineUpgradeReport
+	if a.DataDirectory == "" {
+		return report, errors.New("no project selected")
+	}
+	if upgrade.Source == "" {
+		return report, errors.New("no engine source given")
+	}
+	project, err := a.Projects.FindByPath(a.DataDirectory)
+	if err != nil {
+		return report, err
+	}
+	report.FromVersion = project.VersionOfEngine
+
+	workspace, err := os.MkdirTemp("", "engine-upgrade-*")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(workspace)
+
+	source, err := openEngineSource(strings.ReplaceAll(upgrade.Source, "\\", "/"), filepath.Join(workspace, "source"))
+	if err != nil {
+		return report, err
+	}
+	theirs, version, err := source.tree(upgrade.Ref)
+	if err != nil {
+		return report, err
+	}
+	report.ToVersion = version
+
+	var base engineTree
+	if upgrade.Base != "" {
+		baseSource, err := openEngineSource(strings.ReplaceAll(upgrade.Base, "\\", "/"), filepath.Join(workspace, "base"))
+		if err != nil {
+			return report, err
+		}
+		if base, _, err = baseSource.tree(""); err != nil {
+			return report, err
+		}
+	} else {
+		if project.VersionOfEngine == "" {
+			return report, errors.New("the project does not record its engine version, pass the template it was created from as base")
+		}
+		if base, err = source.version(project.VersionOfEngine); err != nil {
+			return report, err
+		}
+	}
+
+	paths := make([]string, 0, len(theirs))
+	for path := range theirs {
+		paths = append(paths, path)
+	}
+	for path := range base {
+		if _, ok := theirs[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	writes := make(map[string][]byte)
+	for _, path := range paths {
+		baseData, theirData := base[path], theirs[path]
+		if sameFile(baseData, theirData) {
+			continue
+		}
+		ourData, err := os.ReadFile(fmt.Sprintf("%s/%s", a.DataDirectory, path))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return report, err
+		}
+		if err != nil {
+			ourData = nil
+		}
+		outcome := mergeFile(path, baseData, ourData, theirData, upgrade.Resolutions)
+		report.Conflicts = append(report.Conflicts, outcome.conflicts...)
+		if sameFile(outcome.data, ourData) {
+			continue
+		}
+		switch {
+		case outcome.data == nil:
+			report.Removed = append(report.Removed, path)
+		case ourData == nil:
+			report.Added = append(report.Added, path)
+		case outcome.records > 0 && !sameFile(outcome.data, theirData):
+			report.Merged = append(report.Merged, path)
+		default:
+			report.Updated = append(report.Updated, path)
+		}
+		writes[path] = outcome.data
+	}
+	if upgrade.DryRun || len(report.Conflicts) > 0 {
+		return report, nil
+	}
+
+	defer a.Journal.Begin(fmt.Sprintf("Upgrade engine to %s", version))()
+	for _, path := range paths {
+		data, ok := writes[path]
+		if !ok {
+			continue
+		}
+		target := fmt.Sprintf("%s/%s", a.DataDirectory, path)
+		if data == nil {
+			err = repository.RemoveFile(target)
+		} else {
+			err = repository.WriteFile(target, data, 0644)
+		}
+		if err != nil {
+			return report, fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+	if err := a.Projects.SetEngineVersion(project.ID, version); err != nil {
+		return report, fmt.Errorf("upgraded the project but could not record its engine version: %w", err)
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// engineSource is where engine versions are read from, a plain directory or
+// a git repository
+type engineSource struct {
+	directory  string
+	repository *git.Repository
+}
+
+// openEngineSource opens a local checkout, unpacks a template archive or
+// clones a remote into scratch
+func openEngineSource(source string, scratch string) (*engineSource, error) {
+	if strings.Contains(source, "://") || strings.HasPrefix(source, "git@") {
+		repository, err := git.PlainClone(scratch, false, &git.CloneOptions{URL: source})
+		if err != nil {
+			return nil, fmt.Errorf("error cloning %s: %w", source, err)
+		}
+		return &engineSource{directory: scratch, repository: repository}, nil
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("engine source %s: %w", source, err)
+	}
+	if !info.IsDir() {
+		if err := os.MkdirAll(scratch, 0755); err != nil {
+			return nil, err
+		}
+		if _, err := installTemplate(source, scratch); err != nil {
+			return nil, err
+		}
+		return &engineSource{directory: scratch}, nil
+	}
+	engine := &engineSource{directory: source}
+	if repository, err := git.PlainOpen(source); err == nil {
+		engine.repository = repository
+	}
+	return engine, nil
+}
+
+// tree reads the files of ref, or of the source's directory without one, and
+// the engine version they are
+func (s *engineSource) tree(ref string) (engineTree, string, error) {
+	if ref == "" {
+		tree, err := directoryTree(s.directory)
+		if err != nil {
+			return nil, "", err
+		}
+		version, err := engineVersion(s.directory, s.directory)
+		return tree, version, err
+	}
+	if s.repository == nil {
+		return nil, "", fmt.Errorf("cannot check out %s, the engine source is not a git repository", ref)
+	}
+	hash, err := s.repository.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, "", fmt.Errorf("engine source has no %s: %w", ref, err)
+	}
+	tree, err := s.commitTree(*hash)
+	if err != nil {
+		return nil, "", err
+	}
+	var manifest engineManifest
+	if err := toml.Unmarshal(tree[engineManifestFile], &manifest); err == nil && manifest.Version != "" {
+		return tree, manifest.Version, nil
+	}
+	return tree, hash.String(), nil
+}
+
+// version finds the files of an earlier engine version in the source's git
+// history, by commit, by tag or by the version in the engine manifest
+func (s *engineSource) version(version string) (engineTree, error) {
+	if s.repository == nil {
+		return nil, fmt.Errorf("engine source has no history to find version %s in, pass the template the project was created from as base", version)
+	}
+	for _, revision := range []string{version, "v" + version, "refs/tags/" + version} {
+		if hash, err := s.repository.ResolveRevision(plumbing.Revision(revision)); err == nil {
+			return s.commitTree(*hash)
+		}
+	}
+	// Untagged versions are found by the newest commit whose manifest names them
+	if head, err := s.repository.Head(); err == nil {
+		commits, err := s.repository.Log(&git.LogOptions{From: head.Hash()})
+		if err != nil {
+			return nil, err
+		}
+		var found *object.Commit
+		commits.ForEach(func(commit *object.Commit) error {
+			file, err := commit.File(engineManifestFile)
+			if err != nil {
+				return nil
+			}
+			contents, err := file.Contents()
+			if err != nil {
+				return nil
+			}
+			var manifest engineManifest
+			if toml.Unmarshal([]byte(contents), &manifest) == nil && manifest.Version == version {
+				found = commit
+				return storer.ErrStop
+			}
+			return nil
+		})
+		if found != nil {
+			return s.commitTree(found.Hash)
+		}
+	}
+	return nil, fmt.Errorf("engine version %s is not in the source's history, pass the template the project was created from as base", version)
+}
+
+func (s *engineSource) commitTree(hash plumbing.Hash) (engineTree, error) {
+	commit, err := s.repository.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	files, err := commit.Files()
+	if err != nil {
+		return nil, err
+	}
+	tree := make(engineTree)
+	err = files.ForEach(func(file *object.File) error {
+		if file.Mode == filemode.Symlink || file.Mode == filemode.Submodule {
+			return nil
+		}
+		reader, err := file.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		tree[file.Name] = data
+		return nil
+	})
+	return tree, err
+}
+
+// directoryTree reads every regular file below root, leaving out git
+// metadata and the editor's own files
+func directoryTree(root string) (engineTree, error) {
+	tree := make(engineTree)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && (entry.Name() == ".git" || entry.Name() == ".editor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tree[filepath.ToSlash(relative)] = data
+		return nil
+	})
+	return tree, err
+}
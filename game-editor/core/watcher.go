@@ -0,0 +1,264 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// watchDebounce is how long a file has to stay quiet before it is reported,
+// editors and git write files in several steps
+const watchDebounce = 300 * time.Millisecond
+
+// watchedDirectories are the project folders the editors read data from
+var watchedDirectories = []string{"data/toml", "data/assets"}
+
+// ProjectDataChange is emitted as project-data-changed when a data file was
+// changed outside of the editor, e.g. in a text editor or by a git pull
+type ProjectDataChange struct {
+	File    string   `json:"file"`
+	Records []string `json:"records"`
+	Removed bool     `json:"removed"`
+}
+
+// EventEmitter is the part of the Wails event manager the core emits through
+type EventEmitter interface {
+	Emit(name string, data ...any)
+}
+
+// ProjectWatcher reports changes to the current project's data files
+type ProjectWatcher struct {
+	app *App
+
+	mu          sync.Mutex
+	watcher     *fsnotify.Watcher
+	directory   string
+	known       map[string][]byte
+	own         map[string]string
+	pending     map[string]bool
+	timer       *time.Timer
+	subscribers []func(ProjectDataChange)
+}
+
+func newProjectWatcher(app *App) *ProjectWatcher {
+	watcher := &ProjectWatcher{
+		app:     app,
+		known:   make(map[string][]byte),
+		own:     make(map[string]string),
+		pending: make(map[string]bool),
+	}
+	repository.AddWriteHook(watcher.ownWrite)
+	return watcher
+}
+
+// Subscribe calls fn for every change, before the event reaches the frontend,
+// so services can drop what they cached from the file
+func (w *ProjectWatcher) Subscribe(fn func(change ProjectDataChange)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Watch starts watching the data folders of the project at directory,
+// replacing the project watched before
+func (w *ProjectWatcher) Watch(directory string) error {
+	w.Stop()
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	known := make(map[string][]byte)
+	for _, folder := range watchedDirectories {
+		root := fmt.Sprintf("%s/%s", directory, folder)
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return watcher.Add(path)
+			}
+			if strings.HasSuffix(path, ".toml") {
+				if data, err := os.ReadFile(path); err == nil {
+					known[cleanPath(path)] = data
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	w.mu.Lock()
+	w.watcher = watcher
+	w.directory = cleanPath(directory)
+	w.known = known
+	w.own = make(map[string]string)
+	w.mu.Unlock()
+	go w.run(watcher)
+	return nil
+}
+
+// Stop stops watching, dropping changes that were not reported yet
+func (w *ProjectWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watcher != nil {
+		w.watcher.Close()
+		w.watcher = nil
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.pending = make(map[string]bool)
+}
+
+func (w *ProjectWatcher) run(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			w.handle(watcher, event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Printf("error while watching project: %v\n", err)
+		}
+	}
+}
+
+func (w *ProjectWatcher) handle(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			filepath.WalkDir(event.Name, func(path string, entry fs.DirEntry, err error) error {
+				if err == nil && entry.IsDir() {
+					watcher.Add(path)
+				}
+				return nil
+			})
+			return
+		}
+	}
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return
+	}
+	path := cleanPath(event.Name)
+	// Temp files and backups are the atomic save's own bookkeeping
+	if strings.HasSuffix(path, ".tmp") || strings.HasSuffix(path, ".bak") {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watcher != watcher {
+		return
+	}
+	w.pending[path] = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(watchDebounce, w.flush)
+	} else {
+		w.timer.Reset(watchDebounce)
+	}
+}
+
+// flush reports every file that settled since the last flush
+func (w *ProjectWatcher) flush() {
+	w.mu.Lock()
+	directory := w.directory
+	var paths []string
+	for path := range w.pending {
+		paths = append(paths, path)
+	}
+	w.pending = make(map[string]bool)
+	w.timer = nil
+	w.mu.Unlock()
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		removed := errors.Is(err, fs.ErrNotExist)
+		if err != nil && !removed {
+			continue
+		}
+
+		w.mu.Lock()
+		previous, wasKnown := w.known[path]
+		own, wrote := w.own[path]
+		ownWrite := wrote && own == blobName(data)
+		if strings.HasSuffix(path, ".toml") {
+			if removed {
+				delete(w.known, path)
+			} else {
+				w.known[path] = data
+			}
+		}
+		w.mu.Unlock()
+		// The editor's own saves are already known to whoever made them
+		if ownWrite {
+			continue
+		}
+
+		change := ProjectDataChange{
+			File:    strings.TrimPrefix(path, directory+"/"),
+			Removed: removed,
+		}
+		if strings.HasSuffix(path, ".toml") {
+			if wasKnown && !removed && string(previous) == string(data) {
+				continue
+			}
+			if records, err := repository.ChangedRecords(previous, data); err == nil {
+				change.Records = records
+			}
+		}
+		w.publish(change)
+	}
+}
+
+func (w *ProjectWatcher) publish(change ProjectDataChange) {
+	w.mu.Lock()
+	subscribers := append([]func(ProjectDataChange){}, w.subscribers...)
+	w.mu.Unlock()
+	for _, subscriber := range subscribers {
+		subscriber(change)
+	}
+	if w.app.Events != nil {
+		w.app.Events.Emit("project-data-changed", change)
+	}
+}
+
+// ownWrite is the repository write hook, it remembers what the editor itself
+// last wrote so the matching file event is not reported as an outside change
+func (w *ProjectWatcher) ownWrite(path string, before []byte, after []byte) {
+	path = cleanPath(path)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.own[path] = blobName(after)
+	if strings.HasSuffix(path, ".toml") {
+		if after == nil {
+			delete(w.known, path)
+		} else {
+			w.known[path] = after
+		}
+	}
+}
+
+func cleanPath(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
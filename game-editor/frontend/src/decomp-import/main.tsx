@@ -0,0 +1,93 @@
+import { useState } from "react"
+import { Button } from "../components/ui/button"
+import { GrabDecompCheckout, ImportDecomp } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/decomp-importer/DecompImporterApp"
+import { DecompImportReport } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/decomp-importer/models"
+
+// Bootstraps the project from a pokeemerald or pokefirered checkout,
+// previewing what converts and which constants have no counterpart
+const DecompImport = () => {
+    const [source, setSource] = useState("")
+    const [replace, setReplace] = useState(false)
+    const [report, setReport] = useState<DecompImportReport | null>(null)
+    const [isRunning, setIsRunning] = useState(false)
+    const [error, setError] = useState("")
+
+    const importDecomp = async (dryRun: boolean) => {
+        if (!dryRun && replace && !confirm("Replace the project's species, moves and trainers with the imported ones?")) {
+            return
+        }
+        setIsRunning(true)
+        try {
+            setReport(await ImportDecomp({ source, dryRun, replace }))
+            setError("")
+        } catch (error) {
+            setError(`Could not import the checkout: ${error}`)
+        } finally {
+            setIsRunning(false)
+        }
+    }
+
+    const unmapped = report?.unmapped ?? []
+    const counts = [
+        ["Pokemon", report?.pokemon],
+        ["Moves", report?.moves],
+        ["Trainers", report?.trainers],
+        ["Trainer classes", report?.trainerClasses],
+    ] as const
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <h1 className="text-2xl font-bold">Decomp Import</h1>
+                <div className="space-y-2 rounded-lg bg-slate-800 p-4">
+                    <div className="flex gap-2">
+                        <input
+                            value={source}
+                            onChange={(e) => setSource(e.target.value)}
+                            placeholder="pokeemerald or pokefirered checkout"
+                            className="flex-1 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <Button onClick={async () => setSource(await GrabDecompCheckout())} className="bg-tealBlue hover:bg-wildBlueYonder">Browse</Button>
+                    </div>
+                    <div className="flex items-center gap-4">
+                        <label className="flex items-center gap-2 text-sm">
+                            <input type="checkbox" checked={replace} onChange={(e) => setReplace(e.target.checked)} />
+                            Replace existing species, moves and trainers
+                        </label>
+                        <Button onClick={() => importDecomp(true)} disabled={isRunning || !source} className="bg-tealBlue hover:bg-wildBlueYonder">
+                            Preview
+                        </Button>
+                        <Button onClick={() => importDecomp(false)} disabled={isRunning || !source} className="bg-tealBlue hover:bg-wildBlueYonder">
+                            {isRunning ? "Importing..." : "Import"}
+                        </Button>
+                    </div>
+                </div>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                {report && (
+                    <div className="rounded-lg bg-slate-800 p-4">
+                        <p className={report.applied ? "text-green-400" : "text-slate-400"}>
+                            {report.applied ? "Imported" : "Would import"} {counts.map(([label, count]) => `${count ?? 0} ${label.toLowerCase()}`).join(", ")}
+                        </p>
+                        <p className="text-sm text-slate-400">Read {(report.files ?? []).length} file(s) of the checkout</p>
+                    </div>
+                )}
+                {unmapped.length > 0 && (
+                    <div className="space-y-2">
+                        <h2 className="text-lg font-semibold text-yellow-400">{unmapped.length} unmapped constant(s)</h2>
+                        {unmapped.map((constant) => (
+                            <div key={`${constant.constant}-${constant.reason}`} className="rounded-lg bg-slate-800 p-3">
+                                <p>
+                                    {constant.constant}
+                                    <span className="text-sm text-slate-400"> in {constant.record}{constant.count > 1 && ` and ${constant.count - 1} more`}</span>
+                                </p>
+                                <p className="text-sm text-slate-300">{constant.reason}</p>
+                            </div>
+                        ))}
+                    </div>
+                )}
+            </div>
+        </div>
+    )
+}
+
+export default DecompImport
@@ -0,0 +1,110 @@
+import { useState } from "react"
+import { Button } from "../components/ui/button"
+import { GrabProjectTemplate, GrabProjectWorkspace, UpgradeEngine } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App"
+import { EngineUpgradeReport } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/models"
+
+// Merges a newer engine into the project, previewing first and letting each
+// conflict be settled by keeping the project's or the engine's side
+const EngineUpgrade = () => {
+    const [source, setSource] = useState("")
+    const [base, setBase] = useState("")
+    const [resolutions, setResolutions] = useState<Record<string, string>>({})
+    const [report, setReport] = useState<EngineUpgradeReport | null>(null)
+    const [isRunning, setIsRunning] = useState(false)
+    const [error, setError] = useState("")
+
+    const upgrade = async (dryRun: boolean) => {
+        setIsRunning(true)
+        try {
+            setReport(await UpgradeEngine({ source, ref: "", base, dryRun, resolutions }))
+            setError("")
+        } catch (error) {
+            setError(`Could not upgrade the engine: ${error}`)
+        } finally {
+            setIsRunning(false)
+        }
+    }
+
+    const resolve = (key: string, side: string) => setResolutions({ ...resolutions, [key]: side })
+    const conflicts = report?.conflicts ?? []
+    const changes = [
+        ["Added", report?.added],
+        ["Updated", report?.updated],
+        ["Merged", report?.merged],
+        ["Removed", report?.removed],
+    ] as const
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <div>
+                    <h1 className="text-2xl font-bold">Engine Upgrade</h1>
+                    {report && <p className="text-slate-400">{report.fromVersion || "unknown"} → {report.toVersion}</p>}
+                </div>
+                <div className="space-y-2 rounded-lg bg-slate-800 p-4">
+                    <div className="flex gap-2">
+                        <input
+                            value={source}
+                            onChange={(e) => setSource(e.target.value)}
+                            placeholder="New engine: checkout, template archive or git URL"
+                            className="flex-1 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <Button onClick={async () => setSource(await GrabProjectWorkspace())} className="bg-tealBlue hover:bg-wildBlueYonder">Checkout</Button>
+                        <Button onClick={async () => setSource(await GrabProjectTemplate())} className="bg-tealBlue hover:bg-wildBlueYonder">Archive</Button>
+                    </div>
+                    <div className="flex gap-2">
+                        <input
+                            value={base}
+                            onChange={(e) => setBase(e.target.value)}
+                            placeholder="Original template, only needed when the new engine has no git history"
+                            className="flex-1 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <Button onClick={async () => setBase(await GrabProjectTemplate())} className="bg-tealBlue hover:bg-wildBlueYonder">Archive</Button>
+                    </div>
+                    <div className="flex gap-2">
+                        <Button onClick={() => upgrade(true)} disabled={isRunning || !source} className="bg-tealBlue hover:bg-wildBlueYonder">
+                            Preview
+                        </Button>
+                        <Button onClick={() => upgrade(false)} disabled={isRunning || !source} className="bg-tealBlue hover:bg-wildBlueYonder">
+                            {isRunning ? "Upgrading..." : "Upgrade"}
+                        </Button>
+                    </div>
+                </div>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                {report?.applied && <p className="rounded-lg bg-slate-800 p-4 text-green-400">The project is now on engine {report.toVersion}.</p>}
+                {conflicts.length > 0 && (
+                    <div className="space-y-2">
+                        <h2 className="text-lg font-semibold text-yellow-400">{conflicts.length} conflict(s), nothing was written</h2>
+                        {conflicts.map((conflict) => (
+                            <div key={conflict.key} className="flex items-center justify-between rounded-lg bg-slate-800 p-3">
+                                <div>
+                                    <p>{conflict.file}{conflict.record && ` #${conflict.record}`}</p>
+                                    <p className="text-sm text-slate-400">{conflict.reason}</p>
+                                </div>
+                                <div className="flex gap-2">
+                                    {["ours", "theirs"].map((side) => (
+                                        <Button
+                                            key={side}
+                                            onClick={() => resolve(conflict.key, side)}
+                                            className={resolutions[conflict.key] === side ? "bg-tealBlue" : "bg-slate-700 hover:bg-slate-600"}
+                                        >
+                                            {side === "ours" ? "Keep project" : "Take engine"}
+                                        </Button>
+                                    ))}
+                                </div>
+                            </div>
+                        ))}
+                    </div>
+                )}
+                {report && changes.map(([label, files]) => (files ?? []).length > 0 && (
+                    <div key={label} className="rounded-lg bg-slate-800 p-3">
+                        <h2 className="font-semibold">{label}</h2>
+                        {(files ?? []).map((file) => <p key={file} className="text-sm text-slate-300">{file}</p>)}
+                    </div>
+                ))}
+            </div>
+        </div>
+    )
+}
+
+export default EngineUpgrade
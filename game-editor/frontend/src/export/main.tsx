@@ -0,0 +1,111 @@
+import { useState } from "react"
+import { Button } from "../components/ui/button"
+import { ExportProject, GrabExportDestination, GrabPreviousExport } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/export/ExportApp"
+import { ExportResult } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/export/models"
+
+// Bundles the project's data into a versioned archive, or a patch with only
+// what changed since an earlier export
+const Export = () => {
+    const [destination, setDestination] = useState("")
+    const [version, setVersion] = useState("")
+    const [previous, setPrevious] = useState("")
+    const [compact, setCompact] = useState(true)
+    const [result, setResult] = useState<ExportResult | null>(null)
+    const [isRunning, setIsRunning] = useState(false)
+    const [error, setError] = useState("")
+
+    const exportProject = async () => {
+        setIsRunning(true)
+        try {
+            setResult(await ExportProject({ destination, version, compact, previous }))
+            setError("")
+        } catch (error) {
+            setError(`Could not export the project: ${error}`)
+        } finally {
+            setIsRunning(false)
+        }
+    }
+
+    const errors = (result?.validation.diagnostics ?? []).filter((diagnostic) => diagnostic.severity === "error")
+    const manifest = result?.manifest
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <h1 className="text-2xl font-bold">Export</h1>
+                <div className="space-y-2 rounded-lg bg-slate-800 p-4">
+                    <div className="flex gap-2">
+                        <input
+                            value={destination}
+                            onChange={(e) => setDestination(e.target.value)}
+                            placeholder="Folder the archive is written to"
+                            className="flex-1 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <Button onClick={async () => setDestination(await GrabExportDestination())} className="bg-tealBlue hover:bg-wildBlueYonder">Browse</Button>
+                    </div>
+                    <div className="flex gap-2">
+                        <input
+                            value={previous}
+                            onChange={(e) => setPrevious(e.target.value)}
+                            placeholder="Previous export, only to make a patch"
+                            className="flex-1 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <Button onClick={async () => setPrevious(await GrabPreviousExport())} className="bg-tealBlue hover:bg-wildBlueYonder">Browse</Button>
+                    </div>
+                    <div className="flex items-center gap-4">
+                        <input
+                            value={version}
+                            onChange={(e) => setVersion(e.target.value)}
+                            placeholder="Version, defaults to the build time"
+                            className="w-72 rounded bg-slate-900 px-3 py-2"
+                        />
+                        <label className="flex items-center gap-2 text-sm">
+                            <input type="checkbox" checked={compact} onChange={(e) => setCompact(e.target.checked)} />
+                            Compact TOML and JSON
+                        </label>
+                        <Button onClick={exportProject} disabled={isRunning || !destination} className="bg-tealBlue hover:bg-wildBlueYonder">
+                            {isRunning ? "Exporting..." : previous ? "Export patch" : "Export"}
+                        </Button>
+                    </div>
+                </div>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                {errors.length > 0 && (
+                    <div className="space-y-2">
+                        <h2 className="text-lg font-semibold text-red-400">{errors.length} validation error(s), nothing was exported</h2>
+                        {errors.map((diagnostic, index) => (
+                            <div key={index} className="rounded-lg bg-slate-800 p-3">
+                                <p className="text-sm text-slate-400">
+                                    {diagnostic.file}
+                                    {diagnostic.record && ` #${diagnostic.record}`}
+                                    {diagnostic.field && ` ${diagnostic.field}`}
+                                </p>
+                                <p>{diagnostic.message}</p>
+                            </div>
+                        ))}
+                    </div>
+                )}
+                {result?.archive && manifest && (
+                    <div className="space-y-2 rounded-lg bg-slate-800 p-4">
+                        <p className="text-green-400">Wrote {result.archive}</p>
+                        <p className="text-sm text-slate-400">
+                            {manifest.project} {manifest.version}
+                            {manifest.baseVersion && `, patch of ${manifest.baseVersion}`}
+                            {` on engine ${manifest.engineVersion || "unknown"}`}
+                            {manifest.engineSha && ` (${manifest.engineSha.slice(0, 7)})`}
+                        </p>
+                        {manifest.kind === "patch" ? (
+                            <>
+                                {(manifest.changed ?? []).map((file) => <p key={file} className="text-sm text-slate-300">{file}</p>)}
+                                {(manifest.removed ?? []).map((file) => <p key={file} className="text-sm text-red-300">removed {file}</p>)}
+                            </>
+                        ) : (
+                            <p className="text-sm text-slate-300">{(manifest.files ?? []).length} file(s)</p>
+                        )}
+                    </div>
+                )}
+            </div>
+        </div>
+    )
+}
+
+export default Export
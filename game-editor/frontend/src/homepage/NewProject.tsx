@@ -1,10 +1,11 @@
 import { useState } from "react";
 import { v4 as uuidv4 } from 'uuid';
 
-import { GrabProjectWorkspace, CreateProject} from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
+import { GrabProjectWorkspace, GrabProjectTemplate, CreateProjectFromTemplate} from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
 const NewProject = ({ setClickedNewProject }: { setClickedNewProject: (value: boolean) => void }) => {
     const [projectName, setProjectName] = useState("");
     const [projectDirectory, setProjectDirectory] = useState("")
+    const [template, setTemplate] = useState("")
     const [status, setStatus] = useState("");
     return(
         <div>
@@ -17,23 +18,37 @@ const NewProject = ({ setClickedNewProject }: { setClickedNewProject: (value: bo
                 setProjectDirectory(data)
             }}>Project Directory</button>
             <br/>
+            {/* Without a template the latest engine is cloned from GitHub */}
+            <button onClick={async() => {
+                let data = await GrabProjectTemplate();
+                setTemplate(data)
+            }}>Engine Template Archive</button>
+            <br/>
+            <button onClick={async() => {
+                let data = await GrabProjectWorkspace();
+                setTemplate(data)
+            }}>Local Engine Checkout</button>
+            <p>{template ? `Template: ${template}` : "Template: latest engine from GitHub"}</p>
             <button onClick={async() => {
                 let data = {
                     "name": projectName,
                     "directory": projectDirectory,
-                    "id": uuidv4()
+                    "id": uuidv4(),
+                    "template": template
                 }
                 setStatus(`${projectName} is being created!`)
-                let gitRepoCreationStatus = await CreateProject(data)
-                if(gitRepoCreationStatus === true){
+                try {
+                    await CreateProjectFromTemplate(data)
                     setStatus(`${projectName} has been created!`)
                     setClickedNewProject(false)
                     // Refresh the page to update the navbar
                     window.location.reload();
+                } catch (error) {
+                    setStatus(`${projectName} could not be created: ${error}`)
                 }
             }}>Submit</button>
             <p>{status}</p>
         </div>
     )
 }
-export default NewProject
\ No newline at end of file
+export default NewProject
@@ -6,11 +6,21 @@ import {
     Button,
   } from "@material-tailwind/react";
 import { Project } from "../models/project";
-import { SelectProject } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
+import { useEffect, useState } from "react";
+import { CheckProjects, RelocateProject, RenameProject, SelectProject } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
+import { ProjectHealth } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/models";
 import { useProjects } from "../contexts/ProjectContext";
 
 const ProjectCard = ({ project }: { project: Project }) => {
     const { refreshProjects, hasSelectedProject } = useProjects();
+    const [health, setHealth] = useState<ProjectHealth | null>(null)
+    const [error, setError] = useState("")
+
+    useEffect(() => {
+        CheckProjects()
+            .then((projects) => setHealth(projects.find((p) => p.id === project.ID) ?? null))
+            .catch((error) => console.error('Error checking projects:', error));
+    }, [project.ID, project.FolderLocation]);
     
     let finalDate = ""
     if(project.LastUsed === "N/A"){
@@ -27,10 +37,36 @@ const ProjectCard = ({ project }: { project: Project }) => {
     const handleSelectProject = async () => {
         try {
             await SelectProject(project);
+            setError("");
             // Refresh the projects context instead of reloading the page
             await refreshProjects();
         } catch (error) {
-            console.error('Error selecting project:', error);
+            setError(`${error}`);
+        }
+    };
+
+    const handleRenameProject = async () => {
+        const name = window.prompt("New project name", project.Name);
+        if (!name) {
+            return;
+        }
+        try {
+            await RenameProject(project.ID, name);
+            setError("");
+            await refreshProjects();
+        } catch (error) {
+            setError(`${error}`);
+        }
+    };
+
+    // Asks for the folder the project was moved to
+    const handleRelocateProject = async () => {
+        try {
+            await RelocateProject(project.ID, "");
+            setError("");
+            await refreshProjects();
+        } catch (error) {
+            setError(`${error}`);
         }
     };
 
@@ -49,7 +85,11 @@ const ProjectCard = ({ project }: { project: Project }) => {
                 </CardHeader>
                 <CardBody placeholder="" onPointerEnterCapture={() => {}} onPointerLeaveCapture={() => {}}>
                     <Button placeholder="" className="text-black" onClick={handleSelectProject} onPointerEnterCapture={() => {}} onPointerLeaveCapture={() => {}}>Select</Button>
+                    <Button placeholder="" className="ml-2 text-black" onClick={handleRenameProject} onPointerEnterCapture={() => {}} onPointerLeaveCapture={() => {}}>Rename</Button>
+                    <Button placeholder="" className="ml-2 text-black" onClick={handleRelocateProject} onPointerEnterCapture={() => {}} onPointerLeaveCapture={() => {}}>Relocate</Button>
                     <br/>
+                    {health && health.status !== "ok" && <p className="mt-2 text-sm text-red-600">{health.message}</p>}
+                    {error && <p className="mt-2 text-sm text-red-600">{error}</p>}
                 </CardBody>
                 <CardFooter placeholder="" className="text-black" onPointerEnterCapture={() => {}} onPointerLeaveCapture={() => {}}>
                  {finalDate}
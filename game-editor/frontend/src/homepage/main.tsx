@@ -1,6 +1,6 @@
 import React from "react";
 import { useState, useEffect } from "react"
-import { ImportProject} from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
+import { ImportProject, RemoveProject } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App";
 import { useProjects } from "../contexts/ProjectContext";
 
 import NewProject from "./NewProject";
@@ -11,6 +11,7 @@ const HomePage = () => {
     const { projects, refreshProjects, hasSelectedProject } = useProjects();
     const [clickedNewProject, setClickedNewProject] = useState(false)
     const [currentProjectIndex, setCurrentProjectIndex] = useState(0)
+    const [error, setError] = useState("")
 
     // Set the current project index to the last used project when projects load
     useEffect(() => {
@@ -41,17 +42,24 @@ const HomePage = () => {
         setCurrentProjectIndex((prev) => (prev < (projects?.length || 0) - 1 ? prev + 1 : prev));
     };
 
-    const handleRemoveProject = () => {
+    const handleRemoveProject = async () => {
         if (projects && projects.length > 0) {
-            const updatedProjects = [...projects];
-            updatedProjects.splice(currentProjectIndex, 1);
-            // Note: This would need to be handled by the backend to actually remove the project
-            // For now, we'll just refresh the projects list
-            refreshProjects();
+            const project = projects[currentProjectIndex];
+            // Only unregisters the project, its folder is left alone
+            if (!window.confirm(`Remove ${project.Name} from the project list? Its files are kept.`)) {
+                return;
+            }
+            try {
+                await RemoveProject(project.ID);
+                setError("");
+            } catch (error) {
+                setError(`Could not remove ${project.Name}: ${error}`);
+            }
+            await refreshProjects();
             
             // Adjust currentProjectIndex if necessary
-            if (currentProjectIndex >= updatedProjects.length) {
-                setCurrentProjectIndex(Math.max(0, updatedProjects.length - 1));
+            if (currentProjectIndex >= projects.length - 1) {
+                setCurrentProjectIndex(Math.max(0, projects.length - 2));
             }
         }
     };
@@ -125,7 +133,13 @@ const HomePage = () => {
 
                         <button 
                             onClick={async () => {
-                                await ImportProject();
+                                try {
+                                    // Importing a registered folder selects it instead
+                                    await ImportProject();
+                                    setError("");
+                                } catch (error) {
+                                    setError(`Could not import the project: ${error}`);
+                                }
                                 // Refresh the projects list
                                 await refreshProjects();
                             }} 
@@ -138,6 +152,7 @@ const HomePage = () => {
                         </button>
                     </div>
 
+                    {error && <p className="text-red-400">{error}</p>}
                     {clickedNewProject && <NewProject setClickedNewProject={setClickedNewProject}/>}
                 </div>
             </div>
@@ -185,7 +185,7 @@ const Jukebox = () => {
                         controls 
                         autoPlay 
                         loop 
-                        src={song ? `data:audio/ogg;base64,${song.Path}` : ''} 
+                        src={song?.Path} 
                         controlsList="nodownload"
                         className="hidden"
                     />
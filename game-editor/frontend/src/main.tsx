@@ -14,7 +14,16 @@ import Homepage from "./homepage/main"
 import OverworldEditor from "./overworld-editor/main"
 import NewOverworlds from "./overworld-editor/new-overworlds/NewOverworlds"
 import MapEditor from "./map-editor"
+import Validator from "./validator/main"
+import Search from "./search/main"
+import EngineUpgrade from "./engine-upgrade/main"
+import Export from "./export/main"
+import DecompImport from "./decomp-import/main"
+import Snapshots from "./snapshots/main"
+import TypeEditor from "./type-editor/main";
 import { ProjectProvider } from "./contexts/ProjectContext";
+import RecoveryPrompt from "./recovery/RecoveryPrompt";
+import DataChangedNotice from "./project-watcher/DataChangedNotice";
 
 const container = document.getElementById('root') as HTMLElement;
 const root = createRoot(container);
@@ -23,6 +32,8 @@ root.render(
         <ProjectProvider>
             <HashRouter>
                 <Navbar /> 
+                <RecoveryPrompt />
+                <DataChangedNotice />
                 <Routes>
                      <Route path="/" element={<Homepage />} />
                      <Route path="/trainer-editor" element={<TrainerEditor />} />
@@ -34,6 +45,13 @@ root.render(
                      <Route path="/overworld-editor" element={<OverworldEditor/>}/>
                      <Route path="/overworld-editor/new-overworld" element={<NewOverworlds/>}/>
                      <Route path="/map-editor" element={<MapEditor/>}/>
+                     <Route path="/validator" element={<Validator/>}/>
+                     <Route path="/search" element={<Search/>}/>
+                     <Route path="/engine-upgrade" element={<EngineUpgrade/>}/>
+                     <Route path="/export" element={<Export/>}/>
+                     <Route path="/decomp-import" element={<DecompImport/>}/>
+                     <Route path="/snapshots" element={<Snapshots/>}/>
+                     <Route path="/type-editor" element={<TypeEditor/>}/>
                  </Routes>
             </HashRouter>
         </ProjectProvider>
@@ -17,7 +17,7 @@ const PokemonSprite = ({ pokemonId, pokemonName, timeOfDay }: PokemonSpriteProps
             setIsLoading(true);
             try {
                 const pokemon = await LoadPokemonById(pokemonId);
-                const spriteUrl = `data:image/gif;base64,${pokemon.Icon}`;
+                const spriteUrl = pokemon.Icon;
                 setSpriteUrl(spriteUrl);
                 setPokemonData(pokemon);
             } catch (error) {
@@ -33,7 +33,7 @@ const PokemonSprite = ({ pokemonId, pokemonName, timeOfDay }: PokemonSpriteProps
 
     const playCry = () => {
         if (pokemonData?.Cry) {
-            const audio = new Audio(`data:audio/wav;base64,${pokemonData.Cry}`);
+            const audio = new Audio(pokemonData.Cry);
             audio.play().catch(error => {
                 console.error('Error playing cry:', error);
             });
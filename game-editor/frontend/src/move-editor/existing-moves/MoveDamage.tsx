@@ -0,0 +1,94 @@
+import { useEffect, useState } from "react";
+import { ParsePokemonData } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/parsing/ParsingApp";
+import { CalculateDamage, GetWeathers } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/battle-simulator/BattleSimulatorApp";
+
+type DamageResult = Awaited<ReturnType<typeof CalculateDamage>>;
+
+const inputClass = "w-full px-3 py-2 bg-slate-700 text-white rounded-lg border border-slate-600 focus:border-tealBlue focus:ring-1 focus:ring-tealBlue focus:outline-none transition-colors";
+
+// MoveDamage shows what the selected move does to a species, e.g. whether the
+// rival's starter 2HKOs the player's, with the move as last saved
+const MoveDamage = ({ moveName }: { moveName: string }) => {
+  const [species, setSpecies] = useState<{ Name: string; ID: string; }[]>([]);
+  const [weathers, setWeathers] = useState<string[]>([]);
+  const [attacker, setAttacker] = useState({ id: "", level: 5, stage: 0 });
+  const [defender, setDefender] = useState({ id: "", level: 5, stage: 0 });
+  const [weather, setWeather] = useState("");
+  const [critical, setCritical] = useState(false);
+  const [result, setResult] = useState<DamageResult | null>(null);
+  const [message, setMessage] = useState("");
+
+  useEffect(() => {
+    ParsePokemonData().then((data) => setSpecies(data.map((pokemon) => ({ Name: pokemon.Name, ID: pokemon.ID }))));
+    GetWeathers().then(setWeathers);
+  }, []);
+
+  useEffect(() => {
+    if (!attacker.id || !defender.id) {
+      setResult(null);
+      return;
+    }
+    CalculateDamage({
+      attacker: { ...attacker, ivs: null, evs: null, nature: "", stats: null },
+      defender: { ...defender, ivs: null, evs: null, nature: "", stats: null },
+      move: moveName,
+      weather,
+      critical,
+    })
+      .then((damage) => {
+        setResult(damage);
+        setMessage("");
+      })
+      .catch((error) => {
+        setResult(null);
+        setMessage(`${error}`);
+      });
+  }, [moveName, attacker, defender, weather, critical]);
+
+  const side = (label: string, value: typeof attacker, setValue: (value: typeof attacker) => void) => (
+    <div className="space-y-2">
+      <label className="block text-sm font-medium text-gray-300">{label}</label>
+      <select value={value.id} onChange={(e) => setValue({ ...value, id: e.target.value })} className={inputClass}>
+        <option value="">Select a species...</option>
+        {species.map((pokemon) => (
+          <option key={pokemon.ID} value={pokemon.ID}>{pokemon.Name}</option>
+        ))}
+      </select>
+      <div className="grid grid-cols-2 gap-2">
+        <input type="number" min={1} max={100} value={value.level} onChange={(e) => setValue({ ...value, level: parseInt(e.target.value) || 0 })} className={inputClass} title="Level" />
+        <input type="number" min={-6} max={6} value={value.stage} onChange={(e) => setValue({ ...value, stage: parseInt(e.target.value) || 0 })} className={inputClass} title="Stat stage" />
+      </div>
+    </div>
+  );
+
+  return (
+    <div className="bg-slate-800 rounded-xl p-6 shadow-lg mt-6 space-y-4">
+      <h2 className="text-lg font-semibold text-white">Damage Calculator</h2>
+      <div className="grid grid-cols-2 gap-6">
+        {side("Attacker (level, stat stage)", attacker, setAttacker)}
+        {side("Defender (level, stat stage)", defender, setDefender)}
+      </div>
+      <div className="flex items-center gap-6">
+        <select value={weather} onChange={(e) => setWeather(e.target.value)} className={inputClass}>
+          <option value="">Clear weather</option>
+          {weathers.map((name) => (
+            <option key={name} value={name}>{name}</option>
+          ))}
+        </select>
+        <label className="flex items-center gap-2 text-sm text-gray-300 whitespace-nowrap">
+          <input type="checkbox" checked={critical} onChange={(e) => setCritical(e.target.checked)} />
+          Critical hit
+        </label>
+      </div>
+      {result && (
+        <p className="text-white text-sm">
+          {result.attacker}'s {result.move} deals {result.min}-{result.max} damage to {result.defender} ({result.minPercent.toFixed(1)}-{result.maxPercent.toFixed(1)}% of {result.defenderHp} HP)
+          {result.ko ? `, ${result.ko}` : ", it has no effect"}{result.accuracy < 100 ? ` at ${result.accuracy}% accuracy` : ""}
+        </p>
+      )}
+      {message && <p className="text-gray-400 text-sm">{message}</p>}
+    </div>
+  );
+};
+
+export default MoveDamage;
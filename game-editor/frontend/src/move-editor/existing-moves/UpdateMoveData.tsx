@@ -1,5 +1,6 @@
 import { UpdateMove } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/move-editor/MoveEditorApp";
-import { Dispatch, SetStateAction } from "react";
+import { GetTypes } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/type-editor/TypeEditorApp";
+import { Dispatch, SetStateAction, useEffect, useState } from "react";
 import { Move } from "../move.model";
 
 const UpdateMoveData = ({
@@ -27,6 +28,12 @@ const UpdateMoveData = ({
   name: string | undefined;
   setName: Dispatch<SetStateAction<string | undefined>>;
 }) => {
+  const [moveTypes, setMoveTypes] = useState<string[]>([]);
+
+  useEffect(() => {
+    GetTypes().then((definitions) => setMoveTypes(definitions.map((definition) => definition.name)));
+  }, []);
+
   return (
     <div className="bg-slate-800 rounded-xl p-6 shadow-lg">
       <div className="grid grid-cols-2 gap-6">
@@ -44,12 +51,17 @@ const UpdateMoveData = ({
         {/* Move Type Section */}
         <div className="col-span-2">
           <label className="block text-sm font-medium text-gray-300 mb-2">Move Type</label>
-          <input
+          <select
             value={moveType ? moveType : ""}
             onChange={(e) => setMoveType(e.target.value)}
             className="w-full px-3 py-2 bg-slate-700 text-white rounded-lg border border-slate-600 focus:border-tealBlue focus:ring-1 focus:ring-tealBlue focus:outline-none transition-colors"
-            placeholder="Enter move type..."
-          />
+          >
+            <option value="">Select a type...</option>
+            {moveType && !moveTypes.includes(moveType) && <option value={moveType}>{moveType} (unknown type)</option>}
+            {moveTypes.map((type) => (
+              <option key={type} value={type}>{type}</option>
+            ))}
+          </select>
         </div>
 
         {/* Stats Section */}
@@ -2,6 +2,7 @@ import { useState, useEffect } from "react";
 import { ParseMoves } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/move-editor/MoveEditorApp";
 import Select from "react-select";
 import UpdateMoveData from "./existing-moves/UpdateMoveData";
+import MoveDamage from "./existing-moves/MoveDamage";
 import { Move } from "./move.model";
 import { useNavigate } from "react-router-dom";
 
@@ -156,6 +157,7 @@ const MoveEditor = () => {
                 name={name}
                 setName={setName}
               />
+              <MoveDamage moveName={selectedMove.Name} />
             </div>
           ) : (
             <div className="text-gray-400 text-center mt-8">
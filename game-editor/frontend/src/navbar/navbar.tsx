@@ -7,10 +7,17 @@ const navigation = [
   { name: 'Trainer Editor', href: '/trainer-editor' },
   { name: 'Pokemon Editor', href: '/pokemon-editor' },
   { name: 'Move Editor', href: '/move-editor' },
+  { name: 'Type Editor', href: '/type-editor' },
   { name: 'Script Editor', href: '/script-editor' },
   { name: 'Overworld Editor', href: '/overworld-editor' },
   { name: 'Map Editor', href: '/map-editor' },
   { name: 'Jukebox', href: '/jukebox' },
+  { name: 'Validator', href: '/validator' },
+  { name: 'Search', href: '/search' },
+  { name: 'Snapshots', href: '/snapshots' },
+  { name: 'Engine Upgrade', href: '/engine-upgrade' },
+  { name: 'Export', href: '/export' },
+  { name: 'Decomp Import', href: '/decomp-import' },
 ];
 
 export default function Navbar() {
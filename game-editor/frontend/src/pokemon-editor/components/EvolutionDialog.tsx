@@ -125,7 +125,6 @@ const EvolutionDialog = ({
             const updatedPokemon = await LoadPokemonById(selectedPokemon.ID);
             const pokemon: Pokemon = {
                 ...updatedPokemon,
-                DexEntry: "",
                 Evolutions: updatedPokemon.Evolutions.map(evo => ({
                     ...evo,
                     Method1: evo.Method1 ? [evo.Method1] : [],
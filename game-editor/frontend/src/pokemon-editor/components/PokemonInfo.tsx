@@ -4,12 +4,13 @@ import TypeToggle from "./TypeToggle";
 interface PokemonInfoProps {
     selectedPokemon: Pokemon | undefined;
     onTypeChange: (types: string[]) => void;
+    onInfoChange: (field: "Name" | "DexEntry", value: string) => void;
 }
 
-const PokemonInfo = ({ selectedPokemon, onTypeChange }: PokemonInfoProps) => {
+const PokemonInfo = ({ selectedPokemon, onTypeChange, onInfoChange }: PokemonInfoProps) => {
     const playCry = () => {
         if (selectedPokemon?.Cry) {
-            const audio = new Audio(`data:audio/wav;base64,${selectedPokemon.Cry}`);
+            const audio = new Audio(selectedPokemon.Cry);
             audio.play();
         }
     };
@@ -20,7 +21,7 @@ const PokemonInfo = ({ selectedPokemon, onTypeChange }: PokemonInfoProps) => {
                 <div className="flex flex-row gap-2">
                     {selectedPokemon?.Icon && (
                         <img 
-                            src={`data:image/gif;base64,${selectedPokemon.Icon}`} 
+                            src={selectedPokemon.Icon} 
                             alt={`${selectedPokemon.Name} Icon`}
                             className="w-12 h-12 bg-slate-600 rounded-l-xl p-1"
                         />
@@ -51,6 +52,19 @@ const PokemonInfo = ({ selectedPokemon, onTypeChange }: PokemonInfoProps) => {
                         </div>
                     </div>
                 </div>
+                <input
+                    value={selectedPokemon?.Name || ""}
+                    onChange={(e) => onInfoChange("Name", e.target.value)}
+                    placeholder="Species name"
+                    className="mt-2 px-3 py-2 bg-slate-600 rounded-xl text-white focus:outline-none"
+                />
+                <textarea
+                    value={selectedPokemon?.DexEntry || ""}
+                    onChange={(e) => onInfoChange("DexEntry", e.target.value)}
+                    placeholder="Dex entry"
+                    rows={2}
+                    className="mt-2 px-3 py-2 bg-slate-600 rounded-xl text-white resize-none focus:outline-none"
+                />
             </div>
         </div>
     );
@@ -1,4 +1,3 @@
-import { useState } from "react";
 import Select from "react-select";
 import { Pokemon } from "../pokemon.model";
 import { LoadPokemonById } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/parsing/ParsingApp";
@@ -7,10 +6,11 @@ interface PokemonSelectorProps {
     pokemonSpecies: Pokemon[];
     selectedPokemon: Pokemon | undefined;
     onPokemonSelect: (pokemon: Pokemon) => void;
+    onNewPokemon: () => void;
 }
 
-const PokemonSelector = ({ pokemonSpecies, selectedPokemon, onPokemonSelect }: PokemonSelectorProps) => {
-    const [selectValue, setSelectValue] = useState<{ value: string; label: string } | null>(null);
+const PokemonSelector = ({ pokemonSpecies, selectedPokemon, onPokemonSelect, onNewPokemon }: PokemonSelectorProps) => {
+    const selectedSpecies = pokemonSpecies.find(pokemon => pokemon.ID === selectedPokemon?.ID);
 
     const handlePokemonChange = async (e: any) => {
         const selected: Pokemon | undefined = pokemonSpecies.find(pokemon => pokemon.ID === (e?.value));
@@ -18,14 +18,12 @@ const PokemonSelector = ({ pokemonSpecies, selectedPokemon, onPokemonSelect }: P
             const trainerEditor = await LoadPokemonById(selected.ID);
             const pokemon: Pokemon = {
                 ...trainerEditor,
-                DexEntry: "",
                 Evolutions: trainerEditor.Evolutions ? trainerEditor.Evolutions.map(evo => ({
                     ...evo,
                     Method1: evo.Method1 ? [evo.Method1] : [],
                     Method2: evo.Method2 ? [evo.Method2] : []
                 })) : []
             };
-            setSelectValue({ value: pokemon.ID, label: `${pokemon.ID}: ${pokemon.Name}` });
             onPokemonSelect(pokemon);
         }
     };
@@ -34,7 +32,7 @@ const PokemonSelector = ({ pokemonSpecies, selectedPokemon, onPokemonSelect }: P
         <div className="flex flex-row justify-around gap-4 py-2">
             <Select
                 options={pokemonSpecies.map(pokemon => ({ value: pokemon.ID, label: `${pokemon.ID}: ${pokemon.Name}`}))}
-                value={selectValue}
+                value={selectedSpecies ? { value: selectedSpecies.ID, label: `${selectedSpecies.ID}: ${selectedSpecies.Name}` } : null}
                 onChange={handlePokemonChange}
                 isClearable={false}
                 isDisabled={false}
@@ -71,7 +69,7 @@ const PokemonSelector = ({ pokemonSpecies, selectedPokemon, onPokemonSelect }: P
                     })
                 }}
             />
-            <button onClick={() => console.log("TO BE ADDED")} 
+            <button onClick={onNewPokemon} 
                     className="bg-slate-600 rounded-xl px-4 hover:bg-slate-500 transition-colors">
                 New Pokemon
             </button>
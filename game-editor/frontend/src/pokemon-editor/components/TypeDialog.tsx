@@ -1,6 +1,8 @@
+import { useEffect, useState } from "react";
 import { Dialog } from "@headlessui/react";
 import Select from "react-select";
 import { Pokemon } from "../pokemon.model";
+import { GetTypes } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/type-editor/TypeEditorApp";
 
 interface TypeDialogProps {
     isOpen: boolean;
@@ -10,7 +12,11 @@ interface TypeDialogProps {
 }
 
 const TypeDialog = ({ isOpen, onClose, selectedPokemon, onTypeChange }: TypeDialogProps) => {
-    const pokemonTypes: string[] = ["Bug", "Dark", "Dragon", "Electric", "Fairy", "Fighting", "Fire", "Flying", "Ghost", "Grass", "Ground", "Ice", "Normal", "Poison", "Psychic", "Rock", "Steel", "Water"];
+    const [pokemonTypes, setPokemonTypes] = useState<string[]>([]);
+
+    useEffect(() => {
+        GetTypes().then((definitions) => setPokemonTypes(definitions.map((definition) => definition.name)));
+    }, []);
 
     return (
         <Dialog
@@ -1,33 +1,18 @@
-import { useState } from "react";
+import { useEffect, useState } from "react";
+import { GetTypes } from "../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/type-editor/TypeEditorApp";
 
 interface TypeToggleProps {
     types: string[];
     onTypeChange: (types: string[]) => void;
 }
 
-const typeColors: { [key: string]: string } = {
-    Bug: "bg-green-500",
-    Dark: "bg-gray-800",
-    Dragon: "bg-purple-600",
-    Electric: "bg-yellow-400",
-    Fairy: "bg-pink-400",
-    Fighting: "bg-red-600",
-    Fire: "bg-orange-500",
-    Flying: "bg-indigo-400",
-    Ghost: "bg-purple-400",
-    Grass: "bg-green-400",
-    Ground: "bg-yellow-600",
-    Ice: "bg-blue-200",
-    Normal: "bg-gray-400",
-    Poison: "bg-purple-500",
-    Psychic: "bg-pink-500",
-    Rock: "bg-yellow-700",
-    Steel: "bg-gray-500",
-    Water: "bg-blue-500"
-};
-
 const TypeToggle = ({ types, onTypeChange }: TypeToggleProps) => {
     const [currentIndex, setCurrentIndex] = useState(0);
+    const [typeColors, setTypeColors] = useState<{ [key: string]: string }>({});
+
+    useEffect(() => {
+        GetTypes().then((definitions) => setTypeColors(Object.fromEntries(definitions.map((definition) => [definition.name, definition.color]))));
+    }, []);
 
     const handlePrevType = () => {
         const newIndex = (currentIndex - 1 + types.length) % types.length;
@@ -59,7 +44,7 @@ const TypeToggle = ({ types, onTypeChange }: TypeToggleProps) => {
                     ←
                 </button>
             )}
-            <span className={`px-4 py-1 text-white rounded ${typeColors[types[currentIndex]] || typeColors.Normal}`}>
+            <span className="px-4 py-1 text-white rounded bg-gray-400" style={{ backgroundColor: typeColors[types[currentIndex]] }}>
                 {types[currentIndex] || "Normal"}
             </span>
             {showNavigation && (
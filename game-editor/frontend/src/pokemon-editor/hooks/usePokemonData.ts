@@ -45,7 +45,6 @@ export const usePokemonData = () => {
                     if(trainerEditor.Evolutions != null && trainerEditor.Evolutions.length > 0) {
                     pokemon = {
                         ...trainerEditor,
-                        DexEntry: "",
                         Evolutions: trainerEditor.Evolutions.map(evo => ({
                             ...evo,
                             Method1: evo.Method1 ? [evo.Method1] : [],
@@ -55,7 +54,6 @@ export const usePokemonData = () => {
                     } else {
                         pokemon = {
                             ...trainerEditor,
-                            DexEntry: "",
                             Evolutions: []
                         };
                     }
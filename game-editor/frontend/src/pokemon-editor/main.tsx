@@ -11,11 +11,11 @@ import AbilitiesSection from "./components/AbilitiesSection";
 import StatsSection from "./components/StatsSection";
 import EvolutionDialog from "./components/EvolutionDialog";
 import TypeDialog from "./components/TypeDialog";
-import { DeletePokemonEvolution } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor/PokemonEditorApp";
+import { ClonePokemon, CreatePokemon, DeletePokemon, DeletePokemonEvolution, GetPokemonReferences, UpdatePokemon } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor/PokemonEditorApp";
 import { LoadPokemonById } from "../../bindings/github.com/zenith110/pokemon-engine-tools/parsing/ParsingApp";
 
 export default function PokemonEditor(): React.ReactElement {
-    const { pokemonSpecies, selectedPokemon, isLoading, updatePokemonSelection } = usePokemonData();
+    const { pokemonSpecies, selectedPokemon, isLoading, updatePokemonSelection, fetchPokemonSpecies } = usePokemonData();
     const [currentAbilityIndex, setCurrentAbilityIndex] = useState<number>(0);
     const [isTypeModalOpen, setIsTypeModalOpen] = useState<boolean>(false);
     
@@ -71,6 +71,87 @@ export default function PokemonEditor(): React.ReactElement {
         }
     };
 
+    // Name and dex entry change handler
+    const handleInfoChange = (field: "Name" | "DexEntry", value: string) => {
+        if (selectedPokemon) {
+            updatePokemonSelection({ ...selectedPokemon, [field]: value });
+        }
+    };
+
+    // The species fields pokemon.toml stores, evolutions and assets are saved on their own
+    const speciesData = (pokemon: Pokemon) => ({
+        name: pokemon.Name,
+        types: pokemon.Types,
+        dexEntry: pokemon.DexEntry,
+        hp: pokemon.HP,
+        attack: pokemon.Attack,
+        defense: pokemon.Defense,
+        specialAttack: pokemon.SpecialAttack,
+        specialDefense: pokemon.SpecialDefense,
+        speed: pokemon.Speed,
+        abilities: pokemon.Abilities,
+        moves: pokemon.Moves,
+    });
+
+    // Reloads the species list and selects id, the hook selects the last selected species
+    const showSpecies = async (id: string) => {
+        localStorage.setItem('lastSelectedPokemonId', id);
+        await fetchPokemonSpecies();
+    };
+
+    const handleSave = async () => {
+        if (!selectedPokemon) return;
+        try {
+            await UpdatePokemon(selectedPokemon.ID, speciesData(selectedPokemon));
+            await showSpecies(selectedPokemon.ID);
+        } catch (error) {
+            alert(`Failed to save ${selectedPokemon.Name}: ${error}`);
+        }
+    };
+
+    const handleReset = async () => {
+        if (selectedPokemon) {
+            await showSpecies(selectedPokemon.ID);
+        }
+    };
+
+    // New species start from the selected one's types and base stats
+    const handleNewPokemon = async () => {
+        if (!selectedPokemon) return;
+        const name = window.prompt("New species name");
+        if (!name) return;
+        try {
+            const id = await CreatePokemon({ ...speciesData(selectedPokemon), name, dexEntry: "", abilities: [], moves: [] });
+            await showSpecies(id);
+        } catch (error) {
+            alert(`Failed to create ${name}: ${error}`);
+        }
+    };
+
+    const handleClonePokemon = async () => {
+        if (!selectedPokemon) return;
+        try {
+            await showSpecies(await ClonePokemon(selectedPokemon.ID));
+        } catch (error) {
+            alert(`Failed to clone ${selectedPokemon.Name}: ${error}`);
+        }
+    };
+
+    const handleDeletePokemon = async () => {
+        if (!selectedPokemon) return;
+        try {
+            const references = await GetPokemonReferences(selectedPokemon.ID);
+            const question = references.length > 0
+                ? `${selectedPokemon.Name} is still used by ${references.join(", ")}. Delete it and remove it from those as well?`
+                : `Are you sure you want to delete ${selectedPokemon.Name}?`;
+            if (!confirm(question)) return;
+            await DeletePokemon(selectedPokemon.ID, references.length > 0);
+            await showSpecies(pokemonSpecies.find(pokemon => pokemon.ID !== selectedPokemon.ID)?.ID ?? "");
+        } catch (error) {
+            alert(`Failed to delete ${selectedPokemon.Name}: ${error}`);
+        }
+    };
+
     // Evolution update handler
     const handleEvolutionUpdate = (evolutions: Pokemon['Evolutions']) => {
         if (selectedPokemon) {
@@ -125,7 +206,6 @@ export default function PokemonEditor(): React.ReactElement {
                     if(updatedPokemon.Evolutions != null && updatedPokemon.Evolutions.length > 0) {
                     pokemon = {
                         ...updatedPokemon,
-                        DexEntry: "",
                         Evolutions: updatedPokemon.Evolutions.map(evo => ({
                             ...evo,
                             Method1: evo.Method1 ? [evo.Method1] : [],
@@ -135,7 +215,6 @@ export default function PokemonEditor(): React.ReactElement {
                 } else {
                     pokemon = {
                         ...updatedPokemon,
-                        DexEntry: "",
                         Evolutions: []
                     };
                 }
@@ -171,10 +250,12 @@ export default function PokemonEditor(): React.ReactElement {
                         pokemonSpecies={pokemonSpecies}
                         selectedPokemon={selectedPokemon}
                         onPokemonSelect={updatePokemonSelection}
+                        onNewPokemon={handleNewPokemon}
                     />
                     <PokemonInfo 
                         selectedPokemon={selectedPokemon}
                         onTypeChange={handleTypeChange}
+                        onInfoChange={handleInfoChange}
                     />
                 </div>
                 
@@ -241,7 +322,7 @@ export default function PokemonEditor(): React.ReactElement {
                                                             title="Click to edit this evolution"
                                                         >
                                                             <img 
-                                                                src={`data:image/gif;base64,${selectedPokemon.Evolutions[currentEvoIndex]?.Icon || ''}`} 
+                                                                src={selectedPokemon.Evolutions[currentEvoIndex]?.Icon || ''} 
                                                                 alt={`${selectedPokemon.Evolutions[currentEvoIndex]?.Name || 'Evolution'}`}
                                                                 className="w-12 h-12"
                                                             />
@@ -267,7 +348,6 @@ export default function PokemonEditor(): React.ReactElement {
                                                                         if(evo.Evolutions != null && evo.Evolutions.length > 0) {
                                                                         pokemon = {
                                                                             ...evo,
-                                                                            DexEntry: "",
                                                                             Evolutions: evo.Evolutions.map(evo => ({
                                                                                 ...evo,
                                                                                 Method1: evo.Method1 ? [evo.Method1] : [],
@@ -277,7 +357,6 @@ export default function PokemonEditor(): React.ReactElement {
                                                                     } else {
                                                                         pokemon = {
                                                                             ...evo,
-                                                                            DexEntry: "",
                                                                             Evolutions: []
                                                                         };
                                                                     }
@@ -352,14 +431,24 @@ export default function PokemonEditor(): React.ReactElement {
                     onStatChange={handleStatChange}
                 />
                 <div className="flex flex-row gap-4">
-                    <button onClick={() => console.log("Add Later")} 
+                    <button onClick={handleSave} 
                             className="px-12 py-2 bg-slate-600 rounded-xl hover:bg-slate-500 transition-colors">
                         Save
                     </button>
-                    <button onClick={() => console.log("Add Later")} 
+                    <button onClick={handleReset} 
                             className="px-12 py-2 bg-slate-600 rounded-xl hover:bg-slate-500 transition-colors">
                         Reset
                     </button> 
+                    <div className="flex flex-col gap-2">
+                        <button onClick={handleClonePokemon}
+                                className="px-6 py-1 bg-slate-600 rounded-xl hover:bg-slate-500 transition-colors">
+                            Clone
+                        </button>
+                        <button onClick={handleDeletePokemon}
+                                className="px-6 py-1 bg-red-600 rounded-xl hover:bg-red-700 transition-colors">
+                            Delete
+                        </button>
+                    </div>
                 </div>
             </div>
             
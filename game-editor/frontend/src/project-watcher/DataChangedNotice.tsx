@@ -0,0 +1,37 @@
+import { useState } from "react";
+import { Button } from "../components/ui/button";
+import { ProjectDataChange, useProjectDataChanged } from "./useProjectDataChanged";
+
+// Tells the user which project files changed on disk while the editor was open
+const DataChangedNotice = () => {
+    const [changes, setChanges] = useState<ProjectDataChange[]>([]);
+
+    useProjectDataChanged(change => {
+        setChanges(current => [...current.filter(existing => existing.file !== change.file), change]);
+    });
+
+    if (changes.length === 0) {
+        return null;
+    }
+
+    return (
+        <div className="fixed bottom-4 right-4 z-50 max-w-md rounded-xl bg-slate-900 border border-amber-500 p-4 text-white shadow-lg">
+            <p className="font-medium text-amber-400">Project files changed on disk</p>
+            <ul className="mt-2 max-h-32 overflow-y-auto text-sm text-slate-300">
+                {changes.map(change => (
+                    <li key={change.file}>
+                        {change.file}
+                        {change.removed ? " (removed)" : change.records && change.records.length > 0 ? ` (${change.records.join(", ")})` : ""}
+                    </li>
+                ))}
+            </ul>
+            <p className="mt-2 text-sm text-slate-400">Unsaved edits to these records will overwrite the changes when saved.</p>
+            <div className="mt-3 flex gap-2">
+                <Button onClick={() => window.location.reload()} className="bg-tealBlue hover:bg-wildBlueYonder">Reload</Button>
+                <Button variant="outline" onClick={() => setChanges([])} className="bg-slate-800 border-slate-700 hover:bg-slate-700">Dismiss</Button>
+            </div>
+        </div>
+    );
+};
+
+export default DataChangedNotice;
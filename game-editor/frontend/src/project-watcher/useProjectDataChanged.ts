@@ -0,0 +1,26 @@
+import { useEffect, useRef } from "react";
+import { Events } from "@wailsio/runtime";
+
+export interface ProjectDataChange {
+    file: string;
+    records: string[] | null;
+    removed: boolean;
+}
+
+// Calls onChange whenever a project file is changed outside of the editor,
+// optionally only for files whose path starts with one of the given prefixes
+export const useProjectDataChanged = (onChange: (change: ProjectDataChange) => void, prefixes: string[] = []) => {
+    const onChangeRef = useRef(onChange);
+    onChangeRef.current = onChange;
+    const prefixKey = prefixes.join("|");
+
+    useEffect(() => {
+        const unsubscribe = Events.On("project-data-changed", (ev) => {
+            const change = ev.data as ProjectDataChange;
+            if (prefixes.length === 0 || prefixes.some(prefix => change.file.startsWith(prefix))) {
+                onChangeRef.current(change);
+            }
+        });
+        return () => unsubscribe();
+    }, [prefixKey]);
+};
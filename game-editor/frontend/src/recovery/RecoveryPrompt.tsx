@@ -0,0 +1,60 @@
+import { useState, useEffect } from "react"
+import { Dialog, DialogContent, DialogDescription, DialogHeader, DialogTitle } from "../components/ui/dialog"
+import { Button } from "../components/ui/button"
+import { PendingRecoveries, ResolveRecovery } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App"
+import { repository } from "../../bindings/github.com/zenith110/pokemon-engine-tools/repository"
+
+// Asks what to do with saves that were interrupted before they replaced their file
+const RecoveryPrompt = () => {
+    const [pending, setPending] = useState<repository.PendingWrite[]>([])
+    const [error, setError] = useState("")
+
+    useEffect(() => {
+        PendingRecoveries()
+            .then(writes => setPending(writes ?? []))
+            .catch(error => console.error('Error checking for interrupted saves:', error))
+    }, [])
+
+    const resolve = async (write: repository.PendingWrite, restore: boolean) => {
+        try {
+            await ResolveRecovery(write.tempPath, restore)
+            setPending(current => current.filter(candidate => candidate.tempPath !== write.tempPath))
+            setError("")
+        } catch (error) {
+            setError(`Could not resolve ${write.path}: ${error}`)
+        }
+    }
+
+    return (
+        <Dialog open={pending.length > 0}>
+            <DialogContent className="sm:max-w-[600px] bg-slate-900 text-white">
+                <DialogHeader>
+                    <DialogTitle>Recover unsaved changes</DialogTitle>
+                    <DialogDescription className="text-slate-300">
+                        The editor closed while saving these files. Restore keeps the newer unsaved version, discard keeps the file as it is on disk.
+                    </DialogDescription>
+                </DialogHeader>
+                <div className="space-y-3">
+                    {pending.map(write => (
+                        <div key={write.tempPath} className="flex items-center justify-between gap-4 rounded-lg bg-slate-800 p-3">
+                            <div className="min-w-0">
+                                <p className="truncate font-medium">{write.path}</p>
+                                <p className="text-sm text-slate-400">
+                                    {write.size} bytes, {new Date(write.modified).toLocaleString()}
+                                    {write.hasBackup && ", previous version kept as .bak"}
+                                </p>
+                            </div>
+                            <div className="flex gap-2">
+                                <Button onClick={() => resolve(write, true)} className="bg-tealBlue hover:bg-wildBlueYonder">Restore</Button>
+                                <Button variant="outline" onClick={() => resolve(write, false)} className="bg-slate-800 border-slate-700 hover:bg-slate-700">Discard</Button>
+                            </div>
+                        </div>
+                    ))}
+                </div>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+            </DialogContent>
+        </Dialog>
+    )
+}
+
+export default RecoveryPrompt
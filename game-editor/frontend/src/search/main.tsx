@@ -0,0 +1,88 @@
+import { useState } from "react"
+import { Button } from "../components/ui/button"
+import { Search as SearchProject, FindUsages } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/search/SearchApp"
+import { Hit } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/search/models"
+
+// Record types FindUsages understands
+const usageTypes = ["moves", "helditems", "pokemon", "songs", "abilities", "trainerclasses", "types"]
+
+// Searches every record of the project and answers "where is this used?"
+const Search = () => {
+    const [query, setQuery] = useState("")
+    const [hits, setHits] = useState<Hit[] | null>(null)
+    const [usagesOf, setUsagesOf] = useState<Hit | null>(null)
+    const [usages, setUsages] = useState<Hit[]>([])
+    const [error, setError] = useState("")
+
+    const search = async (e: React.FormEvent) => {
+        e.preventDefault()
+        try {
+            setHits(await SearchProject(query, 0) ?? [])
+            setUsagesOf(null)
+            setError("")
+        } catch (error) {
+            setError(`Could not search the project: ${error}`)
+        }
+    }
+
+    const findUsages = async (hit: Hit) => {
+        try {
+            setUsages(await FindUsages(hit.type, hit.id) ?? [])
+            setUsagesOf(hit)
+            setError("")
+        } catch (error) {
+            setError(`Could not find usages of ${hit.name}: ${error}`)
+        }
+    }
+
+    const renderHit = (hit: Hit, index: number, showUsages: boolean) => (
+        <div key={index} className="flex items-center justify-between gap-4 rounded-lg bg-slate-800 p-3">
+            <div className="min-w-0">
+                <div className="flex items-center gap-2 text-sm">
+                    <span className="font-semibold text-tealBlue">{hit.type}</span>
+                    <span className="text-slate-400">#{hit.id}</span>
+                    <span className="text-slate-400">{hit.field}</span>
+                </div>
+                <p className="truncate font-medium">{hit.name}</p>
+                <p className="truncate text-sm text-slate-300">{hit.snippet}</p>
+            </div>
+            {showUsages && usageTypes.includes(hit.type) && (
+                <Button variant="outline" onClick={() => findUsages(hit)} className="bg-slate-800 border-slate-700 hover:bg-slate-700">
+                    Find usages
+                </Button>
+            )}
+        </div>
+    )
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <form onSubmit={search} className="flex gap-2">
+                    <input
+                        value={query}
+                        onChange={e => setQuery(e.target.value)}
+                        placeholder="Search species, moves, items, trainers, maps, songs..."
+                        className="flex-1 rounded-lg bg-slate-800 border border-slate-700 px-3 py-2 text-white"
+                    />
+                    <Button type="submit" className="bg-tealBlue hover:bg-wildBlueYonder">Search</Button>
+                </form>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                {usagesOf && (
+                    <div className="space-y-2">
+                        <h2 className="text-xl font-bold">Used by {usagesOf.name} ({usages.length})</h2>
+                        {usages.length === 0 && <p className="text-slate-400">Nothing refers to {usagesOf.name}.</p>}
+                        {usages.map((hit, index) => renderHit(hit, index, false))}
+                    </div>
+                )}
+                {hits && (
+                    <div className="space-y-2">
+                        <h2 className="text-xl font-bold">Results ({hits.length})</h2>
+                        {hits.map((hit, index) => renderHit(hit, index, true))}
+                    </div>
+                )}
+            </div>
+        </div>
+    )
+}
+
+export default Search
@@ -0,0 +1,136 @@
+import { useEffect, useState } from "react"
+import { Button } from "../components/ui/button"
+import { CommitSnapshot, DiffSnapshots, RestoreFile, RestoreRecord, Snapshots as ListSnapshots } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/App"
+import { Snapshot, SnapshotChange } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools-core/models"
+
+// Data files whose records can be restored one at a time, by index type
+const recordTypes: Record<string, string> = {
+    "data/toml/pokemon.toml": "pokemon",
+    "data/toml/moves.toml": "moves",
+    "data/toml/trainers.toml": "trainers",
+    "data/toml/trainerclasses.toml": "trainerclasses",
+    "data/toml/types.toml": "types",
+    "data/toml/helditems.toml": "helditems",
+    "data/toml/maps.toml": "maps",
+    "data/toml/tilesets.toml": "tilesets",
+    "data/assets/toml/overworlds.toml": "overworlds",
+}
+
+// Lists the project's git snapshots, takes new ones and shows what changed
+// since a snapshot so single records or files can be restored from it
+const Snapshots = () => {
+    const [snapshots, setSnapshots] = useState<Snapshot[]>([])
+    const [message, setMessage] = useState("")
+    const [selected, setSelected] = useState<Snapshot | null>(null)
+    const [changes, setChanges] = useState<SnapshotChange[]>([])
+    const [status, setStatus] = useState("")
+    const [error, setError] = useState("")
+
+    const load = async () => {
+        try {
+            setSnapshots(await ListSnapshots())
+            setError("")
+        } catch (error) {
+            setError(`Could not read the project history: ${error}`)
+        }
+    }
+
+    useEffect(() => {
+        load()
+    }, [])
+
+    const select = async (snapshot: Snapshot) => {
+        setSelected(snapshot)
+        try {
+            setChanges(await DiffSnapshots(snapshot.hash, ""))
+            setError("")
+        } catch (error) {
+            setError(`Could not compare with ${snapshot.hash.slice(0, 7)}: ${error}`)
+        }
+    }
+
+    const commit = async () => {
+        try {
+            const snapshot = await CommitSnapshot(message)
+            setStatus(`Saved snapshot ${snapshot.hash.slice(0, 7)}`)
+            setMessage("")
+            setError("")
+            await load()
+        } catch (error) {
+            setError(`Could not save a snapshot: ${error}`)
+        }
+    }
+
+    const restore = async (change: SnapshotChange) => {
+        if (!selected) {
+            return
+        }
+        const entityType = recordTypes[change.file]
+        try {
+            if (change.record && entityType) {
+                await RestoreRecord(selected.hash, entityType, change.record.split("/").slice(1).join("/"))
+            } else {
+                await RestoreFile(selected.hash, change.file)
+            }
+            setStatus(`Restored ${change.name || change.file}`)
+            await select(selected)
+        } catch (error) {
+            setError(`Could not restore ${change.name || change.file}: ${error}`)
+        }
+    }
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <h1 className="text-2xl font-bold">Snapshots</h1>
+                <div className="flex gap-2">
+                    <input
+                        value={message}
+                        onChange={(e) => setMessage(e.target.value)}
+                        placeholder="Describe what changed"
+                        className="flex-1 rounded bg-slate-800 px-3 py-2"
+                    />
+                    <Button onClick={commit} disabled={!message.trim()} className="bg-tealBlue hover:bg-wildBlueYonder">
+                        Take snapshot
+                    </Button>
+                </div>
+                {status && <p className="text-sm text-green-400">{status}</p>}
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                <div className="grid grid-cols-3 gap-4">
+                    <div className="space-y-2">
+                        {snapshots.map((snapshot) => (
+                            <button
+                                key={snapshot.hash}
+                                onClick={() => select(snapshot)}
+                                className={`w-full rounded-lg p-3 text-left ${selected?.hash === snapshot.hash ? "bg-tealBlue" : "bg-slate-800 hover:bg-slate-700"}`}
+                            >
+                                <p className="truncate">{snapshot.message}</p>
+                                <p className="text-xs text-slate-400">
+                                    {snapshot.hash.slice(0, 7)} · {new Date(snapshot.time).toLocaleString()}
+                                </p>
+                            </button>
+                        ))}
+                    </div>
+                    <div className="col-span-2 space-y-2">
+                        {selected && changes.length === 0 && (
+                            <p className="rounded-lg bg-slate-800 p-4 text-slate-400">Nothing changed since this snapshot.</p>
+                        )}
+                        {changes.map((change) => (
+                            <div key={`${change.file}#${change.record}`} className="flex items-center justify-between rounded-lg bg-slate-800 p-3">
+                                <div>
+                                    <p>{change.summary}</p>
+                                    <p className="text-xs text-slate-400">{change.file}{change.record && ` #${change.record}`}</p>
+                                </div>
+                                <Button onClick={() => restore(change)} className="bg-slate-700 hover:bg-slate-600">
+                                    Restore
+                                </Button>
+                            </div>
+                        ))}
+                    </div>
+                </div>
+            </div>
+        </div>
+    )
+}
+
+export default Snapshots
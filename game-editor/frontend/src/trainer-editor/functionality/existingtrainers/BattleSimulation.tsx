@@ -0,0 +1,95 @@
+import { useState } from "react";
+import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
+import { SimulateBattles } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/battle-simulator/BattleSimulatorApp";
+
+interface BattleSimulationProps {
+    selectedTrainer: models.TrainerJson;
+    trainers: models.TrainerJson[];
+}
+
+const BattleSimulation = ({ selectedTrainer, trainers }: BattleSimulationProps) => {
+    const [playerTrainerId, setPlayerTrainerId] = useState("");
+    const [variant, setVariant] = useState("");
+    const [battles, setBattles] = useState(100);
+    const [report, setReport] = useState<Awaited<ReturnType<typeof SimulateBattles>> | null>(null);
+    const [message, setMessage] = useState("");
+
+    return (
+        <div className="bg-slate-800 rounded-xl p-4 shadow-inner space-y-3 mt-6">
+            <h2 className="text-white text-lg font-semibold text-center">Balance Test</h2>
+            <p className="text-gray-300 text-sm text-center">Battles another trainer's party, e.g. a reference player team, against this trainer</p>
+            <div className="grid grid-cols-3 gap-3">
+                <select
+                    value={playerTrainerId}
+                    onChange={(e) => setPlayerTrainerId(e.target.value)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                >
+                    <option value="">Player party from...</option>
+                    {trainers.filter((trainer) => trainer.id !== selectedTrainer.id).map((trainer) => (
+                        <option key={trainer.id} value={trainer.id}>{trainer.name}</option>
+                    ))}
+                </select>
+                <select
+                    value={variant}
+                    onChange={(e) => setVariant(e.target.value)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                >
+                    <option value="">Base party</option>
+                    {(selectedTrainer.variants ?? []).map((partyVariant) => (
+                        <option key={partyVariant.name} value={partyVariant.name}>{partyVariant.name}</option>
+                    ))}
+                </select>
+                <input
+                    type="number"
+                    min={1}
+                    max={10000}
+                    value={battles}
+                    onChange={(e) => setBattles(parseInt(e.target.value) || 0)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                />
+            </div>
+            <div className="flex justify-center">
+                <button
+                    disabled={!playerTrainerId}
+                    className="px-4 py-2 bg-tealBlue text-white rounded-xl hover:bg-wildBlueYonder transition-colors duration-200 disabled:opacity-50"
+                    onClick={async () => {
+                        try {
+                            setReport(await SimulateBattles({ trainerId: selectedTrainer.id, playerTrainerId, variant, battles, party: [], seed: 0 }))
+                            setMessage("")
+                        } catch (error) {
+                            setReport(null)
+                            setMessage(`Simulation failed: ${error}`)
+                        }
+                    }}
+                >
+                    Simulate
+                </button>
+            </div>
+            {report && (
+                <div className="text-white text-sm space-y-2">
+                    <p className="text-center">
+                        Player wins {(report.winRate * 100).toFixed(1)}% of {report.battles} battles ({report.playerWins} won, {report.trainerWins} lost, {report.draws} drawn), {report.averageTurns.toFixed(1)} turns on average
+                    </p>
+                    <div className="grid grid-cols-2 gap-3">
+                        {[{ title: "Player", party: report.player }, { title: selectedTrainer.name, party: report.opponent }].map(({ title, party }) => (
+                            <div key={title}>
+                                <h3 className="font-semibold">{title}</h3>
+                                {party.map((pokemon) => (
+                                    <p key={pokemon.slot} className="text-gray-300">
+                                        {pokemon.species} Lv {pokemon.level}: {pokemon.kos} KOs, fainted {pokemon.fainted} times
+                                    </p>
+                                ))}
+                            </div>
+                        ))}
+                    </div>
+                    {report.warnings.map((warning, index) => (
+                        <p key={index} className="text-yellow-300">{warning}</p>
+                    ))}
+                </div>
+            )}
+            {message && <p className="text-gray-300 text-sm text-center">{message}</p>}
+        </div>
+    )
+}
+
+export default BattleSimulation
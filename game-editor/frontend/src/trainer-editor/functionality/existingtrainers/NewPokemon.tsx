@@ -80,12 +80,12 @@ const NewPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer, heldI
                             ) : selectedPokemon ? (
                                 <>
                                     <img 
-                                        src={`data:image/png;base64,${selectedPokemon.FrontSprite}`} 
+                                        src={selectedPokemon.FrontSprite} 
                                         alt="Front Sprite" 
                                         className="w-32 h-32 object-contain mb-4"
                                     />
                                     <img 
-                                        src={`data:image/gif;base64,${selectedPokemon.Icon}`} 
+                                        src={selectedPokemon.Icon} 
                                         alt="Icon" 
                                         className="w-16 h-16 object-contain mb-4"
                                     />
@@ -261,7 +261,11 @@ const NewPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer, heldI
                                             id: selectedPokemon.ID,
                                             level: level,
                                             heldItem: heldItem,
-                                            cry: selectedPokemon.Cry
+                                            cry: selectedPokemon.Cry,
+                                            // Saving derives the stats from perfect IVs and no EVs
+                                            ivs: { hp: 31, attack: 31, defense: 31, speed: 31, specialAttack: 31, specialDefense: 31 },
+                                            evs: { hp: 0, attack: 0, defense: 0, speed: 0, specialAttack: 0, specialDefense: 0 },
+                                            nature: ""
                                         }
                                         selectedTrainer.pokemons.push(newPokemon)
                                         setSelectedTrainer(selectedTrainer)
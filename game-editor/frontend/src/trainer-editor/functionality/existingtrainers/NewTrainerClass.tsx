@@ -1,10 +1,11 @@
 import { useState } from "react";
 import Modal from 'react-modal';
 import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
+import { CreateTrainerClass } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
 
 interface NewTrainerClassProps {
-    classTypes: models.Data[];
-    setClassTypes: (classTypes: models.Data[]) => void;
+    classTypes: models.TrainerClass[];
+    setClassTypes: (classTypes: models.TrainerClass[]) => void;
     isOpen: boolean;
     onRequestClose: () => void;
 }
@@ -33,6 +34,10 @@ const customStyles = {
 
 const NewTrainerClass = ({ classTypes, setClassTypes, isOpen, onRequestClose }: NewTrainerClassProps) => {
     const [className, setClassName] = useState("");
+    const [prizeMultiplier, setPrizeMultiplier] = useState(0);
+    const [battleMusic, setBattleMusic] = useState("");
+    const [encounterMusic, setEncounterMusic] = useState("");
+    const [error, setError] = useState("");
 
     return (
         <Modal
@@ -58,6 +63,42 @@ const NewTrainerClass = ({ classTypes, setClassTypes, isOpen, onRequestClose }:
                             />
                         </div>
 
+                        {/* Class Details */}
+                        <div className="grid grid-cols-2 gap-4 mb-6">
+                            <div>
+                                <label className="block text-sm font-medium text-gray-300 mb-2">Prize money multiplier</label>
+                                <input
+                                    type="number"
+                                    min={0}
+                                    max={255}
+                                    value={prizeMultiplier}
+                                    onChange={(e) => setPrizeMultiplier(Number(e.target.value))}
+                                    className="w-full px-3 py-2 rounded-lg bg-slate-800 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                                />
+                            </div>
+                            <div>
+                                <label className="block text-sm font-medium text-gray-300 mb-2">Battle music</label>
+                                <input
+                                    type="text"
+                                    value={battleMusic}
+                                    onChange={(e) => setBattleMusic(e.target.value)}
+                                    className="w-full px-3 py-2 rounded-lg bg-slate-800 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                                    placeholder="e.g. hiker.ogg"
+                                />
+                            </div>
+                            <div>
+                                <label className="block text-sm font-medium text-gray-300 mb-2">Encounter music</label>
+                                <input
+                                    type="text"
+                                    value={encounterMusic}
+                                    onChange={(e) => setEncounterMusic(e.target.value)}
+                                    className="w-full px-3 py-2 rounded-lg bg-slate-800 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                                    placeholder="e.g. hiker_encounter.ogg"
+                                />
+                            </div>
+                        </div>
+                        {error && <p className="text-red-400 text-sm mb-4">{error}</p>}
+
                         {/* Buttons */}
                         <div className="flex justify-end space-x-4">
                             <button 
@@ -67,14 +108,25 @@ const NewTrainerClass = ({ classTypes, setClassTypes, isOpen, onRequestClose }:
                                 Cancel
                             </button>
                             <button 
-                                onClick={() => {
+                                onClick={async () => {
                                     if (!className.trim()) return;
 
-                                    const newClass: models.Data = {
+                                    const newClass: models.TrainerClass = {
                                         Name: className.trim(),
-                                        Music: ""
+                                        Music: battleMusic,
+                                        PrizeMultiplier: prizeMultiplier,
+                                        Sprite: "",
+                                        EncounterMusic: encounterMusic,
+                                        AI: null
                                     };
 
+                                    try {
+                                        await CreateTrainerClass(newClass);
+                                    } catch (error) {
+                                        setError(`${error}`);
+                                        return;
+                                    }
+                                    setError("");
                                     setClassTypes([...classTypes, newClass]);
                                     onRequestClose();
                                 }}
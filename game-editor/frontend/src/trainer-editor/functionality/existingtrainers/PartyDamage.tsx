@@ -0,0 +1,101 @@
+import { useEffect, useState } from "react";
+import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
+import { CalculateDamage, GetWeathers } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/battle-simulator/BattleSimulatorApp";
+
+interface PartyDamageProps {
+    selectedTrainer: models.TrainerJson;
+    pokemonSpecies: { Name: string; ID: string; }[];
+}
+
+type MoveDamage = Awaited<ReturnType<typeof CalculateDamage>>;
+
+const PartyDamage = ({ selectedTrainer, pokemonSpecies }: PartyDamageProps) => {
+    const [defenderId, setDefenderId] = useState("");
+    const [defenderLevel, setDefenderLevel] = useState(5);
+    const [weather, setWeather] = useState("");
+    const [weathers, setWeathers] = useState<string[]>([]);
+    const [damage, setDamage] = useState<{ slot: number; result: MoveDamage }[]>([]);
+
+    useEffect(() => {
+        GetWeathers().then(setWeathers)
+    }, [])
+
+    useEffect(() => {
+        if (!defenderId || defenderLevel < 1 || defenderLevel > 100) {
+            setDamage([])
+            return
+        }
+        const calculate = async () => {
+            const results: { slot: number; result: MoveDamage }[] = []
+            for (const [slot, pokemon] of selectedTrainer.pokemons.entries()) {
+                for (const move of pokemon.moves.filter((move) => move !== "")) {
+                    try {
+                        const result = await CalculateDamage({
+                            attacker: {
+                                id: pokemon.id,
+                                level: pokemon.level,
+                                ivs: pokemon.ivs,
+                                evs: pokemon.evs,
+                                nature: pokemon.nature,
+                                stats: { hp: pokemon.hp, attack: pokemon.attack, defense: pokemon.defense, speed: pokemon.speed, specialAttack: pokemon.specialAttack, specialDefense: pokemon.specialDefense },
+                                stage: 0,
+                            },
+                            defender: { id: defenderId, level: defenderLevel, ivs: null, evs: null, nature: "", stats: null, stage: 0 },
+                            move,
+                            weather,
+                            critical: false,
+                        })
+                        results.push({ slot, result })
+                    } catch {
+                        // status moves and unknown moves deal no damage to show
+                    }
+                }
+            }
+            setDamage(results)
+        }
+        calculate()
+    }, [selectedTrainer, defenderId, defenderLevel, weather])
+
+    return (
+        <div className="bg-slate-800 rounded-xl p-4 shadow-inner space-y-3 mt-6">
+            <h2 className="text-white text-lg font-semibold text-center">Party Damage</h2>
+            <div className="grid grid-cols-3 gap-3">
+                <select
+                    value={defenderId}
+                    onChange={(e) => setDefenderId(e.target.value)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                >
+                    <option value="">Against...</option>
+                    {pokemonSpecies.map((species) => (
+                        <option key={species.ID} value={species.ID}>{species.Name}</option>
+                    ))}
+                </select>
+                <input
+                    type="number"
+                    min={1}
+                    max={100}
+                    value={defenderLevel}
+                    onChange={(e) => setDefenderLevel(parseInt(e.target.value) || 0)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                />
+                <select
+                    value={weather}
+                    onChange={(e) => setWeather(e.target.value)}
+                    className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                >
+                    <option value="">Clear weather</option>
+                    {weathers.map((name) => (
+                        <option key={name} value={name}>{name}</option>
+                    ))}
+                </select>
+            </div>
+            {damage.map(({ slot, result }) => (
+                <p key={`${slot}-${result.move}`} className="text-gray-300 text-sm">
+                    {result.attacker}'s {result.move}: {result.min}-{result.max} damage ({result.minPercent.toFixed(1)}-{result.maxPercent.toFixed(1)}%){result.ko ? `, ${result.ko}` : ", no effect"}
+                </p>
+            ))}
+        </div>
+    )
+}
+
+export default PartyDamage
@@ -1,8 +1,9 @@
 import { useState } from "react"
-import { UpdateTrainer, UpdateTrainerSprite } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
+import { UpdateTrainer, UpdateTrainerSprite, ExportTrainerShowdown, ImportTrainerShowdown, GeneratePartyVariant, DeletePartyVariant } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
 import UpdatingPokemon from "./UpdatingPokemon";
 import NewPokemon from "./NewPokemon";
 import NewTrainerClass from "./NewTrainerClass";
+import TrainerAI from "./TrainerAI";
 import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
 
 interface TrainerProps {
@@ -10,8 +11,8 @@ interface TrainerProps {
     heldItems: { Name: string }[];
     pokemonSpecies: { Name: string; ID: string; }[];
     setSelectedTrainer: (trainer: models.TrainerJson) => void;
-    classTypes: models.Data[];
-    setClassTypes: (classTypes: models.Data[]) => void;
+    classTypes: models.TrainerClass[];
+    setClassTypes: (classTypes: models.TrainerClass[]) => void;
 }
 
 const Trainer = ({ selectedTrainer, heldItems, pokemonSpecies, setSelectedTrainer, classTypes, setClassTypes}: TrainerProps) => {
@@ -20,6 +21,13 @@ const Trainer = ({ selectedTrainer, heldItems, pokemonSpecies, setSelectedTraine
     const [trainerSprite, setTrainerSprite] = useState(selectedTrainer?.spritename)
     const [isNewPokemonModalOpen, setIsNewPokemonModalOpen] = useState(false);
     const [isNewTrainerClassModalOpen, setIsNewTrainerClassModalOpen] = useState(false);
+    const [showdownPaste, setShowdownPaste] = useState("");
+    const [showdownMessage, setShowdownMessage] = useState("");
+    const [variantName, setVariantName] = useState("rematch 1");
+    const [levelOffset, setLevelOffset] = useState(10);
+    const [evolve, setEvolve] = useState(true);
+    const [upgradeMoves, setUpgradeMoves] = useState(true);
+    const [variantChanges, setVariantChanges] = useState<string[]>([]);
     
     return(
         <div className="bg-slate-700 rounded-xl p-6 space-y-6">
@@ -33,7 +41,7 @@ const Trainer = ({ selectedTrainer, heldItems, pokemonSpecies, setSelectedTraine
                     className="hover:opacity-80 transition-opacity"
                 >
                     <img 
-                        src={selectedTrainer? `data:image/png;base64,${selectedTrainer?.sprite}` : ''} 
+                        src={selectedTrainer?.sprite} 
                         alt="Trainer Sprite" 
                         className="w-24 h-24 object-contain"
                     />
@@ -108,6 +116,130 @@ const Trainer = ({ selectedTrainer, heldItems, pokemonSpecies, setSelectedTraine
                 </div>
             </div>
 
+            {/* Showdown Section */}
+            <div className="bg-slate-800 rounded-xl p-4 shadow-inner space-y-3">
+                <h2 className="text-white text-lg font-semibold text-center">Showdown</h2>
+                <textarea
+                    value={showdownPaste}
+                    onChange={(e) => setShowdownPaste(e.target.value)}
+                    placeholder="Paste a Showdown team here..."
+                    rows={8}
+                    className="w-full px-3 py-2 rounded-lg bg-slate-700 text-white font-mono text-sm border border-slate-600 focus:border-slate-500 focus:outline-none"
+                />
+                <div className="flex justify-center space-x-4">
+                    <button
+                        className="px-4 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                        onClick={async () => {
+                            try {
+                                const paste = await ExportTrainerShowdown(selectedTrainer.id)
+                                setShowdownPaste(paste)
+                                await navigator.clipboard.writeText(paste)
+                                setShowdownMessage("Copied the team to the clipboard")
+                            } catch (error) {
+                                setShowdownMessage(`Export failed: ${error}`)
+                            }
+                        }}
+                    >
+                        Export Team
+                    </button>
+                    <button
+                        className="px-4 py-2 bg-tealBlue text-white rounded-xl hover:bg-wildBlueYonder transition-colors duration-200"
+                        onClick={async () => {
+                            try {
+                                const result = await ImportTrainerShowdown(selectedTrainer.id, showdownPaste)
+                                if (!result.applied) {
+                                    const problems = [
+                                        ...(result.unknown ?? []).map((name) => `unknown ${name.kind} ${name.name} (line ${name.line})`),
+                                        ...(result.invalid ?? []).map((value) => `${value.reason} (line ${value.line})`),
+                                    ]
+                                    setShowdownMessage(`Not imported: ${problems.join(", ")}`)
+                                    return
+                                }
+                                setSelectedTrainer({ ...selectedTrainer, pokemons: result.pokemons } as models.TrainerJson)
+                                setShowdownMessage(`Imported ${result.pokemons.length} pokemon`)
+                            } catch (error) {
+                                setShowdownMessage(`Import failed: ${error}`)
+                            }
+                        }}
+                    >
+                        Import Team
+                    </button>
+                </div>
+                {showdownMessage && <p className="text-gray-300 text-sm text-center">{showdownMessage}</p>}
+            </div>
+
+            {/* Party Variants Section */}
+            <div className="bg-slate-800 rounded-xl p-4 shadow-inner space-y-3">
+                <h2 className="text-white text-lg font-semibold text-center">Party Variants</h2>
+                {(selectedTrainer.variants ?? []).map((variant) => (
+                    <div key={variant.name} className="flex items-center justify-between bg-slate-700 rounded-lg px-3 py-2">
+                        <span className="text-white">
+                            {variant.name}: {variant.pokemons.map((pokemon) => `${pokemon.species} Lv ${pokemon.level}`).join(", ")}
+                        </span>
+                        <button
+                            className="px-3 py-1 bg-red-600 text-white rounded-lg hover:bg-red-500 transition-colors duration-200"
+                            onClick={async () => {
+                                await DeletePartyVariant(selectedTrainer.id, variant.name)
+                                setSelectedTrainer({ ...selectedTrainer, variants: selectedTrainer.variants.filter((other) => other.name !== variant.name) } as models.TrainerJson)
+                            }}
+                        >
+                            Delete
+                        </button>
+                    </div>
+                ))}
+                <div className="grid grid-cols-2 gap-3">
+                    <input
+                        type="text"
+                        value={variantName}
+                        onChange={(e) => setVariantName(e.target.value)}
+                        placeholder="Variant name, e.g. rematch 1 or hard"
+                        className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                    />
+                    <input
+                        type="number"
+                        value={levelOffset}
+                        onChange={(e) => setLevelOffset(parseInt(e.target.value) || 0)}
+                        className="px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                    />
+                    <label className="text-white flex items-center space-x-2">
+                        <input type="checkbox" checked={evolve} onChange={(e) => setEvolve(e.target.checked)} />
+                        <span>Evolve past evolution levels</span>
+                    </label>
+                    <label className="text-white flex items-center space-x-2">
+                        <input type="checkbox" checked={upgradeMoves} onChange={(e) => setUpgradeMoves(e.target.checked)} />
+                        <span>Learn new level-up moves</span>
+                    </label>
+                </div>
+                <div className="flex justify-center">
+                    <button
+                        className="px-4 py-2 bg-tealBlue text-white rounded-xl hover:bg-wildBlueYonder transition-colors duration-200"
+                        onClick={async () => {
+                            try {
+                                const result = await GeneratePartyVariant(selectedTrainer.id, { name: variantName, levelOffset, evolve, upgradeMoves, save: true })
+                                const variants = (selectedTrainer.variants ?? []).filter((variant) => variant.name !== result.variant.name)
+                                setSelectedTrainer({ ...selectedTrainer, variants: [...variants, result.variant] } as models.TrainerJson)
+                                setVariantChanges(result.changes.length > 0 ? result.changes : ["The variant is the same as the base party"])
+                            } catch (error) {
+                                setVariantChanges([`Generating failed: ${error}`])
+                            }
+                        }}
+                    >
+                        Generate Variant
+                    </button>
+                </div>
+                {variantChanges.map((change, index) => (
+                    <p key={index} className="text-gray-300 text-sm">{change}</p>
+                ))}
+            </div>
+
+            {/* Battle AI Section */}
+            <TrainerAI
+                key={selectedTrainer.id}
+                selectedTrainer={selectedTrainer}
+                setSelectedTrainer={setSelectedTrainer}
+                heldItems={heldItems}
+            />
+
             {/* Save Button */}
             <div className="flex justify-center">
                 <button 
@@ -120,6 +252,8 @@ const Trainer = ({ selectedTrainer, heldItems, pokemonSpecies, setSelectedTraine
                             "classType": trainerClass,
                             "id": selectedTrainer.id,
                             "pokemons": selectedTrainer.pokemons,
+                            "variants": selectedTrainer.variants ?? [],
+                            "ai": selectedTrainer.ai ?? null,
                             "convertValues": () => {}
                         }
                         UpdateTrainer(updatedTrainer)
@@ -0,0 +1,121 @@
+import { useState, useEffect } from "react";
+import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
+import { GetAIFlags, GetSwitchModes, GetTrainerAI, UpdateTrainerAI } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
+
+interface TrainerAIProps {
+    selectedTrainer: models.TrainerJson;
+    setSelectedTrainer: (trainer: models.TrainerJson) => void;
+    heldItems: { Name: string }[];
+}
+
+const maxItems = 4;
+
+const TrainerAI = ({ selectedTrainer, setSelectedTrainer, heldItems }: TrainerAIProps) => {
+    const [aiFlags, setAIFlags] = useState<{ name: string; description: string }[]>([]);
+    const [switchModes, setSwitchModes] = useState<string[]>([]);
+    const [flags, setFlags] = useState<string[]>(selectedTrainer.ai?.flags ?? []);
+    const [switching, setSwitching] = useState(selectedTrainer.ai?.switching ?? "");
+    const [items, setItems] = useState<models.AIItem[]>(selectedTrainer.ai?.items ?? []);
+    const [resolved, setResolved] = useState<models.TrainerAI | null>(null);
+    const [message, setMessage] = useState("");
+
+    useEffect(() => {
+        GetAIFlags().then((data) => setAIFlags(data ?? []))
+        GetSwitchModes().then((data) => setSwitchModes(data ?? []))
+    }, [])
+
+    useEffect(() => {
+        GetTrainerAI(selectedTrainer.id).then(setResolved).catch(() => setResolved(null))
+    }, [selectedTrainer.id])
+
+    const toggleFlag = (flag: string) => {
+        setFlags(flags.includes(flag) ? flags.filter((other) => other !== flag) : [...flags, flag])
+    }
+
+    const updateItem = (index: number, item: Partial<models.AIItem>) => {
+        setItems(items.map((other, otherIndex) => otherIndex === index ? { ...other, ...item } as models.AIItem : other))
+    }
+
+    return (
+        <div className="bg-slate-800 rounded-xl p-4 shadow-inner space-y-3">
+            <h2 className="text-white text-lg font-semibold text-center">Battle AI</h2>
+            <p className="text-gray-300 text-sm text-center">Adds to the AI of the trainer's class</p>
+            <div className="grid grid-cols-2 gap-2">
+                {aiFlags.map((flag) => (
+                    <label key={flag.name} className="text-white flex items-center space-x-2" title={flag.description}>
+                        <input type="checkbox" checked={flags.includes(flag.name)} onChange={() => toggleFlag(flag.name)} />
+                        <span>{flag.name}</span>
+                    </label>
+                ))}
+            </div>
+            <select
+                value={switching}
+                onChange={(e) => setSwitching(e.target.value)}
+                className="w-full px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+            >
+                <option value="">Switching of the class</option>
+                {switchModes.map((mode) => <option key={mode} value={mode}>{mode}</option>)}
+            </select>
+            {items.map((item, index) => (
+                <div key={index} className="flex items-center space-x-2">
+                    <select
+                        value={item.name}
+                        onChange={(e) => updateItem(index, { name: e.target.value })}
+                        className="flex-1 px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                    >
+                        <option value="">Select an item</option>
+                        {heldItems.map((heldItem) => <option key={heldItem.Name} value={heldItem.Name}>{heldItem.Name}</option>)}
+                    </select>
+                    <input
+                        type="number"
+                        min={1}
+                        value={item.count}
+                        onChange={(e) => updateItem(index, { count: parseInt(e.target.value) || 0 })}
+                        className="w-20 px-3 py-2 rounded-lg bg-slate-700 text-white border border-slate-600 focus:border-slate-500 focus:outline-none"
+                    />
+                    <button
+                        className="px-3 py-1 bg-red-600 text-white rounded-lg hover:bg-red-500 transition-colors duration-200"
+                        onClick={() => setItems(items.filter((_, otherIndex) => otherIndex !== index))}
+                    >
+                        Remove
+                    </button>
+                </div>
+            ))}
+            <div className="flex justify-center space-x-4">
+                {items.length < maxItems && (
+                    <button
+                        className="px-4 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                        onClick={() => setItems([...items, { name: "", count: 1 } as models.AIItem])}
+                    >
+                        Add Item
+                    </button>
+                )}
+                <button
+                    className="px-4 py-2 bg-tealBlue text-white rounded-xl hover:bg-wildBlueYonder transition-colors duration-200"
+                    onClick={async () => {
+                        const profile = { flags, switching, items } as models.AIProfile
+                        try {
+                            await UpdateTrainerAI(selectedTrainer.id, profile)
+                            setSelectedTrainer({ ...selectedTrainer, ai: profile } as models.TrainerJson)
+                            setResolved(await GetTrainerAI(selectedTrainer.id))
+                            setMessage("Saved the AI")
+                        } catch (error) {
+                            setMessage(`${error}`)
+                        }
+                    }}
+                >
+                    Save AI
+                </button>
+            </div>
+            {resolved && (
+                <p className="text-gray-300 text-sm text-center">
+                    Battles with {resolved.flags.length > 0 ? resolved.flags.join(", ") : "no flags"}, {resolved.switching} switching
+                    {resolved.items.length > 0 && `, ${resolved.items.map((item) => `${item.count}x ${item.name}`).join(", ")}`}
+                </p>
+            )}
+            {message && <p className="text-gray-300 text-sm text-center">{message}</p>}
+        </div>
+    )
+}
+
+export default TrainerAI
@@ -1,7 +1,8 @@
-import { useState } from "react";
+import { useState, useEffect } from "react";
 import Modal from 'react-modal';
 import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
 import { LoadPokemonById } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/parsing/ParsingApp";
+import { CalculateTrainerPokemonStats, GetNatures } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
 
 interface UpdatingPokemonProps {
     selectedTrainer: models.TrainerJson;
@@ -55,6 +56,15 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
     const [species, setSpecies] = useState(pokemon?.species)
     const [modalIsOpen, setIsOpen] = useState(false);
     const [id, setId] = useState(pokemon?.id)
+    const [derived, setDerived] = useState(pokemon?.ivs != null)
+    const [ivs, setIvs] = useState<models.StatSpread>(pokemon?.ivs ?? { hp: 31, attack: 31, defense: 31, speed: 31, specialAttack: 31, specialDefense: 31 })
+    const [evs, setEvs] = useState<models.StatSpread>(pokemon?.evs ?? { hp: 0, attack: 0, defense: 0, speed: 0, specialAttack: 0, specialDefense: 0 })
+    const [nature, setNature] = useState(pokemon?.nature ?? "")
+    const [natures, setNatures] = useState<string[]>([])
+
+    useEffect(() => {
+        GetNatures().then((data) => setNatures(data ?? []))
+    }, [])
     
     const openModal = () => {
         setIsOpen(true);
@@ -88,7 +98,10 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                     id: pokemonData.ID,
                     level: level,
                     heldItem: heldItem,
-                    cry: pokemonData.Cry
+                    cry: pokemonData.Cry,
+                    ivs: pokemon?.ivs,
+                    evs: pokemon?.evs,
+                    nature: pokemon?.nature
                 });
             }
         } catch (error) {
@@ -111,7 +124,7 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                     className="w-24 h-24 bg-slate-600 rounded-xl p-2 hover:bg-slate-500 transition-colors duration-200 flex items-center justify-center shadow-inner border-2 border-slate-500 relative"
                 >
                     <img 
-                        src={pokemon? `data:image/gif;base64,${pokemon?.icon}` : ''} 
+                        src={pokemon?.icon} 
                         alt="Sprite" 
                         className="w-20 h-20 object-contain"
                     />
@@ -146,14 +159,14 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                                         <button 
                                             onClick={() => {
                                                 if (clickedPokemon) {
-                                                    var test = new Audio(`data:audio/wav;base64,${clickedPokemon.cry}`)
+                                                    var test = new Audio(clickedPokemon.cry)
                                                     test.play();
                                                 }
                                             }}
                                             className="hover:opacity-80 transition-opacity mb-4 bg-slate-700 p-4 rounded-xl"
                                         >
                                             <img 
-                                                src={clickedPokemon? `data:image/gif;base64,${clickedPokemon?.front}` : ''} 
+                                                src={clickedPokemon?.front} 
                                                 alt="Sprite" 
                                                 className="w-32 h-32 object-contain"
                                             />
@@ -206,9 +219,69 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                                 </div>
                             </div>
 
+                            {/* Spread Section */}
+                            <div className="bg-slate-800 rounded-xl p-4 mb-6">
+                                <label className="flex items-center justify-center space-x-2 mb-4">
+                                    <input
+                                        type="checkbox"
+                                        checked={derived}
+                                        onChange={(e) => setDerived(e.target.checked)}
+                                    />
+                                    <span className="text-lg font-semibold">Derive stats from IVs, EVs and nature</span>
+                                </label>
+                                {derived && (
+                                    <div className="space-y-4">
+                                        {[
+                                            { label: "IVs", spread: ivs, setter: setIvs, max: 31 },
+                                            { label: "EVs", spread: evs, setter: setEvs, max: 255 }
+                                        ].map((group) => (
+                                            <div key={group.label}>
+                                                <h4 className="text-sm mb-2 text-gray-300 text-center">{group.label}</h4>
+                                                <div className="grid grid-cols-6 gap-2">
+                                                    {[
+                                                        { label: "HP", key: "hp" },
+                                                        { label: "Atk", key: "attack" },
+                                                        { label: "Def", key: "defense" },
+                                                        { label: "SpA", key: "specialAttack" },
+                                                        { label: "SpD", key: "specialDefense" },
+                                                        { label: "Spe", key: "speed" }
+                                                    ].map((stat) => (
+                                                        <div key={stat.key}>
+                                                            <label className="block text-xs mb-1 text-gray-400 text-center">{stat.label}</label>
+                                                            <input
+                                                                type="number"
+                                                                min={0}
+                                                                max={group.max}
+                                                                value={group.spread[stat.key as keyof models.StatSpread]}
+                                                                onChange={(e) => group.setter({ ...group.spread, [stat.key]: Number(e.target.value) } as models.StatSpread)}
+                                                                className="w-full px-1 py-1 rounded-lg bg-white text-slate-800 text-center"
+                                                            />
+                                                        </div>
+                                                    ))}
+                                                </div>
+                                            </div>
+                                        ))}
+                                        <div>
+                                            <label className="block text-sm mb-1 text-gray-300">Nature</label>
+                                            <select
+                                                value={nature}
+                                                onChange={(e) => setNature(e.target.value)}
+                                                className="w-full px-2 py-1 rounded-lg bg-slate-800 text-white border border-slate-600 focus:border-slate-500 focus:outline-none [&>*]:bg-slate-800"
+                                            >
+                                                <option value={""}>Neutral</option>
+                                                {natures.map((name) =>
+                                                    <option value={name} key={name} className="hover:bg-slate-700">{name.charAt(0).toUpperCase() + name.slice(1)}</option>
+                                                )}
+                                            </select>
+                                        </div>
+                                    </div>
+                                )}
+                            </div>
+
                             {/* Stats Section */}
                             <div className="bg-slate-800 rounded-xl p-4 mb-6">
                                 <h3 className="text-lg font-semibold mb-4 text-center">Stats</h3>
+                                {derived && <p className="text-sm text-gray-400 text-center mb-4">Calculated from the species' base stats when saved</p>}
                                 <div className="grid grid-cols-2 gap-4">
                                     {[
                                         { label: "HP", value: clickedPokemon?.hp, setter: setHp },
@@ -223,6 +296,7 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                                             <input 
                                                 type="number" 
                                                 defaultValue={stat.value} 
+                                                disabled={derived}
                                                 onChange={(e) => stat.setter(Number(e.target.value))}
                                                 className="w-full px-2 py-1 rounded-lg bg-white text-slate-800 text-center"
                                             />
@@ -287,7 +361,13 @@ const UpdatingPokemon = ({ selectedTrainer, pokemonSpecies, setSelectedTrainer,
                                             id: id,
                                             level: level,
                                             heldItem: heldItem,
-                                            cry: pokemon.cry
+                                            cry: pokemon.cry,
+                                            ivs: derived ? ivs : null,
+                                            evs: derived ? evs : null,
+                                            nature: derived ? nature : ""
+                                        }
+                                        if (derived) {
+                                            updatedSelectedPokemon = await CalculateTrainerPokemonStats(updatedSelectedPokemon)
                                         }
                                         
                                         selectedTrainer.pokemons[index] = updatedSelectedPokemon
@@ -15,7 +15,7 @@ interface DictData {
 
 const NewTrainerCard = () => {
     const navigate = useNavigate();
-    const [classTypes, setClassTypes] = useState<models.Data[]>([])
+    const [classTypes, setClassTypes] = useState<models.TrainerClass[]>([])
     const [pokemonSpecies, setPokemonSpecies] = useState<models.PokemonTrainerEditor[]>([])
     const [pokemonNames, setPokemonNames] = useState<{ ID: string; Name: string }[]>([])
     const [pokemonCount, setPokemonCount] = useState<number>(0)
@@ -1,7 +1,7 @@
 import { models } from "../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
 
 interface TrainerClassesProps {
-    trainerClasses: models.Data[];
+    trainerClasses: models.TrainerClass[];
     dictData: { name: string; classType: string; pokemons: any[] };
     setDictData: (data: { name: string; classType: string; pokemons: any[] }) => void;
 }
@@ -11,7 +11,7 @@ const TrainerClasses = ({ trainerClasses, dictData, setDictData }: TrainerClasse
         <>
         <select name="trainerClasses" onChange={(e) => setDictData({...dictData, classType: e.target.value})} defaultValue={"placeholder"}>
             <option value={"placeholder"} disabled>Select a trainer class</option>
-            {trainerClasses.map((trainerClass: models.Data) =>
+            {trainerClasses.map((trainerClass: models.TrainerClass) =>
                 <option value={trainerClass.Name} key={trainerClass.Name}>{trainerClass.Name}</option>
             )}
         </select>
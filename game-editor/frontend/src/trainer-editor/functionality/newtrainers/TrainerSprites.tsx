@@ -25,7 +25,7 @@ const TrainerSprites = ({ trainerSprites, dictData, setDictData }: TrainerSprite
             </div>
             <br/>
             <div className="flex items-center justify-center">
-                <img src={trainerImage? `data:image/png;base64,${trainerImage}` : ''}/>
+                <img src={trainerImage}/>
             </div>
             <br/>
             </div>
@@ -1,6 +1,5 @@
 import { useState} from "react"
 import { CreateTrainerData } from "../../../../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp"
-import { v4 as uuidv4 } from 'uuid';
 import { useNavigate } from "react-router-dom";
 import { models } from "../../../../../bindings/github.com/zenith110/pokemon-engine-tools/models";
 
@@ -75,8 +74,11 @@ const PokemonStats = ({ currentlySelectedPokemon, heldItemsList, setPokemonIndex
             "sprite": dictData.sprite || "",
             "spritename": dictData.sprite || "",
             "classType": dictData.classType,
-            "id": uuidv4(),
+            // The editor assigns the trainer's ID
+            "id": "",
             "pokemons": dictData.pokemons,
+            "variants": [],
+            "ai": null,
             "convertValues": () => {}
         }
         
@@ -87,7 +89,7 @@ const PokemonStats = ({ currentlySelectedPokemon, heldItemsList, setPokemonIndex
         <div>
         <br/>
         <div className="flex items-center justify-center">
-            <img src={currentlySelectedPokemon? `data:image/png;base64,${currentlySelectedPokemon?.FrontSprite}` : ''} alt="pokemon sprite"/>
+            <img src={currentlySelectedPokemon?.FrontSprite} alt="pokemon sprite"/>
         </div>
             <br />
             <div className="text-left">
@@ -4,17 +4,44 @@ import Select from "react-select";
 import { models } from "../../bindings/github.com/zenith110/pokemon-engine-tools/models";
 
 import { ParseTrainers, ParseTrainerClass, ParsePokemonData, ParseHeldItems } from "../../bindings/github.com/zenith110/pokemon-engine-tools/parsing/ParsingApp";
+import { RecomputeTrainerStats, DeleteTrainer, DuplicateTrainer, ReorderTrainers } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/trainer-editor/TrainerEditorApp";
 import Trainer from "./functionality/existingtrainers/Trainer";
+import BattleSimulation from "./functionality/existingtrainers/BattleSimulation";
+import PartyDamage from "./functionality/existingtrainers/PartyDamage";
 
 export default function TrainerEditor():React.ReactElement {
     const [trainers, setTrainers] = useState<models.TrainerJson[] | null>([]);
     const [selectedTrainer, setSelectedTrainer] = useState<models.TrainerJson | null>(null);
     const [selectValue, setSelectValue] = useState<{ value: string; label: string } | null>(null);
     const [isLoading, setIsLoading] = useState<boolean>(true);
-    const [classTypes, setClassTypes] = useState<models.Data[]>([]);
+    const [classTypes, setClassTypes] = useState<models.TrainerClass[]>([]);
     const [heldItems, setHeldItems] = useState<models.HeldItem[]>([]);
     const [pokemonSpecies, setPokemonSpecies] = useState<{ Name: string; ID: string; }[]>([]);
+    const [recomputeMessage, setRecomputeMessage] = useState<string>("");
     const navigate = useNavigate();
+
+    const reloadTrainers = async (selectId: string | null) => {
+        const data = await ParseTrainers()
+        setTrainers(data)
+        const selected = data?.find((trainer) => trainer.id === selectId) ?? null
+        setSelectedTrainer(selected)
+        setSelectValue(selected ? { value: selected.id, label: selected.name } : null)
+    }
+
+    const moveTrainer = async (offset: number) => {
+        if (!trainers || !selectedTrainer) {
+            return
+        }
+        const ids = trainers.map((trainer) => trainer.id)
+        const from = ids.indexOf(selectedTrainer.id)
+        const to = from + offset
+        if (to < 0 || to >= ids.length) {
+            return
+        }
+        [ids[from], ids[to]] = [ids[to], ids[from]]
+        await ReorderTrainers(ids)
+        await reloadTrainers(selectedTrainer.id)
+    }
     
     useEffect(() => {
         const fetchTrainerData = async () => {
@@ -107,9 +134,11 @@ export default function TrainerEditor():React.ReactElement {
                                 value: trainer.id, 
                                 label: `${trainer.name}`
                             }))} 
+                            value={selectValue}
                             onChange={(e) => {
                                 const trainerData = trainers.find((trainer) => trainer.id === e?.value);
                                 setSelectedTrainer(trainerData);
+                                setSelectValue(e);
                             }}
                             isClearable={false}
                             isDisabled={false}
@@ -149,8 +178,47 @@ export default function TrainerEditor():React.ReactElement {
                         />
                     </div>
 
+                    {selectedTrainer && (
+                        <div className="flex flex-row justify-center space-x-2">
+                            <button
+                                onClick={() => moveTrainer(-1)}
+                                className="px-4 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                            >
+                                Move Up
+                            </button>
+                            <button
+                                onClick={() => moveTrainer(1)}
+                                className="px-4 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                            >
+                                Move Down
+                            </button>
+                            <button
+                                onClick={async () => {
+                                    const id = await DuplicateTrainer(selectedTrainer.id)
+                                    await reloadTrainers(id)
+                                }}
+                                className="px-4 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                            >
+                                Duplicate
+                            </button>
+                            <button
+                                onClick={async () => {
+                                    if (!window.confirm(`Delete ${selectedTrainer.name}?`)) {
+                                        return
+                                    }
+                                    await DeleteTrainer(selectedTrainer.id)
+                                    localStorage.removeItem('lastSelectedTrainerId')
+                                    await reloadTrainers(null)
+                                }}
+                                className="px-4 py-2 bg-red-600 text-white rounded-xl hover:bg-red-500 transition-colors duration-200"
+                            >
+                                Delete
+                            </button>
+                        </div>
+                    )}
+
                     {selectedTrainer ? (
-                        <div className="w-full mt-6">
+                        <div className="w-full mt-6" key={selectedTrainer.id}>
                             <Trainer 
                                 selectedTrainer={selectedTrainer} 
                                 heldItems={heldItems} 
@@ -159,6 +227,14 @@ export default function TrainerEditor():React.ReactElement {
                                 classTypes={classTypes}
                                 setClassTypes={setClassTypes}
                             />
+                            <PartyDamage
+                                selectedTrainer={selectedTrainer}
+                                pokemonSpecies={pokemonSpecies}
+                            />
+                            <BattleSimulation
+                                selectedTrainer={selectedTrainer}
+                                trainers={trainers ?? []}
+                            />
                         </div>
                     ) : (
                         <div className="text-gray-400 text-center mt-8">
@@ -176,6 +252,24 @@ export default function TrainerEditor():React.ReactElement {
                         </svg>
                         <span>New Trainer</span>
                     </button>
+
+                    <button
+                        onClick={async () => {
+                            try {
+                                const result = await RecomputeTrainerStats()
+                                const missing = result.missing?.length ? `, species missing for ${result.missing.join(", ")}` : ""
+                                setRecomputeMessage(`Updated ${result.updated} pokemon, ${result.unchanged} unchanged, ${result.handSet} with hand set stats${missing}`)
+                                setTrainers(await ParseTrainers())
+                                setSelectedTrainer(null)
+                            } catch (error) {
+                                setRecomputeMessage(`Recompute failed: ${error}`)
+                            }
+                        }}
+                        className="px-6 py-2 bg-slate-600 text-white rounded-xl hover:bg-slate-500 transition-colors duration-200"
+                    >
+                        Recompute Stats
+                    </button>
+                    {recomputeMessage && <p className="text-gray-300 text-sm text-center">{recomputeMessage}</p>}
                 </div>
             </div>
         </div>
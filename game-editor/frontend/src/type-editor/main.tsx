@@ -0,0 +1,182 @@
+import { useEffect, useState } from "react"
+import { Button } from "../components/ui/button"
+import { models } from "../../bindings/github.com/zenith110/pokemon-engine-tools/models"
+import { CreateType, DeleteType, GetTypes, UpdateType } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/type-editor/TypeEditorApp"
+
+// multipliers the type chart offers, 1 is left out of types.toml
+const multipliers = [0, 0.5, 1, 2]
+
+// Edits types.toml: the types, their colors and icons and the type chart row
+// of the selected type. Renaming a type moves its pokemon and moves along.
+const TypeEditor = () => {
+    const [types, setTypes] = useState<models.TypeDefinition[]>([])
+    const [selectedName, setSelectedName] = useState<string | null>(null)
+    const [draft, setDraft] = useState<models.TypeDefinition | null>(null)
+    const [message, setMessage] = useState("")
+
+    const load = async () => {
+        try {
+            const definitions = await GetTypes()
+            setTypes(definitions)
+            return definitions
+        } catch (error) {
+            setMessage(`Could not load the types: ${error}`)
+            return []
+        }
+    }
+
+    useEffect(() => {
+        load()
+    }, [])
+
+    const select = (definition: models.TypeDefinition | null) => {
+        setSelectedName(definition?.name ?? null)
+        setDraft(definition ? { ...definition, effectiveness: { ...(definition.effectiveness ?? {}) } } : { name: "", color: "#9ca3af", icon: "", effectiveness: {} })
+        setMessage("")
+    }
+
+    const multiplierAgainst = (defending: string) => {
+        const key = Object.keys(draft?.effectiveness ?? {}).find((name) => name.toLowerCase() === defending.toLowerCase())
+        return key === undefined ? 1 : draft!.effectiveness[key]
+    }
+
+    const setMultiplier = (defending: string, multiplier: number) => {
+        if (!draft) return
+        const effectiveness = Object.fromEntries(
+            Object.entries(draft.effectiveness ?? {}).filter(([name]) => name.toLowerCase() !== defending.toLowerCase())
+        )
+        if (multiplier !== 1) {
+            effectiveness[defending] = multiplier
+        }
+        setDraft({ ...draft, effectiveness })
+    }
+
+    const save = async () => {
+        if (!draft) return
+        try {
+            let saved = `Saved ${draft.name}`
+            if (selectedName === null) {
+                await CreateType(draft)
+                saved = `Created ${draft.name}`
+            } else {
+                const moved = await UpdateType(selectedName, draft)
+                if (draft.name !== selectedName) {
+                    saved = `Renamed ${selectedName} to ${draft.name} on ${moved} pokemon and moves`
+                }
+            }
+            const definitions = await load()
+            select(definitions.find((definition) => definition.name.toLowerCase() === draft.name.trim().toLowerCase()) ?? null)
+            setMessage(saved)
+        } catch (error) {
+            setMessage(`Could not save ${draft.name}: ${error}`)
+        }
+    }
+
+    const remove = async () => {
+        if (selectedName === null) return
+        try {
+            await DeleteType(selectedName)
+            setMessage(`Deleted ${selectedName}`)
+            setSelectedName(null)
+            setDraft(null)
+            await load()
+        } catch (error) {
+            setMessage(`Could not delete ${selectedName}: ${error}`)
+        }
+    }
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <div className="flex items-center justify-between">
+                    <div>
+                        <h1 className="text-2xl font-bold">Type Editor</h1>
+                        <p className="text-slate-400">{types.length} type(s)</p>
+                    </div>
+                    <Button onClick={() => select(null)} className="bg-tealBlue hover:bg-wildBlueYonder">
+                        New type
+                    </Button>
+                </div>
+                {message && <p className="text-sm text-slate-300">{message}</p>}
+                <div className="grid grid-cols-4 gap-4">
+                    <div className="space-y-1">
+                        {types.map((definition) => (
+                            <button
+                                key={definition.name}
+                                onClick={() => select(definition)}
+                                className={`w-full rounded-lg px-3 py-2 text-left ${selectedName === definition.name ? "bg-slate-700" : "bg-slate-800 hover:bg-slate-700"}`}
+                            >
+                                <span className="mr-2 inline-block h-3 w-3 rounded-full" style={{ backgroundColor: definition.color }} />
+                                {definition.name}
+                            </button>
+                        ))}
+                    </div>
+                    {draft && (
+                        <div className="col-span-3 space-y-4 rounded-xl bg-slate-800 p-4">
+                            <div className="grid grid-cols-3 gap-4">
+                                <label className="text-sm text-slate-300">
+                                    Name
+                                    <input
+                                        value={draft.name}
+                                        onChange={(e) => setDraft({ ...draft, name: e.target.value })}
+                                        className="mt-1 w-full rounded-lg border border-slate-600 bg-slate-700 px-3 py-2 text-white focus:outline-none"
+                                    />
+                                </label>
+                                <label className="text-sm text-slate-300">
+                                    Color
+                                    <input
+                                        type="color"
+                                        value={draft.color}
+                                        onChange={(e) => setDraft({ ...draft, color: e.target.value })}
+                                        className="mt-1 h-10 w-full rounded-lg bg-slate-700"
+                                    />
+                                </label>
+                                <label className="text-sm text-slate-300">
+                                    Icon
+                                    <input
+                                        value={draft.icon}
+                                        onChange={(e) => setDraft({ ...draft, icon: e.target.value })}
+                                        placeholder="e.g. fire.png"
+                                        className="mt-1 w-full rounded-lg border border-slate-600 bg-slate-700 px-3 py-2 text-white focus:outline-none"
+                                    />
+                                </label>
+                            </div>
+                            <div>
+                                <h2 className="mb-2 font-semibold">{draft.name || "New type"} moves against</h2>
+                                <div className="grid grid-cols-3 gap-2">
+                                    {/* a new type sets its multiplier against itself once it is created */}
+                                    {types.map(({ name }) => (
+                                        <label key={name} className="flex items-center justify-between rounded-lg bg-slate-700 px-3 py-1 text-sm">
+                                            {name === selectedName ? draft.name || name : name}
+                                            <select
+                                                value={multiplierAgainst(name)}
+                                                onChange={(e) => setMultiplier(name, parseFloat(e.target.value))}
+                                                className="rounded bg-slate-800 px-2 py-1"
+                                            >
+                                                {multipliers.map((multiplier) => (
+                                                    <option key={multiplier} value={multiplier}>×{multiplier}</option>
+                                                ))}
+                                            </select>
+                                        </label>
+                                    ))}
+                                </div>
+                            </div>
+                            <div className="flex gap-2">
+                                <Button onClick={save} className="bg-tealBlue hover:bg-wildBlueYonder">
+                                    {selectedName === null ? "Create" : "Save"}
+                                </Button>
+                                {selectedName !== null && (
+                                    <Button onClick={remove} className="bg-red-700 hover:bg-red-600">
+                                        Delete
+                                    </Button>
+                                )}
+                            </div>
+                        </div>
+                    )}
+                </div>
+            </div>
+        </div>
+    )
+}
+
+export default TypeEditor
@@ -0,0 +1,69 @@
+import { useState } from "react"
+import { Button } from "../components/ui/button"
+import { ValidateProject } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/validator/ValidatorApp"
+import { Report } from "../../bindings/github.com/zenith110/pokemon-engine-tools/tools/validator/models"
+
+// Runs the project validator and lists what it found, errors first
+const Validator = () => {
+    const [report, setReport] = useState<Report | null>(null)
+    const [isRunning, setIsRunning] = useState(false)
+    const [error, setError] = useState("")
+
+    const validate = async () => {
+        setIsRunning(true)
+        try {
+            setReport(await ValidateProject())
+            setError("")
+        } catch (error) {
+            setError(`Could not validate the project: ${error}`)
+        } finally {
+            setIsRunning(false)
+        }
+    }
+
+    const diagnostics = [...(report?.diagnostics ?? [])].sort((a, b) =>
+        a.severity === b.severity ? 0 : a.severity === "error" ? -1 : 1
+    )
+
+    return (
+        <div className="min-h-screen bg-slate-950 text-white p-6">
+            <div className="max-w-6xl mx-auto space-y-4">
+                <div className="flex items-center justify-between">
+                    <div>
+                        <h1 className="text-2xl font-bold">Project Validator</h1>
+                        {report && (
+                            <p className="text-slate-400">{report.errors} error(s), {report.warnings} warning(s)</p>
+                        )}
+                    </div>
+                    <Button onClick={validate} disabled={isRunning} className="bg-tealBlue hover:bg-wildBlueYonder">
+                        {isRunning ? "Validating..." : "Validate project"}
+                    </Button>
+                </div>
+                {error && <p className="text-sm text-red-400">{error}</p>}
+                {report && diagnostics.length === 0 && (
+                    <p className="rounded-lg bg-slate-800 p-4 text-green-400">No problems found.</p>
+                )}
+                <div className="space-y-2">
+                    {diagnostics.map((diagnostic, index) => (
+                        <div key={index} className="rounded-lg bg-slate-800 p-3">
+                            <div className="flex items-center gap-2 text-sm">
+                                <span className={diagnostic.severity === "error" ? "font-semibold text-red-400" : "font-semibold text-yellow-400"}>
+                                    {diagnostic.severity}
+                                </span>
+                                <span className="text-slate-400">{diagnostic.code}</span>
+                                <span className="truncate text-slate-300">
+                                    {diagnostic.file}
+                                    {diagnostic.record && ` #${diagnostic.record}`}
+                                    {diagnostic.field && ` ${diagnostic.field}`}
+                                </span>
+                            </div>
+                            <p>{diagnostic.message}</p>
+                        </div>
+                    ))}
+                </div>
+            </div>
+        </div>
+    )
+}
+
+export default Validator
@@ -10,6 +10,8 @@ replace github.com/zenith110/pokemon-engine-tools/parsing => ./parsing
 
 replace github.com/zenith110/pokemon-engine-tools/models => ./models
 
+replace github.com/zenith110/pokemon-engine-tools/repository => ./repository
+
 replace github.com/zenith110/pokemon-engine-tools/tools/map-editor => ./tools/mapeditor
 
 replace github.com/zenith110/pokemon-engine-tools/tools/jukebox => ./tools/jukebox
@@ -22,22 +24,42 @@ replace github.com/zenith110/pokemon-engine-tools/tools/move-editor => ./tools/m
 
 replace github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor => ./tools/pokemon-editor
 
+replace github.com/zenith110/pokemon-engine-tools/tools/validator => ./tools/validator
+
+replace github.com/zenith110/pokemon-engine-tools/tools/search => ./tools/search
+
+replace github.com/zenith110/pokemon-engine-tools/tools/export => ./tools/export
+
+replace github.com/zenith110/pokemon-engine-tools/tools/decomp-importer => ./tools/decomp-importer
+
+replace github.com/zenith110/pokemon-engine-tools/tools/battle-simulator => ./tools/battle-simulator
+
+replace github.com/zenith110/pokemon-engine-tools/tools/type-editor => ./tools/type-editor
+
 require (
 	github.com/gin-gonic/gin v1.10.1
 	github.com/wailsapp/wails/v3 v3.0.0-alpha.16
+	github.com/zenith110/pokemon-engine-tools/models v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/parsing v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/repository v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools-core v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/battle-simulator v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/decomp-importer v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/export v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/jukebox v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/map-editor v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/move-editor v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/overworld-editor v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/search v0.0.0-00010101000000-000000000000
 	github.com/zenith110/pokemon-engine-tools/tools/trainer-editor v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/type-editor v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools/validator v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8
 )
 
 require (
 	dario.cat/mergo v1.0.1 // indirect
-	github.com/BurntSushi/toml v1.5.0 // indirect
 	github.com/Microsoft/go-winio v0.6.2 // indirect
 	github.com/ProtonMail/go-crypto v1.1.6 // indirect
 	github.com/adrg/xdg v0.5.3 // indirect
@@ -51,6 +73,7 @@ require (
 	github.com/cyphar/filepath-securejoin v0.4.1 // indirect
 	github.com/ebitengine/purego v0.8.2 // indirect
 	github.com/emirpasic/gods v1.18.1 // indirect
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/go-git/gcfg v1.5.1-0.20230307220236-3a3c6141e376 // indirect
@@ -94,8 +117,6 @@ require (
 	github.com/wailsapp/mimetype v1.4.1 // indirect
 	github.com/wailsapp/wails/v2 v2.10.1 // indirect
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
-	github.com/zenith110/pokemon-engine-tools/models v0.0.0-00010101000000-000000000000 // indirect
-	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8 // indirect
 	golang.org/x/arch v0.8.0 // indirect
 	golang.org/x/crypto v0.40.0 // indirect
 	golang.org/x/net v0.41.0 // indirect
@@ -12,12 +12,18 @@ import (
 	"github.com/wailsapp/wails/v3/pkg/application"
 	parsing "github.com/zenith110/pokemon-engine-tools/parsing"
 	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	battleSimulator "github.com/zenith110/pokemon-engine-tools/tools/battle-simulator"
+	decompImporter "github.com/zenith110/pokemon-engine-tools/tools/decomp-importer"
+	export "github.com/zenith110/pokemon-engine-tools/tools/export"
 	jukebox "github.com/zenith110/pokemon-engine-tools/tools/jukebox"
 	mapEditor "github.com/zenith110/pokemon-engine-tools/tools/map-editor"
 	moveEditor "github.com/zenith110/pokemon-engine-tools/tools/move-editor"
 	overworldEditor "github.com/zenith110/pokemon-engine-tools/tools/overworld-editor"
 	pokemonEditor "github.com/zenith110/pokemon-engine-tools/tools/pokemon-editor"
+	search "github.com/zenith110/pokemon-engine-tools/tools/search"
 	trainerEditor "github.com/zenith110/pokemon-engine-tools/tools/trainer-editor"
+	typeEditor "github.com/zenith110/pokemon-engine-tools/tools/type-editor"
+	validator "github.com/zenith110/pokemon-engine-tools/tools/validator"
 )
 
 //go:embed all:frontend/dist
@@ -40,6 +46,15 @@ func main() {
 	jukeboxApp := jukebox.NewJukeboxApp(coreApp)
 	parsingApp := parsing.NewParsingApp(coreApp)
 	pokemonEditorApp := pokemonEditor.NewPokemonEditorApp(coreApp)
+	validatorApp := validator.NewValidatorApp(coreApp)
+	searchApp := search.NewSearchApp(coreApp)
+	exportApp := export.NewExportApp(coreApp)
+	decompImporterApp := decompImporter.NewDecompImporterApp(coreApp)
+	battleSimulatorApp := battleSimulator.NewBattleSimulatorApp(coreApp)
+	typeEditorApp := typeEditor.NewTypeEditorApp(coreApp)
+
+	// Project sprites, cries and music are served by URL instead of inlined
+	assetServer := core.NewAssetServer(coreApp)
 
 	// Set up Gin router for SSE
 	gin.SetMode(gin.ReleaseMode)
@@ -70,17 +85,25 @@ func main() {
 			application.NewService(jukeboxApp),
 			application.NewService(parsingApp),
 			application.NewService(pokemonEditorApp),
+			application.NewService(validatorApp),
+			application.NewService(searchApp),
+			application.NewService(exportApp),
+			application.NewService(decompImporterApp),
+			application.NewService(battleSimulatorApp),
+			application.NewService(typeEditorApp),
 		},
 		Assets: application.AssetOptions{
-			Handler: application.AssetFileServerFS(assets),
+			Handler:    application.AssetFileServerFS(assets),
+			Middleware: assetServer.Middleware,
 		},
 		Mac: application.MacOptions{
 			ApplicationShouldTerminateAfterLastWindowClosed: true,
 		},
 	})
 
-	// Set the Wails app instance for event emission
-	mapEditorApp.SetWailsApp(app)
+	// Send the map editor's render events to the frontend
+	mapEditorApp.SetEventEmitter(app.Event)
+	coreApp.Events = app.Event
 
 	// Create a new window with the necessary options
 	app.Window.NewWithOptions(application.WebviewWindowOptions{
@@ -5,29 +5,39 @@ import (
 )
 
 type PokemonJson struct {
-	Species        string   `json:"species"`
-	Level          int      `json:"level"`
-	HP             int      `json:"hp"`
-	Attack         int      `json:"attack"`
-	Defense        int      `json:"defense"`
-	Speed          int      `json:"speed"`
-	SpecialAttack  int      `json:"specialAttack"`
-	SpecialDefense int      `json:"specialDefense"`
-	Moves          []string `json:"moves"`
-	HeldItem       string   `json:"heldItem"`
-	ID             string   `json:"id"`
-	Front          string   `json:"front"`
-	Icon           string   `json:"icon"`
-	Cry            string   `json:"cry"`
+	Species        string      `json:"species"`
+	Level          int         `json:"level"`
+	HP             int         `json:"hp"`
+	Attack         int         `json:"attack"`
+	Defense        int         `json:"defense"`
+	Speed          int         `json:"speed"`
+	SpecialAttack  int         `json:"specialAttack"`
+	SpecialDefense int         `json:"specialDefense"`
+	Moves          []string    `json:"moves"`
+	HeldItem       string      `json:"heldItem"`
+	ID             string      `json:"id"`
+	Front          string      `json:"front"`
+	Icon           string      `json:"icon"`
+	Cry            string      `json:"cry"`
+	IVs            *StatSpread `json:"ivs"`
+	EVs            *StatSpread `json:"evs"`
+	Nature         string      `json:"nature"`
 }
 
 type TrainerJson struct {
-	Name       string        `json:"name"`
-	Sprite     string        `json:"sprite"`
-	SpriteName string        `json:"spritename"`
-	Id         string        `json:"id"`
-	Pokemons   []PokemonJson `json:"pokemons"`
-	ClassType  string        `json:"classType"`
+	Name       string             `json:"name"`
+	Sprite     string             `json:"sprite"`
+	SpriteName string             `json:"spritename"`
+	Id         string             `json:"id"`
+	Pokemons   []PokemonJson      `json:"pokemons"`
+	ClassType  string             `json:"classType"`
+	Variants   []PartyVariantJson `json:"variants"`
+	AI         *AIProfile         `json:"ai"`
+}
+
+type PartyVariantJson struct {
+	Name     string        `json:"name"`
+	Pokemons []PokemonJson `json:"pokemons"`
 }
 
 type OptionsConfig struct {
@@ -64,6 +74,7 @@ type PokemonTrainerEditor struct {
 	Evolutions     []Evolution
 	Types          []string
 	Cry            string
+	DexEntry       string
 }
 
 type CreateNewTileset struct {
@@ -160,6 +171,8 @@ type ProjectCreation struct {
 	Name      string `json:"name"`
 	ID        string `json:"id"`
 	Directory string `json:"directory"`
+	// Template is a local engine checkout or archive, empty clones from GitHub
+	Template string `json:"template"`
 }
 
 type UpdatedMove struct {
@@ -213,3 +226,19 @@ type PokemonEvolutionResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// SpeciesJson is a species as the pokemon editor saves it, its evolutions and
+// assets are edited on their own
+type SpeciesJson struct {
+	Name           string             `json:"name"`
+	Types          []string           `json:"types"`
+	DexEntry       string             `json:"dexEntry"`
+	HP             int                `json:"hp"`
+	Attack         int                `json:"attack"`
+	Defense        int                `json:"defense"`
+	SpecialAttack  int                `json:"specialAttack"`
+	SpecialDefense int                `json:"specialDefense"`
+	Speed          int                `json:"speed"`
+	Abilities      []models.Abilities `json:"abilities"`
+	Moves          []models.Moves     `json:"moves"`
+}
@@ -1,5 +1,9 @@
 package models
 
+import (
+	"github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
 type TilesetData struct {
 	Tilesets []Tileset `toml:"tilesets"`
 }
@@ -72,3 +76,108 @@ type Map struct {
 	CaveEncounters    []CaveEncounters    `toml:"caveEncounters"`
 	FishingEncounters []FishingEncounters `toml:"fishingEncounters"`
 }
+
+// TrainerToml is trainers.toml. It follows the engine's trainer models and
+// adds the IVs, EVs and nature a party pokemon's stats are computed from.
+type TrainerToml struct {
+	Trainers []Trainer `toml:"trainers"`
+}
+type Trainer struct {
+	Name      string           `toml:"name"`
+	Sprite    string           `toml:"sprite"`
+	ID        string           `toml:"id"`
+	ClassType string           `toml:"classType"`
+	Pokemons  []TrainerPokemon `toml:"pokemon"`
+	Variants  []PartyVariant   `toml:"variants,omitempty"`
+	AI        *AIProfile       `toml:"ai,omitempty"`
+}
+
+// PartyVariant is a named alternative party of a trainer, e.g. a rematch or
+// the hard mode party
+type PartyVariant struct {
+	Name     string           `toml:"name"`
+	Pokemons []TrainerPokemon `toml:"pokemon"`
+}
+
+// TrainerPokemon is a party pokemon. When IVs is set its stats are derived
+// from the species' base stats, otherwise they were typed by hand.
+type TrainerPokemon struct {
+	models.Pokemons
+	IVs    *StatSpread `toml:"ivs,omitempty"`
+	EVs    *StatSpread `toml:"evs,omitempty"`
+	Nature string      `toml:"nature,omitempty"`
+}
+type StatSpread struct {
+	HP             int `toml:"hp" json:"hp"`
+	Attack         int `toml:"attack" json:"attack"`
+	Defense        int `toml:"defense" json:"defense"`
+	Speed          int `toml:"speed" json:"speed"`
+	SpecialAttack  int `toml:"specialAttack" json:"specialAttack"`
+	SpecialDefense int `toml:"specialDefense" json:"specialDefense"`
+}
+
+// TrainerClassToml is trainerclasses.toml. Its classes add prize money,
+// a default sprite, encounter music and an AI profile to the engine's name
+// and battle music.
+type TrainerClassToml struct {
+	Data []TrainerClass `toml:"data"`
+}
+
+// TrainerClass is a trainer class, trainers refer to it by name in their
+// classType. Music is the battle music.
+type TrainerClass struct {
+	models.Data
+	PrizeMultiplier int        `toml:"prizeMultiplier,omitempty"`
+	Sprite          string     `toml:"sprite,omitempty"`
+	EncounterMusic  string     `toml:"encounterMusic,omitempty"`
+	AI              *AIProfile `toml:"ai,omitempty"`
+}
+
+// AIProfile is how a trainer battles: the AI flags it uses, when it switches
+// pokemon out and the items it may use. A trainer's profile adds to the one
+// of its class.
+type AIProfile struct {
+	Flags     []string `toml:"flags,omitempty" json:"flags"`
+	Switching string   `toml:"switching,omitempty" json:"switching"`
+	Items     []AIItem `toml:"items,omitempty" json:"items"`
+}
+
+// AIItem is an item a trainer can use in battle and how often
+type AIItem struct {
+	Name  string `toml:"name" json:"name"`
+	Count int    `toml:"count" json:"count"`
+}
+
+// TrainerAIToml is trainerai.toml, the resolved AI profile of every trainer
+// as the engine reads it. The editor is the source of truth for this layout,
+// the engine follows it.
+type TrainerAIToml struct {
+	Trainers []TrainerAI `toml:"trainers"`
+}
+
+// TrainerAI is the AI a trainer battles with once its class's profile is
+// applied. FlagMask has bit n set for the nth flag of the known AI flags.
+type TrainerAI struct {
+	ID        string   `toml:"id" json:"id"`
+	Name      string   `toml:"name" json:"name"`
+	Flags     []string `toml:"flags" json:"flags"`
+	FlagMask  int      `toml:"flagMask" json:"flagMask"`
+	Switching string   `toml:"switching" json:"switching"`
+	Items     []AIItem `toml:"items" json:"items"`
+}
+
+// TypesToml is types.toml, the project's types and their type chart. A
+// project without it uses the types of the mainline games.
+type TypesToml struct {
+	Types []TypeDefinition `toml:"types"`
+}
+
+// TypeDefinition is a type pokemon and moves refer to by name. Effectiveness
+// maps the defending types a move of this type is not neutral against to its
+// multiplier, e.g. 2, 0.5 or 0 for an immunity.
+type TypeDefinition struct {
+	Name          string             `toml:"name" json:"name"`
+	Color         string             `toml:"color" json:"color"`
+	Icon          string             `toml:"icon,omitempty" json:"icon"`
+	Effectiveness map[string]float64 `toml:"effectiveness,omitempty" json:"effectiveness"`
+}
@@ -1,39 +1,21 @@
 package parsing
 
 import (
-	"fmt"
-	"io"
-	"os"
-
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
-	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 )
 
-func (a *ParsingApp) ParseHeldItems() []coreModels.HeldItem {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/helditems.toml", a.app.DataDirectory))
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
-
-	var heldItems Models.HeldItemToml
-
-	b, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
-	}
-
-	err = toml.Unmarshal(b, &heldItems)
+func (a *ParsingApp) ParseHeldItems() ([]coreModels.HeldItem, error) {
+	heldItems, err := repository.NewHeldItemRepository(a.app.DataDirectory).All()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	var heldItemsData []coreModels.HeldItem
-	for heldItem := range heldItems.HeldItems {
+	for heldItem := range heldItems {
 		heldItemData := coreModels.HeldItem{
-			Name: heldItems.HeldItems[heldItem].Name,
+			Name: heldItems[heldItem].Name,
 		}
 		heldItemsData = append(heldItemsData, heldItemData)
 	}
-	return heldItemsData
+	return heldItemsData, nil
 }
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
 )
 
 func (j *ParsingApp) GrabMusicTracks() []coreModels.Song {
@@ -18,7 +19,7 @@ func (j *ParsingApp) GrabMusicTracks() []coreModels.Song {
 		songId := 0
 		songData := coreModels.Song{
 			Name: song.Name(),
-			Path: CreateBase64File(fmt.Sprintf("%s/data/assets/music/%s", j.app.DataDirectory, song.Name())),
+			Path: core.AssetURL(j.app.DataDirectory, fmt.Sprintf("music/%s", song.Name())),
 			ID:   strconv.Itoa(songId),
 		}
 		songId += 1
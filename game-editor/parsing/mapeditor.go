@@ -5,88 +5,26 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 )
 
-func (a *ParsingApp) GetAllTilesets() []coreModels.Tileset {
-	var tilesets []coreModels.Tileset
-	var tilesetData coreModels.TilesetData
-	tilesetTomlPath := fmt.Sprintf("%s/data/toml/tilesets.toml", a.app.DataDirectory)
-	tilesetFile, tilesetFileErr := os.Open(tilesetTomlPath)
-	if tilesetFileErr != nil {
-		fmt.Printf("Error occured while opening the toml file: %v\n", tilesetFileErr)
-	}
-	b, err := io.ReadAll(tilesetFile)
-	if err != nil {
-		fmt.Printf("Error reading tilesets.toml: %v\n", err)
-		return tilesets
-	}
-
-	err = toml.Unmarshal(b, &tilesetData)
-	if err != nil {
-		fmt.Printf("Error unmarshaling tileset.toml: %v\n", err)
-		return tilesets
-	}
-
-	for _, tileset := range tilesetData.Tilesets {
-		tilesets = append(tilesets, tileset)
-	}
-	return tilesets
+func (a *ParsingApp) GetAllTilesets() ([]coreModels.Tileset, error) {
+	return repository.NewTilesetRepository(a.app.DataDirectory).All()
 }
 
-func (a *ParsingApp) GetAllMaps() []coreModels.Map {
-	var maps []coreModels.Map
-	var mapsData coreModels.MapEditerMapData
-	tilesetTomlPath := fmt.Sprintf("%s/data/toml/maps.toml", a.app.DataDirectory)
-	mapFile, mapFileErr := os.Open(tilesetTomlPath)
-	if mapFileErr != nil {
-		fmt.Printf("Error occured while opening the toml file: %v\n", mapFileErr)
-	}
-	b, err := io.ReadAll(mapFile)
-	if err != nil {
-		fmt.Printf("Error reading maps.toml: %v\n", err)
-		return maps
-	}
-
-	err = toml.Unmarshal(b, &mapsData)
-	if err != nil {
-		fmt.Printf("Error unmarshaling tileset.toml: %v\n", err)
-		return maps
-	}
-
-	for _, mapData := range mapsData.Map {
-		maps = append(maps, mapData)
-	}
-	return maps
+func (a *ParsingApp) GetAllMaps() ([]coreModels.Map, error) {
+	return repository.NewMapRepository(a.app.DataDirectory).All()
 }
-func (a *ParsingApp) GetMapTomlByID(id int) coreModels.Map {
-	var mapData coreModels.MapEditerMapData
-	var selectedMap coreModels.Map
-	mapTomlPath := fmt.Sprintf("%s/data/toml/maps.toml", a.app.DataDirectory)
-	mapFile, mapFileErr := os.Open(mapTomlPath)
-	if mapFileErr != nil {
-		fmt.Printf("Error occured while opening the toml file: %v\n", mapFileErr)
-	}
-	b, err := io.ReadAll(mapFile)
-	if err != nil {
-		fmt.Printf("Error reading maps.toml: %v\n", err)
-		return mapData.Map[0]
-	}
 
-	err = toml.Unmarshal(b, &mapData)
+func (a *ParsingApp) GetMapTomlByID(id int) (coreModels.Map, error) {
+	entry, err := a.app.Index.Lookup("maps", strconv.Itoa(id))
 	if err != nil {
-		fmt.Printf("Error unmarshaling maps.toml: %v\n", err)
-		return mapData.Map[0]
-	}
-	for _, mapData := range mapData.Map {
-		if int(mapData.ID) == id {
-			selectedMap = mapData
-			break
-		}
+		return coreModels.Map{}, err
 	}
-	return selectedMap
+	return entry.Record.(coreModels.Map), nil
 }
 
 func (a *ParsingApp) ParseMapData(mapPath string) map[string]any {
@@ -2,13 +2,11 @@ package parsing
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"strings"
 	"sync"
 
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
 	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
 )
 
@@ -17,85 +15,53 @@ type OnLoadPokemonEditor struct {
 	Name string `toml:"species"`
 }
 
-func ParsePokemonFile(a *ParsingApp) []OnLoadPokemonEditor {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/pokemon.toml", a.app.DataDirectory))
-
-	if err != nil {
-		fmt.Printf("Error opening pokemon.toml: %v\n", err)
-		return []OnLoadPokemonEditor{}
-	}
-	defer file.Close()
-
-	var pokemons Models.PokemonToml
-
-	b, err := io.ReadAll(file)
+func ParsePokemonFile(a *ParsingApp) ([]OnLoadPokemonEditor, error) {
+	pokemons, err := a.app.Index.All("pokemon")
 	if err != nil {
-		fmt.Printf("Error reading pokemon.toml: %v\n", err)
-		return []OnLoadPokemonEditor{}
+		return []OnLoadPokemonEditor{}, err
 	}
 
-	err = toml.Unmarshal(b, &pokemons)
-	if err != nil {
-		fmt.Printf("Error unmarshaling pokemon.toml: %v\n", err)
-		return []OnLoadPokemonEditor{}
-	}
-
-	onLoadData := make([]OnLoadPokemonEditor, 0, len(pokemons.Pokemon))
-	for _, pokemon := range pokemons.Pokemon {
+	onLoadData := make([]OnLoadPokemonEditor, 0, len(pokemons))
+	for _, pokemon := range pokemons {
 		onLoad := OnLoadPokemonEditor{
 			ID:   pokemon.ID,
-			Name: strings.ToUpper(pokemon.Species[:1]) + pokemon.Species[1:],
+			Name: capitalize(pokemon.Name),
 		}
 		onLoadData = append(onLoadData, onLoad)
 	}
-	return onLoadData
-}
-
-func (a *ParsingApp) ParsePokemonData() []OnLoadPokemonEditor {
-	return ParsePokemonFile(a)
+	return onLoadData, nil
 }
 
-func (a *ParsingApp) LoadPokemonById(id string) coreModels.PokemonTrainerEditor {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/pokemon.toml", a.app.DataDirectory))
-	if err != nil {
-		fmt.Printf("Error opening pokemon.toml: %v\n", err)
-		return coreModels.PokemonTrainerEditor{}
+// capitalize upper cases the first letter of name. An empty name, which the
+// validator reports, stays empty instead of panicking.
+func capitalize(name string) string {
+	if name == "" {
+		return name
 	}
-	defer file.Close()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
 
-	var pokemons Models.PokemonToml
-	b, err := io.ReadAll(file)
-	if err != nil {
-		fmt.Printf("Error reading pokemon.toml: %v\n", err)
-		return coreModels.PokemonTrainerEditor{}
-	}
+func (a *ParsingApp) ParsePokemonData() ([]OnLoadPokemonEditor, error) {
+	return ParsePokemonFile(a)
+}
 
-	err = toml.Unmarshal(b, &pokemons)
+func (a *ParsingApp) LoadPokemonById(id string) (coreModels.PokemonTrainerEditor, error) {
+	entry, err := a.app.Index.Lookup("pokemon", id)
 	if err != nil {
-		fmt.Printf("Error unmarshaling pokemon.toml: %v\n", err)
-		return coreModels.PokemonTrainerEditor{}
-	}
-
-	// Find the specific Pokémon
-	var pokemonData Models.Pokemon
-	for _, p := range pokemons.Pokemon {
-		if p.ID == id {
-			pokemonData = p
-			break
-		}
+		return coreModels.PokemonTrainerEditor{}, err
 	}
 
 	// Create a temporary PokemonToml with just this Pokémon
 	tempToml := Models.PokemonToml{
-		Pokemon: []Models.Pokemon{pokemonData},
+		Pokemon: []Models.Pokemon{entry.Record.(Models.Pokemon)},
 	}
 
 	// Use existing function to load assets
 	result := CreatePokemonTrainerEditorData(tempToml, a)
 	if len(result) > 0 {
-		return result[0]
+		return result[0], nil
 	}
-	return coreModels.PokemonTrainerEditor{}
+	return coreModels.PokemonTrainerEditor{}, nil
 }
 
 func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp) []coreModels.PokemonTrainerEditor {
@@ -113,7 +79,7 @@ func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp)
 
 			// Load types
 			for _, pokemonType := range data.Types {
-				types = append(types, strings.ToUpper(pokemonType[:1])+pokemonType[1:])
+				types = append(types, capitalize(pokemonType))
 			}
 
 			// Load evolutions concurrently
@@ -127,12 +93,12 @@ func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp)
 				// Load evolution icon
 				go func() {
 					defer assetsWg.Done()
-					iconChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/icons/%s/%s.gif", a.app.DataDirectory, evoID, evoID))
+					iconChan <- core.AssetURL(a.app.DataDirectory, core.PokemonAssets(evoID).Icon)
 				}()
 
 				// Create evolution data
 				evolutionData := coreModels.Evolution{
-					Name:        strings.ToUpper(evolution.Name[:1]) + evolution.Name[1:],
+					Name:        capitalize(evolution.Name),
 					Method1:     "",
 					Method2:     "",
 					ID:          evoID,
@@ -152,6 +118,7 @@ func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp)
 			}
 
 			// Load main Pokémon assets concurrently
+			assets := core.PokemonAssets(data.ID)
 			assetsWg.Add(6)
 
 			// Create separate channels for each main asset type
@@ -164,32 +131,32 @@ func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp)
 
 			go func() {
 				defer assetsWg.Done()
-				frontChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/front/%s_front.png", a.app.DataDirectory, data.ID))
+				frontChan <- core.AssetURL(a.app.DataDirectory, assets.Front)
 			}()
 			go func() {
 				defer assetsWg.Done()
-				backChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/back/%s_back.png", a.app.DataDirectory, data.ID))
+				backChan <- core.AssetURL(a.app.DataDirectory, assets.Back)
 			}()
 			go func() {
 				defer assetsWg.Done()
-				shinyFrontChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/shinyfront/%s_front_shiny.png", a.app.DataDirectory, data.ID))
+				shinyFrontChan <- core.AssetURL(a.app.DataDirectory, assets.ShinyFront)
 			}()
 			go func() {
 				defer assetsWg.Done()
-				shinyBackChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/shinyback/%s_shiny_back.png", a.app.DataDirectory, data.ID))
+				shinyBackChan <- core.AssetURL(a.app.DataDirectory, assets.ShinyBack)
 			}()
 			go func() {
 				defer assetsWg.Done()
-				iconChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/icons/%s/%s.gif", a.app.DataDirectory, data.ID, data.ID))
+				iconChan <- core.AssetURL(a.app.DataDirectory, assets.Icon)
 			}()
 			go func() {
 				defer assetsWg.Done()
-				cryChan <- CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/cries/%s.wav", a.app.DataDirectory, data.ID))
+				cryChan <- core.AssetURL(a.app.DataDirectory, assets.Cry)
 			}()
 
 			// Create the main Pokémon data
 			trainerEditorPokemon := coreModels.PokemonTrainerEditor{
-				Name:           strings.ToUpper(data.Species[:1]) + data.Species[1:],
+				Name:           capitalize(data.Species),
 				HP:             data.Stats.Hp,
 				Defense:        data.Stats.Defense,
 				SpecialAttack:  data.Stats.SpecialAttack,
@@ -201,6 +168,7 @@ func CreatePokemonTrainerEditorData(pokemons Models.PokemonToml, a *ParsingApp)
 				Abilities:      data.Abilities,
 				Evolutions:     evolutions,
 				Types:          types,
+				DexEntry:       data.DexEntry,
 			}
 
 			// Wait for all assets to be loaded
@@ -1,91 +1,80 @@
 package parsing
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"os"
 
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
-	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
 )
 
-func ParseTrainerClassFile(a *ParsingApp) []byte {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/trainerclasses.toml", a.app.DataDirectory))
-	if err != nil {
-		panic(err)
+// ParseTrainerClass reads trainerclasses.toml, a project without one has no
+// classes yet
+func (a *ParsingApp) ParseTrainerClass() (coreModels.TrainerClassToml, error) {
+	classes, err := repository.NewTrainerClassRepository(a.app.DataDirectory).Load()
+	if errors.Is(err, repository.ErrMissingFile) {
+		return coreModels.TrainerClassToml{Data: []coreModels.TrainerClass{}}, nil
 	}
-	defer file.Close()
-
-	bytes, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
-	}
-	return bytes
-}
-func ParseTrainerClassModel(trainerclasses Models.TrainerClasses, bytes []byte) Models.TrainerClasses {
-	err := toml.Unmarshal(bytes, &trainerclasses)
-	if err != nil {
-		panic(err)
-	}
-	return trainerclasses
-}
-func (a *ParsingApp) ParseTrainerClass() Models.TrainerClasses {
-	bytes := ParseTrainerClassFile(a)
-	var trainerclasses Models.TrainerClasses
-	return ParseTrainerClassModel(trainerclasses, bytes)
+	return classes, err
 }
 
-func (a *ParsingApp) ParseTrainers() []coreModels.TrainerJson {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/trainers.toml", a.app.DataDirectory))
-	if err != nil {
-		panic(err)
-	}
-	defer file.Close()
-	var trainers Models.TrainerToml
-	bytes, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
-	}
-	err = toml.Unmarshal(bytes, &trainers)
+func (a *ParsingApp) ParseTrainers() ([]coreModels.TrainerJson, error) {
+	trainers, err := repository.NewTrainerRepository(a.app.DataDirectory).Load()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	var trainersData []coreModels.TrainerJson
 	for trainer := range trainers.Trainers {
-		var pokemons []coreModels.PokemonJson
-		for pokemon := range trainers.Trainers[trainer].Pokemons {
-			pokemonData := coreModels.PokemonJson{
-				Species:        trainers.Trainers[trainer].Pokemons[pokemon].Species,
-				HP:             trainers.Trainers[trainer].Pokemons[pokemon].HP,
-				Speed:          trainers.Trainers[trainer].Pokemons[pokemon].Speed,
-				SpecialAttack:  trainers.Trainers[trainer].Pokemons[pokemon].SpecialAttack,
-				SpecialDefense: trainers.Trainers[trainer].Pokemons[pokemon].SpecialDefense,
-				Attack:         trainers.Trainers[trainer].Pokemons[pokemon].Attack,
-				Defense:        trainers.Trainers[trainer].Pokemons[pokemon].Defense,
-				Front:          CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/front/%s_front.png", a.app.DataDirectory, trainers.Trainers[trainer].Pokemons[pokemon].ID)),
-				ID:             trainers.Trainers[trainer].Pokemons[pokemon].ID,
-				Icon:           CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/icons/%s/%s.gif", a.app.DataDirectory, trainers.Trainers[trainer].Pokemons[pokemon].ID, trainers.Trainers[trainer].Pokemons[pokemon].ID)),
-				Moves:          trainers.Trainers[trainer].Pokemons[pokemon].Moves,
-				Level:          trainers.Trainers[trainer].Pokemons[pokemon].Level,
-				HeldItem:       trainers.Trainers[trainer].Pokemons[pokemon].HeldItem,
-				Cry:            CreateBase64File(fmt.Sprintf("%s/data/assets/pokemon/cries/%s.wav", a.app.DataDirectory, trainers.Trainers[trainer].Pokemons[pokemon].ID)),
-			}
-			pokemons = append(pokemons, pokemonData)
-
+		variants := []coreModels.PartyVariantJson{}
+		for _, variant := range trainers.Trainers[trainer].Variants {
+			variants = append(variants, coreModels.PartyVariantJson{
+				Name:     variant.Name,
+				Pokemons: a.partyJson(variant.Pokemons),
+			})
 		}
-
 		trainerData := coreModels.TrainerJson{
 			Name:       trainers.Trainers[trainer].Name,
-			Sprite:     CreateBase64File(fmt.Sprintf("%s/data/assets/trainers_sprite/%s", a.app.DataDirectory, trainers.Trainers[trainer].Sprite)),
+			Sprite:     core.AssetURL(a.app.DataDirectory, fmt.Sprintf("trainers_sprite/%s", trainers.Trainers[trainer].Sprite)),
 			SpriteName: trainers.Trainers[trainer].Sprite,
 			Id:         trainers.Trainers[trainer].ID,
 			ClassType:  trainers.Trainers[trainer].ClassType,
-			Pokemons:   pokemons,
+			Pokemons:   a.partyJson(trainers.Trainers[trainer].Pokemons),
+			Variants:   variants,
+			AI:         trainers.Trainers[trainer].AI,
 		}
 		trainersData = append(trainersData, trainerData)
 	}
-	return trainersData
+	return trainersData, nil
+}
+
+// partyJson converts a stored party to the shape the editor works with
+func (a *ParsingApp) partyJson(party []coreModels.TrainerPokemon) []coreModels.PokemonJson {
+	var pokemons []coreModels.PokemonJson
+	for pokemon := range party {
+		pokemonData := coreModels.PokemonJson{
+			Species:        party[pokemon].Species,
+			HP:             party[pokemon].HP,
+			Speed:          party[pokemon].Speed,
+			SpecialAttack:  party[pokemon].SpecialAttack,
+			SpecialDefense: party[pokemon].SpecialDefense,
+			Attack:         party[pokemon].Attack,
+			Defense:        party[pokemon].Defense,
+			Front:          core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/front/%s_front.png", party[pokemon].ID)),
+			ID:             party[pokemon].ID,
+			Icon:           core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/icons/%s/%s.gif", party[pokemon].ID, party[pokemon].ID)),
+			Moves:          party[pokemon].Moves,
+			Level:          party[pokemon].Level,
+			HeldItem:       party[pokemon].HeldItem,
+			Cry:            core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/cries/%s.wav", party[pokemon].ID)),
+			IVs:            party[pokemon].IVs,
+			EVs:            party[pokemon].EVs,
+			Nature:         party[pokemon].Nature,
+		}
+		pokemons = append(pokemons, pokemonData)
+	}
+	return pokemons
 }
 
 func (a *ParsingApp) GrabTrainerSprites() []coreModels.TrainerSprite {
@@ -97,7 +86,7 @@ func (a *ParsingApp) GrabTrainerSprites() []coreModels.TrainerSprite {
 	for _, sprite := range trainerSprites {
 		trainerSprites := coreModels.TrainerSprite{
 			Name: sprite.Name(),
-			Path: CreateBase64File(fmt.Sprintf("%s/data/assets/trainers_sprite/%s", a.app.DataDirectory, sprite.Name())),
+			Path: core.AssetURL(a.app.DataDirectory, fmt.Sprintf("trainers_sprite/%s", sprite.Name())),
 		}
 		trainerSpritesResult = append(trainerSpritesResult, trainerSprites)
 	}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	tempSuffix   = ".tmp"
+	backupSuffix = ".bak"
+)
+
+// WriteFile replaces the file at path with data without ever leaving a
+// truncated file behind. The data is written and synced to a temp file next
+// to path, the previous version is kept as path.bak and the temp file is then
+// renamed over path. A crash before the rename leaves the temp file around
+// for PendingWrites to find.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	directory := filepath.Dir(path)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	temp, err := writeTemp(path, data, perm)
+	if err != nil {
+		return err
+	}
+	previous, err := backup(path, perm)
+	if err != nil {
+		os.Remove(temp)
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	syncDirectory(directory)
+	if data == nil {
+		data = []byte{}
+	}
+	notifyWrite(path, previous, data)
+	return nil
+}
+
+// RemoveFile deletes the file at path, keeping its last version as path.bak
+func RemoveFile(path string) error {
+	previous, err := backup(path, 0644)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	syncDirectory(filepath.Dir(path))
+	notifyWrite(path, previous, nil)
+	return nil
+}
+
+// writeTemp writes data to a fresh path.<random>.tmp file and syncs it
+func writeTemp(path string, data []byte, perm os.FileMode) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+tempSuffix)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// backup copies the current contents of path to path.bak, itself through a
+// temp file so a crash never leaves a half written backup. It returns the
+// previous contents, nil when there was no file yet.
+func backup(path string, perm os.FileMode) ([]byte, error) {
+	previous, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	temp, err := writeTemp(path+backupSuffix, previous, perm)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(temp, path+backupSuffix); err != nil {
+		os.Remove(temp)
+		return nil, err
+	}
+	return previous, nil
+}
+
+// syncDirectory flushes a rename to disk. Directories cannot be synced on
+// every platform, so failures are ignored.
+func syncDirectory(directory string) {
+	d, err := os.Open(directory)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// PendingWrite is a temp file left behind by a WriteFile that never finished
+type PendingWrite struct {
+	Path      string    `json:"path"`
+	TempPath  string    `json:"tempPath"`
+	Size      int64     `json:"size"`
+	Modified  time.Time `json:"modified"`
+	HasBackup bool      `json:"hasBackup"`
+}
+
+// PendingWrites walks the given directories for temp files left behind by an
+// interrupted WriteFile. Missing directories are skipped.
+func PendingWrites(directories ...string) ([]PendingWrite, error) {
+	var pending []PendingWrite
+	for _, directory := range directories {
+		err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != directory && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if _, ok := tempTarget(path); !ok {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			pending = append(pending, pendingWrite(path, info))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].TempPath < pending[j].TempPath })
+	return pending, nil
+}
+
+// PendingWritesOf returns the temp files left behind by interrupted writes to
+// the given files
+func PendingWritesOf(paths ...string) ([]PendingWrite, error) {
+	var pending []PendingWrite
+	for _, path := range paths {
+		matches, err := filepath.Glob(path + ".*" + tempSuffix)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if _, ok := tempTarget(match); !ok {
+				continue
+			}
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			pending = append(pending, pendingWrite(match, info))
+		}
+	}
+	return pending, nil
+}
+
+func pendingWrite(tempPath string, info fs.FileInfo) PendingWrite {
+	target, _ := tempTarget(tempPath)
+	_, backupErr := os.Stat(target + backupSuffix)
+	return PendingWrite{
+		Path:      filepath.ToSlash(target),
+		TempPath:  filepath.ToSlash(tempPath),
+		Size:      info.Size(),
+		Modified:  info.ModTime(),
+		HasBackup: backupErr == nil,
+	}
+}
+
+// tempTarget maps moves.toml.123456.tmp back to moves.toml
+func tempTarget(tempPath string) (string, bool) {
+	if !strings.HasSuffix(tempPath, tempSuffix) {
+		return "", false
+	}
+	withoutSuffix := strings.TrimSuffix(tempPath, tempSuffix)
+	random := filepath.Ext(withoutSuffix)
+	if len(random) < 2 || strings.Trim(random[1:], "0123456789") != "" {
+		return "", false
+	}
+	target := strings.TrimSuffix(withoutSuffix, random)
+	if filepath.Ext(target) == "" {
+		return "", false
+	}
+	return target, true
+}
+
+// UnsafePath reports whether path climbs out of its directory with a ..
+// segment or points into the .git or .editor folders, which the editor
+// never writes through a restore
+func UnsafePath(path string) bool {
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." || segment == ".git" || segment == ".editor" {
+			return true
+		}
+	}
+	return false
+}
+
+// RestorePendingWrite finishes an interrupted write by moving the temp file
+// over its target, keeping the current target as the backup
+func RestorePendingWrite(tempPath string) error {
+	target, ok := tempTarget(tempPath)
+	if !ok || UnsafePath(tempPath) {
+		return &Error{Kind: ErrNotFound, File: filepath.Base(tempPath), Err: errors.New("not a pending write")}
+	}
+	data, err := os.ReadFile(tempPath)
+	if err != nil {
+		return &Error{Kind: ErrMissingFile, File: filepath.Base(tempPath), Err: err}
+	}
+	if err := WriteFile(target, data, 0644); err != nil {
+		return &Error{Kind: ErrWriteFailed, File: filepath.Base(target), Err: err}
+	}
+	return os.Remove(tempPath)
+}
+
+// DiscardPendingWrite deletes the temp file of an interrupted write
+func DiscardPendingWrite(tempPath string) error {
+	if _, ok := tempTarget(tempPath); !ok || UnsafePath(tempPath) {
+		return &Error{Kind: ErrNotFound, File: filepath.Base(tempPath), Err: errors.New("not a pending write")}
+	}
+	if err := os.Remove(tempPath); err != nil {
+		return &Error{Kind: ErrMissingFile, File: filepath.Base(tempPath), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteFile(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "toml", "moves.toml")
+
+	if err := WriteFile(path, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "first" {
+		t.Errorf("file holds %q, want first", got)
+	}
+	if _, err := os.Stat(path + backupSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a new file got a backup: %v", err)
+	}
+
+	if err := WriteFile(path, []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "second" {
+		t.Errorf("file holds %q, want second", got)
+	}
+	if got := readFile(t, path+backupSuffix); got != "first" {
+		t.Errorf("backup holds %q, want first", got)
+	}
+
+	if err := RemoveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("removed file still exists: %v", err)
+	}
+	if got := readFile(t, path+backupSuffix); got != "second" {
+		t.Errorf("backup holds %q after removing, want second", got)
+	}
+
+	pending, err := PendingWrites(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("writes that finished left %+v", pending)
+	}
+}
+
+func TestWriteHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.toml")
+	type write struct{ before, after string }
+	var writes []write
+	AddWriteHook(func(hookPath string, before []byte, after []byte) {
+		if hookPath == filepath.ToSlash(path) {
+			writes = append(writes, write{string(before), string(after)})
+		}
+	})
+	WriteFile(path, []byte("a"), 0644)
+	WriteFile(path, nil, 0644)
+	RemoveFile(path)
+	want := []write{{"", "a"}, {"a", ""}, {"", ""}}
+	if len(writes) != len(want) {
+		t.Fatalf("hook saw %+v, want %+v", writes, want)
+	}
+	for index := range want {
+		if writes[index] != want[index] {
+			t.Errorf("write %d was %+v, want %+v", index, writes[index], want[index])
+		}
+	}
+}
+
+func TestTempTarget(t *testing.T) {
+	tests := []struct {
+		path   string
+		target string
+		ok     bool
+	}{
+		{"data/moves.toml.123456.tmp", "data/moves.toml", true},
+		{"data/moves.toml.bak.42.tmp", "data/moves.toml.bak", true},
+		{"data/moves.toml.tmp", "", false},
+		{"data/moves.toml.abc.tmp", "", false},
+		{"data/notes.1.tmp", "", false},
+		{"data/moves.toml", "", false},
+	}
+	for _, test := range tests {
+		target, ok := tempTarget(test.path)
+		if target != test.target || ok != test.ok {
+			t.Errorf("tempTarget(%q) = %q, %v, want %q, %v", test.path, target, ok, test.target, test.ok)
+		}
+	}
+}
+
+func TestPendingWrites(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "toml", "moves.toml")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("current"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A write that crashed before its rename
+	temp := path + ".987.tmp"
+	if err := os.WriteFile(temp, []byte("interrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Temp files of the editor's own folders are not the project's
+	hidden := filepath.Join(directory, ".editor", "state.json.1.tmp")
+	if err := os.MkdirAll(filepath.Dir(hidden), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hidden, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := PendingWrites(directory, filepath.Join(directory, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PendingWrite{Path: filepath.ToSlash(path), TempPath: filepath.ToSlash(temp), Size: int64(len("interrupted")), HasBackup: true}
+	if len(pending) != 1 {
+		t.Fatalf("got %+v, want only %s", pending, temp)
+	}
+	pending[0].Modified = want.Modified
+	if pending[0] != want {
+		t.Errorf("got %+v, want %+v", pending[0], want)
+	}
+	if of, err := PendingWritesOf(path); err != nil || len(of) != 1 || of[0].TempPath != want.TempPath {
+		t.Errorf("PendingWritesOf found %+v, %v", of, err)
+	}
+
+	if err := RestorePendingWrite(temp); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "interrupted" {
+		t.Errorf("restored file holds %q, want interrupted", got)
+	}
+	if got := readFile(t, path+backupSuffix); got != "current" {
+		t.Errorf("backup holds %q after restoring, want current", got)
+	}
+	if _, err := os.Stat(temp); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("restored temp file still exists: %v", err)
+	}
+
+	if err := os.WriteFile(temp, []byte("again"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := DiscardPendingWrite(temp); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "interrupted" {
+		t.Errorf("discarding changed the file to %q", got)
+	}
+	if err := DiscardPendingWrite(temp); !errors.Is(err, ErrMissingFile) {
+		t.Errorf("discarding twice returned %v, want %v", err, ErrMissingFile)
+	}
+}
+
+func TestPendingWriteRefusesOtherFiles(t *testing.T) {
+	for _, path := range []string{"data/moves.toml", "data/../../moves.toml.1.tmp", "project/.git/config.toml.1.tmp", "project/.editor/state.json.1.tmp"} {
+		if err := RestorePendingWrite(path); !errors.Is(err, ErrNotFound) {
+			t.Errorf("restoring %s returned %v, want %v", path, err, ErrNotFound)
+		}
+		if err := DiscardPendingWrite(path); !errors.Is(err, ErrNotFound) {
+			t.Errorf("discarding %s returned %v, want %v", path, err, ErrNotFound)
+		}
+	}
+}
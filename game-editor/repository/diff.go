@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ChangedRecords compares two versions of a data file and returns the IDs of
+// the records that were added, removed or edited. Records are the tables of
+// every top level array, keyed by their id field or, lacking one, their name.
+// Either version may be nil for a created or deleted file.
+func ChangedRecords(before []byte, after []byte) ([]string, error) {
+	beforeRecords, err := recordsByID(before)
+	if err != nil {
+		return nil, err
+	}
+	afterRecords, err := recordsByID(after)
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for id, record := range afterRecords {
+		if previous, ok := beforeRecords[id]; !ok || !reflect.DeepEqual(previous, record) {
+			changed = append(changed, id)
+		}
+	}
+	for id := range beforeRecords {
+		if _, ok := afterRecords[id]; !ok {
+			changed = append(changed, id)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func recordsByID(data []byte) (map[string]any, error) {
+	records := make(map[string]any)
+	if data == nil {
+		return records, nil
+	}
+	var document map[string]any
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	for _, value := range document {
+		tables, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		for _, table := range tables {
+			fields, ok := table.(map[string]any)
+			if !ok {
+				continue
+			}
+			key, ok := fields["id"]
+			if !ok {
+				key, ok = fields["name"]
+			}
+			if ok {
+				records[fmt.Sprint(key)] = fields
+			}
+		}
+	}
+	return records, nil
+}
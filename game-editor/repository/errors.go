@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of repository failures, match them with errors.Is
+var (
+	ErrMissingFile   = errors.New("data file is missing")
+	ErrMalformedFile = errors.New("data file is malformed")
+	ErrNotFound      = errors.New("record not found")
+	ErrDuplicateID   = errors.New("record ID already exists")
+	ErrInvalidOrder  = errors.New("order does not name every record once")
+	ErrWriteFailed   = errors.New("data file could not be written")
+	ErrOutOfRange    = errors.New("record has values out of range")
+)
+
+// Error describes a failed operation on one of the project data files
+type Error struct {
+	Kind error
+	File string
+	ID   string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s: %v", e.File, e.Kind)
+	if e.ID != "" {
+		message = fmt.Sprintf("%s: %v (ID %s)", e.File, e.Kind, e.ID)
+	}
+	if e.Err != nil {
+		message = fmt.Sprintf("%s: %v", message, e.Err)
+	}
+	return message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
@@ -0,0 +1,11 @@
+module github.com/zenith110/pokemon-engine-tools/repository
+
+replace github.com/zenith110/pokemon-engine-tools/models => ../models
+
+go 1.22.2
+
+require (
+	github.com/pelletier/go-toml/v2 v2.2.3
+	github.com/zenith110/pokemon-engine-tools/models v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8
+)
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// WriteHook is told about every file WriteFile or RemoveFile changed. before
+// is nil when the file was created and after is nil when it was removed.
+type WriteHook func(path string, before []byte, after []byte)
+
+var (
+	writeHooksMu sync.RWMutex
+	writeHooks   []WriteHook
+)
+
+// AddWriteHook registers hook for all later writes
+func AddWriteHook(hook WriteHook) {
+	writeHooksMu.Lock()
+	defer writeHooksMu.Unlock()
+	writeHooks = append(writeHooks, hook)
+}
+
+func notifyWrite(path string, before []byte, after []byte) {
+	writeHooksMu.RLock()
+	hooks := writeHooks
+	writeHooksMu.RUnlock()
+	path = filepath.ToSlash(path)
+	for _, hook := range hooks {
+		hook(path, before, after)
+	}
+}
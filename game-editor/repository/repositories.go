@@ -0,0 +1,156 @@
+// Package repository gives typed access to the TOML data files under
+// data/toml of a project, and overworlds.toml under data/assets/toml. Every
+// method returns an *Error instead of panicking, so a missing or malformed
+// file can be reported to the user.
+package repository
+
+import (
+	"strconv"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// PokemonRepository stores species in pokemon.toml, keyed by ID
+type PokemonRepository struct {
+	*table[Models.PokemonToml, Models.Pokemon]
+}
+
+func NewPokemonRepository(dataDirectory string) *PokemonRepository {
+	return &PokemonRepository{&table[Models.PokemonToml, Models.Pokemon]{
+		dataDirectory: dataDirectory,
+		file:          "pokemon.toml",
+		records:       func(d *Models.PokemonToml) *[]Models.Pokemon { return &d.Pokemon },
+		id:            func(r Models.Pokemon) string { return r.ID },
+	}}
+}
+
+// MoveRepository stores moves in moves.toml, keyed by their numeric ID
+type MoveRepository struct {
+	*table[Models.AllMoves, Models.Move]
+}
+
+func NewMoveRepository(dataDirectory string) *MoveRepository {
+	return &MoveRepository{&table[Models.AllMoves, Models.Move]{
+		dataDirectory: dataDirectory,
+		file:          "moves.toml",
+		records:       func(d *Models.AllMoves) *[]Models.Move { return &d.Move },
+		id:            func(r Models.Move) string { return strconv.Itoa(r.ID) },
+	}}
+}
+
+// TrainerRepository stores trainers in trainers.toml, keyed by ID
+type TrainerRepository struct {
+	*table[coreModels.TrainerToml, coreModels.Trainer]
+}
+
+func NewTrainerRepository(dataDirectory string) *TrainerRepository {
+	return &TrainerRepository{&table[coreModels.TrainerToml, coreModels.Trainer]{
+		dataDirectory: dataDirectory,
+		file:          "trainers.toml",
+		records:       func(d *coreModels.TrainerToml) *[]coreModels.Trainer { return &d.Trainers },
+		id:            func(r coreModels.Trainer) string { return r.ID },
+	}}
+}
+
+// TrainerClassRepository stores trainer classes in trainerclasses.toml, keyed
+// by name
+type TrainerClassRepository struct {
+	*table[coreModels.TrainerClassToml, coreModels.TrainerClass]
+}
+
+func NewTrainerClassRepository(dataDirectory string) *TrainerClassRepository {
+	return &TrainerClassRepository{&table[coreModels.TrainerClassToml, coreModels.TrainerClass]{
+		dataDirectory: dataDirectory,
+		file:          "trainerclasses.toml",
+		records:       func(d *coreModels.TrainerClassToml) *[]coreModels.TrainerClass { return &d.Data },
+		id:            func(r coreModels.TrainerClass) string { return r.Name },
+	}}
+}
+
+// HeldItemRepository stores held items in helditems.toml, keyed by name
+type HeldItemRepository struct {
+	*table[Models.HeldItemToml, Models.HeldItems]
+}
+
+func NewHeldItemRepository(dataDirectory string) *HeldItemRepository {
+	return &HeldItemRepository{&table[Models.HeldItemToml, Models.HeldItems]{
+		dataDirectory: dataDirectory,
+		file:          "helditems.toml",
+		records:       func(d *Models.HeldItemToml) *[]Models.HeldItems { return &d.HeldItems },
+		id:            func(r Models.HeldItems) string { return r.Name },
+	}}
+}
+
+// MapRepository stores map entries in maps.toml, keyed by their numeric ID
+type MapRepository struct {
+	*table[coreModels.MapEditerMapData, coreModels.Map]
+}
+
+func NewMapRepository(dataDirectory string) *MapRepository {
+	return &MapRepository{&table[coreModels.MapEditerMapData, coreModels.Map]{
+		dataDirectory: dataDirectory,
+		file:          "maps.toml",
+		records:       func(d *coreModels.MapEditerMapData) *[]coreModels.Map { return &d.Map },
+		id:            func(r coreModels.Map) string { return strconv.Itoa(r.ID) },
+	}}
+}
+
+// TilesetRepository stores tilesets in tilesets.toml, keyed by name
+type TilesetRepository struct {
+	*table[coreModels.TilesetData, coreModels.Tileset]
+}
+
+func NewTilesetRepository(dataDirectory string) *TilesetRepository {
+	return &TilesetRepository{&table[coreModels.TilesetData, coreModels.Tileset]{
+		dataDirectory: dataDirectory,
+		file:          "tilesets.toml",
+		records:       func(d *coreModels.TilesetData) *[]coreModels.Tileset { return &d.Tilesets },
+		id:            func(r coreModels.Tileset) string { return r.Name },
+	}}
+}
+
+// OverworldRepository stores overworld sprites in overworlds.toml, keyed by ID.
+// The file sits next to the overworld assets in data/assets/toml.
+type OverworldRepository struct {
+	*table[Models.OverworldsHolder, Models.Overworld]
+}
+
+func NewOverworldRepository(dataDirectory string) *OverworldRepository {
+	return &OverworldRepository{&table[Models.OverworldsHolder, Models.Overworld]{
+		dataDirectory: dataDirectory,
+		directory:     "data/assets/toml",
+		file:          "overworlds.toml",
+		records:       func(d *Models.OverworldsHolder) *[]Models.Overworld { return &d.Overworlds },
+		id:            func(r Models.Overworld) string { return r.ID },
+	}}
+}
+
+// TrainerAIRepository stores the resolved AI of every trainer in
+// trainerai.toml, keyed by trainer ID
+type TrainerAIRepository struct {
+	*table[coreModels.TrainerAIToml, coreModels.TrainerAI]
+}
+
+func NewTrainerAIRepository(dataDirectory string) *TrainerAIRepository {
+	return &TrainerAIRepository{&table[coreModels.TrainerAIToml, coreModels.TrainerAI]{
+		dataDirectory: dataDirectory,
+		file:          "trainerai.toml",
+		records:       func(d *coreModels.TrainerAIToml) *[]coreModels.TrainerAI { return &d.Trainers },
+		id:            func(r coreModels.TrainerAI) string { return r.ID },
+	}}
+}
+
+// TypeRepository stores type definitions in types.toml, keyed by name
+type TypeRepository struct {
+	*table[coreModels.TypesToml, coreModels.TypeDefinition]
+}
+
+func NewTypeRepository(dataDirectory string) *TypeRepository {
+	return &TypeRepository{&table[coreModels.TypesToml, coreModels.TypeDefinition]{
+		dataDirectory: dataDirectory,
+		file:          "types.toml",
+		records:       func(d *coreModels.TypesToml) *[]coreModels.TypeDefinition { return &d.Types },
+		id:            func(r coreModels.TypeDefinition) string { return r.Name },
+	}}
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// fileLocks serialises read-modify-write cycles on the same data file
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = make(map[string]*sync.Mutex)
+)
+
+func lockFile(path string) func() {
+	fileLocksMu.Lock()
+	lock, ok := fileLocks[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		fileLocks[path] = lock
+	}
+	fileLocksMu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// table is a TOML document D holding a list of records R keyed by a string ID
+type table[D any, R any] struct {
+	dataDirectory string
+	directory     string // folder of file in the project, data/toml when empty
+	file          string
+	records       func(*D) *[]R
+	id            func(R) string
+}
+
+// File returns the name of the data file, e.g. moves.toml
+func (t *table[D, R]) File() string {
+	return t.file
+}
+
+// Location returns the data file relative to the project, e.g.
+// data/toml/moves.toml
+func (t *table[D, R]) Location() string {
+	directory := t.directory
+	if directory == "" {
+		directory = "data/toml"
+	}
+	return fmt.Sprintf("%s/%s", directory, t.file)
+}
+
+// Path returns the location of the data file inside the project
+func (t *table[D, R]) Path() string {
+	return fmt.Sprintf("%s/%s", t.dataDirectory, t.Location())
+}
+
+// Load reads and parses the whole data file
+func (t *table[D, R]) Load() (D, error) {
+	var document D
+	b, err := os.ReadFile(t.Path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return document, &Error{Kind: ErrMissingFile, File: t.file, Err: err}
+	}
+	if err != nil {
+		return document, &Error{Kind: ErrMalformedFile, File: t.file, Err: err}
+	}
+	if err := toml.Unmarshal(b, &document); err != nil {
+		return document, &Error{Kind: ErrMalformedFile, File: t.file, Err: err}
+	}
+	return document, nil
+}
+
+// Decode parses the contents of the data file, for callers that already have
+// them in memory such as write hooks
+func (t *table[D, R]) Decode(data []byte) ([]R, error) {
+	var document D
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, &Error{Kind: ErrMalformedFile, File: t.file, Err: err}
+	}
+	return *t.records(&document), nil
+}
+
+// Save atomically replaces the data file with document
+func (t *table[D, R]) Save(document D) error {
+	data, err := toml.Marshal(document)
+	if err != nil {
+		return &Error{Kind: ErrWriteFailed, File: t.file, Err: err}
+	}
+	if err := WriteFile(t.Path(), data, 0644); err != nil {
+		return &Error{Kind: ErrWriteFailed, File: t.file, Err: err}
+	}
+	return nil
+}
+
+// All returns every record of the data file in file order
+func (t *table[D, R]) All() ([]R, error) {
+	document, err := t.Load()
+	if err != nil {
+		return nil, err
+	}
+	return *t.records(&document), nil
+}
+
+// Find returns the record with the given ID
+func (t *table[D, R]) Find(id string) (R, error) {
+	var record R
+	records, err := t.All()
+	if err != nil {
+		return record, err
+	}
+	for _, candidate := range records {
+		if t.id(candidate) == id {
+			return candidate, nil
+		}
+	}
+	return record, &Error{Kind: ErrNotFound, File: t.file, ID: id}
+}
+
+// Insert appends record, refusing IDs that are already taken. A missing data
+// file is created.
+func (t *table[D, R]) Insert(record R) error {
+	return t.update(true, func(records *[]R) error {
+		id := t.id(record)
+		for _, existing := range *records {
+			if t.id(existing) == id {
+				return &Error{Kind: ErrDuplicateID, File: t.file, ID: id}
+			}
+		}
+		*records = append(*records, record)
+		return nil
+	})
+}
+
+// Upsert replaces the record with the same ID or appends it when there is
+// none. A missing data file is created.
+func (t *table[D, R]) Upsert(record R) error {
+	return t.update(true, func(records *[]R) error {
+		id := t.id(record)
+		for index := range *records {
+			if t.id((*records)[index]) == id {
+				(*records)[index] = record
+				return nil
+			}
+		}
+		*records = append(*records, record)
+		return nil
+	})
+}
+
+// Replace overwrites an existing record, failing with ErrNotFound otherwise
+func (t *table[D, R]) Replace(record R) error {
+	return t.update(false, func(records *[]R) error {
+		id := t.id(record)
+		for index := range *records {
+			if t.id((*records)[index]) == id {
+				(*records)[index] = record
+				return nil
+			}
+		}
+		return &Error{Kind: ErrNotFound, File: t.file, ID: id}
+	})
+}
+
+// Modify applies change to the record with the given ID and saves the result
+func (t *table[D, R]) Modify(id string, change func(record *R) error) error {
+	return t.update(false, func(records *[]R) error {
+		for index := range *records {
+			if t.id((*records)[index]) == id {
+				return change(&(*records)[index])
+			}
+		}
+		return &Error{Kind: ErrNotFound, File: t.file, ID: id}
+	})
+}
+
+// Delete removes the record with the given ID
+func (t *table[D, R]) Delete(id string) error {
+	return t.update(false, func(records *[]R) error {
+		for index := range *records {
+			if t.id((*records)[index]) == id {
+				*records = append((*records)[:index], (*records)[index+1:]...)
+				return nil
+			}
+		}
+		return &Error{Kind: ErrNotFound, File: t.file, ID: id}
+	})
+}
+
+// Reorder rewrites the records in the order of ids, which has to name every
+// record exactly once
+func (t *table[D, R]) Reorder(ids []string) error {
+	return t.update(false, func(records *[]R) error {
+		byID := make(map[string]R, len(*records))
+		for _, record := range *records {
+			byID[t.id(record)] = record
+		}
+		if len(byID) != len(*records) || len(ids) != len(*records) {
+			return &Error{Kind: ErrInvalidOrder, File: t.file}
+		}
+		reordered := make([]R, 0, len(ids))
+		for _, id := range ids {
+			record, ok := byID[id]
+			if !ok {
+				return &Error{Kind: ErrInvalidOrder, File: t.file, ID: id}
+			}
+			delete(byID, id)
+			reordered = append(reordered, record)
+		}
+		*records = reordered
+		return nil
+	})
+}
+
+// Restore puts the record with the given ID back the way it is in data, an
+// earlier version of the data file, or deletes it when data does not have it
+func (t *table[D, R]) Restore(data []byte, id string) error {
+	records, err := t.Decode(data)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if t.id(record) == id {
+			return t.Upsert(record)
+		}
+	}
+	return t.Delete(id)
+}
+
+// Update loads the records, lets change edit them in place and saves the
+// result unless change returns an error. A missing data file is treated as
+// empty, a malformed one is never overwritten.
+func (t *table[D, R]) Update(change func(records *[]R) error) error {
+	return t.update(true, change)
+}
+
+func (t *table[D, R]) update(allowMissing bool, change func(records *[]R) error) error {
+	unlock := lockFile(t.Path())
+	defer unlock()
+
+	document, err := t.Load()
+	if err != nil && !(allowMissing && errors.Is(err, ErrMissingFile)) {
+		return err
+	}
+	if err := change(t.records(&document)); err != nil {
+		return err
+	}
+	return t.Save(document)
+}
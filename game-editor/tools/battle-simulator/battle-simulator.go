@@ -0,0 +1,301 @@
+package battlesimulator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// Limits of a simulation run
+const (
+	DefaultBattles = 100
+	MaxBattles     = 10000
+)
+
+type BattleSimulatorApp struct {
+	app *core.App
+}
+
+// NewBattleSimulatorApp creates a new BattleSimulatorApp struct
+func NewBattleSimulatorApp(app *core.App) *BattleSimulatorApp {
+	return &BattleSimulatorApp{
+		app: app,
+	}
+}
+
+// SimulationOptions describes a simulation against a trainer. The player's
+// party is either given as Party, whose stats are derived from their IVs (31
+// when not set), EVs and nature, or taken from another trainer with
+// PlayerTrainerID. Variant picks one of the trainer's party variants instead
+// of its base party. A zero Seed picks a random one.
+type SimulationOptions struct {
+	TrainerID       string                   `json:"trainerId"`
+	Variant         string                   `json:"variant"`
+	Party           []coreModels.PokemonJson `json:"party"`
+	PlayerTrainerID string                   `json:"playerTrainerId"`
+	Battles         int                      `json:"battles"`
+	Seed            int64                    `json:"seed"`
+}
+
+// SimulationReport sums up the simulated battles. WinRate is the player's
+// share of wins and the seed reproduces the run.
+type SimulationReport struct {
+	Trainer      string          `json:"trainer"`
+	Variant      string          `json:"variant"`
+	Battles      int             `json:"battles"`
+	PlayerWins   int             `json:"playerWins"`
+	TrainerWins  int             `json:"trainerWins"`
+	Draws        int             `json:"draws"`
+	WinRate      float64         `json:"winRate"`
+	AverageTurns float64         `json:"averageTurns"`
+	Player       []PokemonResult `json:"player"`
+	Opponent     []PokemonResult `json:"opponent"`
+	Seed         int64           `json:"seed"`
+	Warnings     []string        `json:"warnings"`
+}
+
+// PokemonResult is how a party pokemon did over every battle: the pokemon it
+// knocked out and how often it fainted
+type PokemonResult struct {
+	Slot    int    `json:"slot"`
+	Species string `json:"species"`
+	Level   int    `json:"level"`
+	KOs     int    `json:"kos"`
+	Fainted int    `json:"fainted"`
+}
+
+// SimulateBattles plays the player's party against a trainer's party the
+// given number of times. Both sides send their pokemon in party order and
+// use the mainline damage formula, type chart, accuracy, critical hits and
+// PP; the player always picks its strongest move and the trainer follows
+// its AI flags. Status moves and items have no effect.
+func (a *BattleSimulatorApp) SimulateBattles(options SimulationOptions) (SimulationReport, error) {
+	switch {
+	case options.Battles == 0:
+		options.Battles = DefaultBattles
+	case options.Battles < 0 || options.Battles > MaxBattles:
+		return SimulationReport{}, fmt.Errorf("between 1 and %d battles can be simulated, not %d", MaxBattles, options.Battles)
+	}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+
+	data, err := a.loadProject()
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	trainer, err := data.trainer(options.TrainerID)
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	report := SimulationReport{Trainer: trainer.Name, Variant: options.Variant, Battles: options.Battles, Seed: options.Seed, Warnings: []string{}}
+
+	opponentParty := trainer.Pokemons
+	if options.Variant != "" {
+		index := slices.IndexFunc(trainer.Variants, func(variant coreModels.PartyVariant) bool { return variant.Name == options.Variant })
+		if index == -1 {
+			return SimulationReport{}, fmt.Errorf("trainer %s has no party variant %s", trainer.Name, options.Variant)
+		}
+		opponentParty = trainer.Variants[index].Pokemons
+	}
+	opponent, err := data.trainerCombatants(opponentParty, trainer.Name, &report.Warnings)
+	if err != nil {
+		return SimulationReport{}, err
+	}
+
+	var player []combatant
+	switch {
+	case options.PlayerTrainerID != "":
+		playerTrainer, err := data.trainer(options.PlayerTrainerID)
+		if err != nil {
+			return SimulationReport{}, err
+		}
+		player, err = data.trainerCombatants(playerTrainer.Pokemons, "player", &report.Warnings)
+		if err != nil {
+			return SimulationReport{}, err
+		}
+	default:
+		player, err = data.playerCombatants(options.Party, &report.Warnings)
+		if err != nil {
+			return SimulationReport{}, err
+		}
+	}
+	if len(player) == 0 {
+		return SimulationReport{}, fmt.Errorf("the player's party is empty")
+	}
+	if len(opponent) == 0 {
+		return SimulationReport{}, fmt.Errorf("trainer %s has no pokemon to battle with", trainer.Name)
+	}
+
+	profile := core.ResolveAIProfile(data.classAI[trainer.ClassType], trainer.AI)
+	smart := slices.ContainsFunc(profile.Flags, func(flag string) bool {
+		return flag == "try-to-faint" || flag == "check-viability" || flag == "prefer-strongest-move"
+	})
+	checkBadMove := smart || slices.Contains(profile.Flags, "check-bad-move")
+
+	report.Player = results(player)
+	report.Opponent = results(opponent)
+	rng := rand.New(rand.NewSource(options.Seed))
+	turns := 0
+	for range options.Battles {
+		result := battle(rng, data.chart, newSide(player, true, true), newSide(opponent, smart, checkBadMove))
+		switch result.winner {
+		case playerWon:
+			report.PlayerWins++
+		case opponentWon:
+			report.TrainerWins++
+		default:
+			report.Draws++
+		}
+		turns += result.turns
+		for slot := range player {
+			report.Player[slot].KOs += result.playerKOs[slot]
+			report.Player[slot].Fainted += result.playerFaints[slot]
+		}
+		for slot := range opponent {
+			report.Opponent[slot].KOs += result.opponentKOs[slot]
+			report.Opponent[slot].Fainted += result.opponentFaints[slot]
+		}
+	}
+	report.WinRate = float64(report.PlayerWins) / float64(options.Battles)
+	report.AverageTurns = float64(turns) / float64(options.Battles)
+	return report, nil
+}
+
+func results(party []combatant) []PokemonResult {
+	result := make([]PokemonResult, 0, len(party))
+	for slot, pokemon := range party {
+		result = append(result, PokemonResult{Slot: slot + 1, Species: pokemon.species, Level: pokemon.level})
+	}
+	return result
+}
+
+// project is the data a simulation reads
+type project struct {
+	dataDirectory string
+	species       map[string]Models.Pokemon
+	moves         map[string]Models.Move
+	classAI       map[string]*coreModels.AIProfile
+	chart         core.TypeChart
+}
+
+func (a *BattleSimulatorApp) loadProject() (project, error) {
+	data := project{
+		dataDirectory: a.app.DataDirectory,
+		species:       make(map[string]Models.Pokemon),
+		moves:         make(map[string]Models.Move),
+		classAI:       make(map[string]*coreModels.AIProfile),
+	}
+	types, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return data, err
+	}
+	data.chart = core.NewTypeChart(types)
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return data, err
+	}
+	for _, pokemon := range species {
+		data.species[pokemon.ID] = pokemon
+	}
+	moves, err := repository.NewMoveRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return data, err
+	}
+	for _, move := range moves {
+		data.moves[strings.ToLower(move.Name)] = move
+	}
+	classes, err := repository.NewTrainerClassRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return data, err
+	}
+	for _, class := range classes {
+		data.classAI[class.Name] = class.AI
+	}
+	return data, nil
+}
+
+func (p project) trainer(id string) (coreModels.Trainer, error) {
+	return repository.NewTrainerRepository(p.dataDirectory).Find(id)
+}
+
+// trainerCombatants prepares a stored party, pokemon without stats get them
+// derived with perfect IVs
+func (p project) trainerCombatants(party []coreModels.TrainerPokemon, owner string, warnings *[]string) ([]combatant, error) {
+	var result []combatant
+	for _, pokemon := range party {
+		species, ok := p.species[pokemon.ID]
+		if !ok {
+			return nil, fmt.Errorf("%s's %s uses species %s, which does not exist", owner, pokemon.Species, pokemon.ID)
+		}
+		stats := coreModels.StatSpread{
+			HP:             pokemon.HP,
+			Attack:         pokemon.Attack,
+			Defense:        pokemon.Defense,
+			Speed:          pokemon.Speed,
+			SpecialAttack:  pokemon.SpecialAttack,
+			SpecialDefense: pokemon.SpecialDefense,
+		}
+		if stats.HP <= 0 {
+			var evs coreModels.StatSpread
+			if pokemon.EVs != nil {
+				evs = *pokemon.EVs
+			}
+			stats = core.CalculateStats(species.Stats, pokemon.Level, core.PerfectIVs, evs, pokemon.Nature)
+		}
+		result = append(result, p.combatant(species, pokemon.Level, stats, pokemon.Moves, owner, warnings))
+	}
+	return result, nil
+}
+
+// playerCombatants prepares the player's party from the editor's shape
+func (p project) playerCombatants(party []coreModels.PokemonJson, warnings *[]string) ([]combatant, error) {
+	var result []combatant
+	for _, pokemon := range party {
+		species, ok := p.species[pokemon.ID]
+		if !ok {
+			return nil, fmt.Errorf("the player's %s uses species %s, which does not exist", pokemon.Species, pokemon.ID)
+		}
+		if pokemon.Level < 1 || pokemon.Level > 100 {
+			return nil, fmt.Errorf("the player's %s is level %d, levels go from 1 to 100", species.Species, pokemon.Level)
+		}
+		ivs := core.PerfectIVs
+		if pokemon.IVs != nil {
+			ivs = *pokemon.IVs
+		}
+		var evs coreModels.StatSpread
+		if pokemon.EVs != nil {
+			evs = *pokemon.EVs
+		}
+		stats := core.CalculateStats(species.Stats, pokemon.Level, ivs, evs, pokemon.Nature)
+		result = append(result, p.combatant(species, pokemon.Level, stats, pokemon.Moves, "player", warnings))
+	}
+	return result, nil
+}
+
+func (p project) combatant(species Models.Pokemon, level int, stats coreModels.StatSpread, moves []string, owner string, warnings *[]string) combatant {
+	pokemon := combatant{species: species.Species, level: level, types: species.Types, stats: stats}
+	for _, name := range moves {
+		if name == "" {
+			continue
+		}
+		move, ok := p.moves[strings.ToLower(name)]
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("%s's %s: move %s does not exist and is not used", owner, species.Species, name))
+			continue
+		}
+		if move.Power <= 0 {
+			*warnings = append(*warnings, fmt.Sprintf("%s's %s: %s deals no damage, its effect is not simulated", owner, species.Species, move.Name))
+		}
+		pokemon.moves = append(pokemon.moves, move)
+	}
+	return pokemon
+}
@@ -0,0 +1,274 @@
+package battlesimulator
+
+import (
+	"math/rand"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// maxTurns ends a battle that neither side can win, e.g. when both only have
+// status moves left, as a draw
+const maxTurns = 500
+
+// criticalChance is the one in n chance of a critical hit
+const criticalChance = 24
+
+// struggle is used by a pokemon that has no PP left on any move, it hurts the
+// user by a quarter of its HP
+var struggle = Models.Move{Name: "Struggle", Power: 50, KindOfMove: "Physical"}
+
+// combatant is a party pokemon as it enters every battle
+type combatant struct {
+	species string
+	level   int
+	types   []string
+	stats   coreModels.StatSpread
+	moves   []Models.Move
+}
+
+// battler is a combatant during one battle
+type battler struct {
+	*combatant
+	slot int
+	hp   int
+	pp   []int
+}
+
+// side is one trainer's party during a battle. Smart sides pick the move
+// that deals the most damage, the others pick at random and avoid moves the
+// target is immune to when they check for bad moves.
+type side struct {
+	party        []*battler
+	active       int
+	smart        bool
+	checkBadMove bool
+}
+
+func newSide(party []combatant, smart bool, checkBadMove bool) *side {
+	s := &side{smart: smart, checkBadMove: checkBadMove}
+	for slot := range party {
+		b := &battler{combatant: &party[slot], slot: slot, hp: party[slot].stats.HP}
+		for _, move := range party[slot].moves {
+			b.pp = append(b.pp, move.Pp)
+		}
+		s.party = append(s.party, b)
+	}
+	return s
+}
+
+func (s *side) current() *battler {
+	return s.party[s.active]
+}
+
+// next sends in the next pokemon that can still battle, false when the side
+// has none left
+func (s *side) next() bool {
+	for slot := range s.party {
+		if s.party[slot].hp > 0 {
+			s.active = slot
+			return true
+		}
+	}
+	return false
+}
+
+// battleResult is what happened in one battle, KOs and faints are by party
+// slot
+type battleResult struct {
+	winner         int
+	turns          int
+	playerKOs      []int
+	playerFaints   []int
+	opponentKOs    []int
+	opponentFaints []int
+}
+
+// Winners of a battle
+const (
+	draw = iota
+	playerWon
+	opponentWon
+)
+
+// battle simulates a single battle between the player and the opponent
+func battle(rng *rand.Rand, chart core.TypeChart, player *side, opponent *side) battleResult {
+	result := battleResult{
+		playerKOs:      make([]int, len(player.party)),
+		playerFaints:   make([]int, len(player.party)),
+		opponentKOs:    make([]int, len(opponent.party)),
+		opponentFaints: make([]int, len(opponent.party)),
+	}
+	if !player.next() {
+		result.winner = opponentWon
+		return result
+	}
+	if !opponent.next() {
+		result.winner = playerWon
+		return result
+	}
+
+	// action is a pokemon using its move, with the tallies its KOs and
+	// faints go to
+	type action struct {
+		attacker       *battler
+		defenderSide   *side
+		move           int
+		kos            []int
+		attackerFaints []int
+		defenderFaints []int
+	}
+	for turn := 1; turn <= maxTurns; turn++ {
+		result.turns = turn
+		first := action{
+			attacker: player.current(), defenderSide: opponent,
+			kos: result.playerKOs, attackerFaints: result.playerFaints, defenderFaints: result.opponentFaints,
+		}
+		second := action{
+			attacker: opponent.current(), defenderSide: player,
+			kos: result.opponentKOs, attackerFaints: result.opponentFaints, defenderFaints: result.playerFaints,
+		}
+		first.move = player.choose(rng, chart, first.attacker, second.attacker)
+		second.move = opponent.choose(rng, chart, second.attacker, first.attacker)
+		playerSpeed, opponentSpeed := first.attacker.stats.Speed, second.attacker.stats.Speed
+		if opponentSpeed > playerSpeed || (opponentSpeed == playerSpeed && rng.Intn(2) == 0) {
+			first, second = second, first
+		}
+
+		for _, act := range []action{first, second} {
+			// a pokemon that fainted earlier this turn does not move
+			if act.attacker.hp <= 0 {
+				continue
+			}
+			defender := act.defenderSide.current()
+			recoil := use(rng, chart, act.attacker, act.move, defender)
+			if defender.hp <= 0 {
+				act.kos[act.attacker.slot]++
+				act.defenderFaints[defender.slot]++
+			}
+			if recoil && act.attacker.hp <= 0 {
+				act.attackerFaints[act.attacker.slot]++
+			}
+			playerLeft, opponentLeft := player.next(), opponent.next()
+			switch {
+			case !playerLeft && !opponentLeft:
+				result.winner = draw
+				return result
+			case !playerLeft:
+				result.winner = opponentWon
+				return result
+			case !opponentLeft:
+				result.winner = playerWon
+				return result
+			}
+			// the pokemon that were sent in only move next turn
+			if defender.hp <= 0 || act.attacker.hp <= 0 {
+				break
+			}
+		}
+	}
+	result.winner = draw
+	return result
+}
+
+// choose returns the index of the move attacker uses against defender, -1
+// for Struggle
+func (s *side) choose(rng *rand.Rand, chart core.TypeChart, attacker *battler, defender *battler) int {
+	var usable []int
+	for index := range attacker.moves {
+		if attacker.pp[index] > 0 {
+			usable = append(usable, index)
+		}
+	}
+	if len(usable) == 0 {
+		return -1
+	}
+	if s.smart {
+		best, bestDamage := -1, 0.0
+		for _, index := range usable {
+			if damage := expectedDamage(chart, attacker, attacker.moves[index], defender); damage > bestDamage {
+				best, bestDamage = index, damage
+			}
+		}
+		if best != -1 {
+			return best
+		}
+	}
+	if s.checkBadMove {
+		var good []int
+		for _, index := range usable {
+			move := attacker.moves[index]
+			if move.Power <= 0 || chart.Effectiveness(move.Type, defender.types) > 0 {
+				good = append(good, index)
+			}
+		}
+		if len(good) > 0 {
+			usable = good
+		}
+	}
+	return usable[rng.Intn(len(usable))]
+}
+
+// expectedDamage is a move's average damage against defender weighed by its
+// accuracy, without critical hits
+func expectedDamage(chart core.TypeChart, attacker *battler, move Models.Move, defender *battler) float64 {
+	rolls := core.DamageRolls(damageInput(chart, attacker.combatant, move, defender.combatant, false))
+	total := 0
+	for _, damage := range rolls {
+		total += min(damage, defender.hp)
+	}
+	return float64(total) / float64(len(rolls)) * float64(accuracy(move)) / 100
+}
+
+// use lets attacker use a move on defender, index -1 is Struggle. It reports
+// whether the attacker took recoil damage.
+func use(rng *rand.Rand, chart core.TypeChart, attacker *battler, index int, defender *battler) bool {
+	move := struggle
+	if index >= 0 {
+		move = attacker.moves[index]
+		attacker.pp[index]--
+	}
+	if rng.Intn(100) >= accuracy(move) {
+		return false
+	}
+	if move.Power > 0 {
+		critical := rng.Intn(criticalChance) == 0
+		roll := core.MinDamageRoll + rng.Intn(core.MaxDamageRoll-core.MinDamageRoll+1)
+		defender.hp -= min(core.Damage(damageInput(chart, attacker.combatant, move, defender.combatant, critical), roll), defender.hp)
+	}
+	if index >= 0 {
+		return false
+	}
+	attacker.hp -= min(max(attacker.stats.HP/4, 1), attacker.hp)
+	return true
+}
+
+func damageInput(chart core.TypeChart, attacker *combatant, move Models.Move, defender *combatant, critical bool) core.DamageInput {
+	input := core.DamageInput{
+		Level:         attacker.level,
+		Power:         move.Power,
+		Attack:        attacker.stats.Attack,
+		Defense:       defender.stats.Defense,
+		Effectiveness: chart.Effectiveness(move.Type, defender.types),
+		Critical:      critical,
+	}
+	if strings.EqualFold(move.KindOfMove, "special") {
+		input.Attack = attacker.stats.SpecialAttack
+		input.Defense = defender.stats.SpecialDefense
+	}
+	for _, attackerType := range attacker.types {
+		input.STAB = input.STAB || (move.Type != "" && strings.EqualFold(attackerType, move.Type))
+	}
+	return input
+}
+
+// accuracy is the chance in percent that a move hits, moves without an
+// accuracy never miss
+func accuracy(move Models.Move) int {
+	if move.Accuracy <= 0 || move.Accuracy > 100 {
+		return 100
+	}
+	return move.Accuracy
+}
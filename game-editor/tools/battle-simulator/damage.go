@@ -0,0 +1,170 @@
+package battlesimulator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+// DamagePokemon is the attacker or the defender of a damage calculation. Its
+// stats are derived from its IVs (31 when not set), EVs and nature unless
+// Stats gives them, e.g. for a trainer pokemon with hand typed stats. Stage
+// is the stat stage of the stat the move attacks with or hits.
+type DamagePokemon struct {
+	ID     string                 `json:"id"`
+	Level  int                    `json:"level"`
+	IVs    *coreModels.StatSpread `json:"ivs"`
+	EVs    *coreModels.StatSpread `json:"evs"`
+	Nature string                 `json:"nature"`
+	Stats  *coreModels.StatSpread `json:"stats"`
+	Stage  int                    `json:"stage"`
+}
+
+// DamageCalculation is one hit of a move. Weather is one of core.Weathers or
+// empty for clear weather.
+type DamageCalculation struct {
+	Attacker DamagePokemon `json:"attacker"`
+	Defender DamagePokemon `json:"defender"`
+	Move     string        `json:"move"`
+	Weather  string        `json:"weather"`
+	Critical bool          `json:"critical"`
+}
+
+// DamageResult is the damage of every random factor, from the lowest roll to
+// the highest, and its share of the defender's HP. MinHits and MaxHits are the
+// hits the highest and the lowest rolls need to knock the defender out, KO
+// sums them up as e.g. "2HKO" or "2HKO to 3HKO".
+type DamageResult struct {
+	Attacker      string  `json:"attacker"`
+	Defender      string  `json:"defender"`
+	Move          string  `json:"move"`
+	Rolls         []int   `json:"rolls"`
+	Min           int     `json:"min"`
+	Max           int     `json:"max"`
+	DefenderHP    int     `json:"defenderHp"`
+	MinPercent    float64 `json:"minPercent"`
+	MaxPercent    float64 `json:"maxPercent"`
+	Effectiveness float64 `json:"effectiveness"`
+	Accuracy      int     `json:"accuracy"`
+	MinHits       int     `json:"minHits"`
+	MaxHits       int     `json:"maxHits"`
+	KO            string  `json:"ko"`
+}
+
+// GetWeathers lists the weathers a damage calculation accepts
+func (a *BattleSimulatorApp) GetWeathers() []string {
+	return core.Weathers
+}
+
+// CalculateDamage returns the damage a move of moves.toml deals with the
+// mainline formula, using the species' base stats and types of pokemon.toml
+// and the project's type chart. Critical hits ignore the attacker's lowered
+// and the defender's raised stages.
+func (a *BattleSimulatorApp) CalculateDamage(calculation DamageCalculation) (DamageResult, error) {
+	if calculation.Weather != "" && !slices.Contains(core.Weathers, calculation.Weather) {
+		return DamageResult{}, fmt.Errorf("unknown weather %s, expected one of %s", calculation.Weather, strings.Join(core.Weathers, ", "))
+	}
+	data, err := a.loadProject()
+	if err != nil {
+		return DamageResult{}, err
+	}
+	move, ok := data.moves[strings.ToLower(calculation.Move)]
+	if !ok {
+		return DamageResult{}, fmt.Errorf("move %s does not exist", calculation.Move)
+	}
+	if move.Power <= 0 {
+		return DamageResult{}, fmt.Errorf("%s deals no damage", move.Name)
+	}
+	attacker, err := data.damagePokemon(calculation.Attacker, "attacker")
+	if err != nil {
+		return DamageResult{}, err
+	}
+	defender, err := data.damagePokemon(calculation.Defender, "defender")
+	if err != nil {
+		return DamageResult{}, err
+	}
+
+	special := strings.EqualFold(move.KindOfMove, "special")
+	attackStage, defenseStage := calculation.Attacker.Stage, calculation.Defender.Stage
+	if calculation.Critical {
+		attackStage, defenseStage = max(attackStage, 0), min(defenseStage, 0)
+	}
+	if special {
+		attacker.stats.SpecialAttack = core.StatStage(attacker.stats.SpecialAttack, attackStage)
+		defender.stats.SpecialDefense = core.StatStage(defender.stats.SpecialDefense, defenseStage)
+		defender.stats.SpecialDefense = int(float64(defender.stats.SpecialDefense) * core.WeatherDefense(calculation.Weather, defender.types, true))
+	} else {
+		attacker.stats.Attack = core.StatStage(attacker.stats.Attack, attackStage)
+		defender.stats.Defense = core.StatStage(defender.stats.Defense, defenseStage)
+		defender.stats.Defense = int(float64(defender.stats.Defense) * core.WeatherDefense(calculation.Weather, defender.types, false))
+	}
+	input := damageInput(data.chart, &attacker, move, &defender, calculation.Critical)
+	input.Weather = core.WeatherModifier(calculation.Weather, move.Type)
+
+	rolls := core.DamageRolls(input)
+	result := DamageResult{
+		Attacker:      attacker.species,
+		Defender:      defender.species,
+		Move:          move.Name,
+		Rolls:         rolls,
+		Min:           rolls[0],
+		Max:           rolls[len(rolls)-1],
+		DefenderHP:    defender.stats.HP,
+		Effectiveness: input.Effectiveness,
+		Accuracy:      accuracy(move),
+	}
+	if defender.stats.HP > 0 {
+		result.MinPercent = float64(result.Min) * 100 / float64(defender.stats.HP)
+		result.MaxPercent = float64(result.Max) * 100 / float64(defender.stats.HP)
+	}
+	if result.Min > 0 {
+		result.MinHits = hitsToKO(defender.stats.HP, result.Max)
+		result.MaxHits = hitsToKO(defender.stats.HP, result.Min)
+		result.KO = koName(result.MinHits)
+		if result.MaxHits != result.MinHits {
+			result.KO += " to " + koName(result.MaxHits)
+		}
+	}
+	return result, nil
+}
+
+// damagePokemon prepares the attacker or the defender of a calculation
+func (p project) damagePokemon(pokemon DamagePokemon, role string) (combatant, error) {
+	species, ok := p.species[pokemon.ID]
+	if !ok {
+		return combatant{}, fmt.Errorf("the %s's species %s does not exist", role, pokemon.ID)
+	}
+	if pokemon.Level < 1 || pokemon.Level > 100 {
+		return combatant{}, fmt.Errorf("the %s is level %d, levels go from 1 to 100", role, pokemon.Level)
+	}
+	if pokemon.Stage < -core.MaxStatStage || pokemon.Stage > core.MaxStatStage {
+		return combatant{}, fmt.Errorf("the %s's stat stage is %d, stages go from %d to %d", role, pokemon.Stage, -core.MaxStatStage, core.MaxStatStage)
+	}
+	if pokemon.Stats != nil && pokemon.Stats.HP > 0 {
+		return combatant{species: species.Species, level: pokemon.Level, types: species.Types, stats: *pokemon.Stats}, nil
+	}
+	ivs := core.PerfectIVs
+	if pokemon.IVs != nil {
+		ivs = *pokemon.IVs
+	}
+	var evs coreModels.StatSpread
+	if pokemon.EVs != nil {
+		evs = *pokemon.EVs
+	}
+	stats := core.CalculateStats(species.Stats, pokemon.Level, ivs, evs, pokemon.Nature)
+	return combatant{species: species.Species, level: pokemon.Level, types: species.Types, stats: stats}, nil
+}
+
+func hitsToKO(hp int, damage int) int {
+	return (hp + damage - 1) / damage
+}
+
+func koName(hits int) string {
+	if hits == 1 {
+		return "OHKO"
+	}
+	return fmt.Sprintf("%dHKO", hits)
+}
@@ -0,0 +1,42 @@
+module github.com/zenith110/pokemon-engine-tools/tools/battle-simulator
+
+replace github.com/zenith110/pokemon-engine-tools/models => ../../models
+
+replace github.com/zenith110/pokemon-engine-tools/tools-core => ../../core
+
+replace github.com/zenith110/pokemon-engine-tools/repository => ../../repository
+
+go 1.22.2
+
+require (
+	github.com/zenith110/pokemon-engine-tools/models v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/repository v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-engine-tools/tools-core v0.0.0-00010101000000-000000000000
+	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8
+)
+
+require (
+	github.com/Microsoft/go-winio v0.6.1 // indirect
+	github.com/emirpasic/gods v1.18.1 // indirect
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
+	github.com/kevinburke/ssh_config v1.2.0 // indirect
+	github.com/leaanthony/slicer v1.6.0 // indirect
+	github.com/leaanthony/u v1.1.1 // indirect
+	github.com/mitchellh/go-homedir v1.1.0 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
+	github.com/sergi/go-diff v1.3.2-0.20230802210424-5b0b94c5c0d3 // indirect
+	github.com/src-d/gcfg v1.4.0 // indirect
+	github.com/wailsapp/wails/v2 v2.10.1 // indirect
+	github.com/xanzy/ssh-agent v0.3.3 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/net v0.35.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/tools v0.30.0 // indirect
+	gopkg.in/src-d/go-billy.v4 v4.3.2 // indirect
+	gopkg.in/src-d/go-git.v4 v4.13.1 // indirect
+	gopkg.in/warnings.v0 v0.1.2 // indirect
+)
@@ -0,0 +1,614 @@
+package decompimporter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The decomp data files are C, but only a small part of it: constant
+// definitions, enums and static arrays built from designated initializers.
+// This is just enough of a reader for those, it does not run the
+// preprocessor, so both sides of an #if are read.
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// tokenize splits C source into tokens, dropping comments and preprocessor
+// lines. Object-like #defines are returned separately.
+func tokenize(source string) ([]token, map[string][]token) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	var tokens []token
+	defines := make(map[string][]token)
+	line := 1
+	atLineStart := true
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			atLineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+			continue
+		case c == '/' && i+1 < len(source) && source[i+1] == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end == -1 {
+				end = len(source) - i - 2
+			}
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += end + 4
+			continue
+		case c == '#' && atLineStart:
+			start := i
+			for i < len(source) && (source[i] != '\n' || source[i-1] == '\\') {
+				if source[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			directive := strings.ReplaceAll(source[start:i], "\\\n", " ")
+			if name, value, ok := parseDefine(directive); ok {
+				valueTokens, _ := tokenize(value)
+				defines[name] = valueTokens
+			}
+			continue
+		}
+		atLineStart = false
+		start := i
+		switch {
+		case isIdentStart(c):
+			for i < len(source) && isIdentPart(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, source[start:i], line})
+		case c >= '0' && c <= '9':
+			for i < len(source) && isIdentPart(source[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, source[start:i], line})
+		case c == '"' || c == '\'':
+			i++
+			for i < len(source) && source[i] != c && source[i] != '\n' {
+				if source[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			if i > len(source) {
+				i = len(source)
+			}
+			kind := tokenString
+			if c == '\'' {
+				kind = tokenNumber
+			}
+			tokens = append(tokens, token{kind, source[start:i], line})
+		default:
+			i++
+			tokens = append(tokens, token{tokenPunct, string(c), line})
+		}
+	}
+	return tokens, defines
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// parseDefine reads an object-like #define, function-like macros are left
+// alone
+func parseDefine(directive string) (string, string, bool) {
+	rest := strings.TrimSpace(strings.TrimPrefix(directive, "#"))
+	if !strings.HasPrefix(rest, "define") {
+		return "", "", false
+	}
+	rest = strings.TrimLeft(rest[len("define"):], " \t")
+	end := 0
+	for end < len(rest) && isIdentPart(rest[end]) {
+		end++
+	}
+	if end == 0 || (end < len(rest) && rest[end] == '(') {
+		return "", "", false
+	}
+	return rest[:end], strings.TrimSpace(rest[end:]), true
+}
+
+// cValue is one initializer: either a braced list, whose entries may be
+// designated by .field or [index], or a plain expression
+type cValue struct {
+	entries []cEntry
+	braced  bool
+	expr    []token
+}
+
+// cEntry is one entry of a braced list. Field is set for .field = value,
+// index for [INDEX] = value.
+type cEntry struct {
+	field string
+	index []token
+	value *cValue
+}
+
+// field returns the value designated .name, nil when there is none
+func (v *cValue) field(name string) *cValue {
+	if v == nil {
+		return nil
+	}
+	for _, entry := range v.entries {
+		if entry.field == name {
+			return entry.value
+		}
+	}
+	return nil
+}
+
+// items returns the values of a braced list in order
+func (v *cValue) items() []*cValue {
+	if v == nil {
+		return nil
+	}
+	items := make([]*cValue, 0, len(v.entries))
+	for _, entry := range v.entries {
+		items = append(items, entry.value)
+	}
+	return items
+}
+
+// ident returns the identifier a value is made of, "" for anything else
+func (v *cValue) ident() string {
+	if v == nil || v.braced || len(v.expr) != 1 || v.expr[0].kind != tokenIdent {
+		return ""
+	}
+	return v.expr[0].text
+}
+
+// call splits NAME(arg, arg) into its name and arguments
+func (v *cValue) call() (string, []*cValue, bool) {
+	if v == nil || v.braced || len(v.expr) < 3 || v.expr[0].kind != tokenIdent || v.expr[1].text != "(" || v.expr[len(v.expr)-1].text != ")" {
+		return "", nil, false
+	}
+	var args []*cValue
+	for _, arg := range splitTopLevel(v.expr[2 : len(v.expr)-1]) {
+		p := &parser{tokens: arg}
+		args = append(args, p.value())
+	}
+	return v.expr[0].text, args, true
+}
+
+// text returns the string literals of a value joined together, looking
+// through wrappers like _("...") and COMPOUND_STRING("...")
+func (v *cValue) text() (string, bool) {
+	if v == nil || v.braced {
+		return "", false
+	}
+	var text strings.Builder
+	found := false
+	for _, t := range v.expr {
+		if t.kind == tokenString {
+			text.WriteString(unquote(t.text))
+			found = true
+		}
+	}
+	return text.String(), found
+}
+
+// identifiers lists every identifier used anywhere in a value
+func (v *cValue) identifiers() []string {
+	if v == nil {
+		return nil
+	}
+	var names []string
+	for _, t := range v.expr {
+		if t.kind == tokenIdent {
+			names = append(names, t.text)
+		}
+	}
+	for _, entry := range v.entries {
+		names = append(names, entry.value.identifiers()...)
+	}
+	return names
+}
+
+// unquote turns a C string literal into text. The games break lines and
+// pages with \n, \l and \p, all of which become spaces.
+func unquote(literal string) string {
+	literal = strings.TrimSuffix(strings.TrimPrefix(literal, "\""), "\"")
+	var text strings.Builder
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' || i+1 == len(literal) {
+			text.WriteByte(literal[i])
+			continue
+		}
+		i++
+		switch literal[i] {
+		case 'n', 'l', 'p':
+			text.WriteByte(' ')
+		default:
+			text.WriteByte(literal[i])
+		}
+	}
+	return text.String()
+}
+
+// splitTopLevel splits tokens on the commas that are not nested in
+// parentheses, brackets or braces
+func splitTopLevel(tokens []token) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case ",":
+			if depth == 0 && t.kind == tokenPunct {
+				parts = append(parts, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
+
+// declaration is a top level variable with an initializer
+type declaration struct {
+	name  string
+	value *cValue
+	file  string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenPunct}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// declarations reads the top level of a file, keeping variables with an
+// initializer and the constants of enums
+func (p *parser) declarations(file string, enums map[string][]token) []declaration {
+	var declarations []declaration
+	var statement []token
+	for !p.done() {
+		t := p.peek()
+		switch {
+		case t.text == "enum" && t.kind == tokenIdent:
+			p.pos++
+			if p.peek().kind == tokenIdent {
+				p.pos++
+			}
+			if p.peek().text == "{" {
+				p.enum(enums)
+			}
+			statement = nil
+		case t.text == "=" && t.kind == tokenPunct:
+			p.pos++
+			name := declaredName(statement)
+			value := p.value()
+			if p.peek().text == "=" {
+				// Both sides of an #if declared the variable, the later one wins
+				statement = value.expr
+				continue
+			}
+			if name != "" {
+				declarations = append(declarations, declaration{name: name, value: value, file: file})
+			}
+			statement = nil
+		case t.text == "{" && t.kind == tokenPunct:
+			// A function body or struct definition, neither holds data
+			p.skipBlock()
+			statement = nil
+		case t.text == ";" && t.kind == tokenPunct:
+			p.pos++
+			statement = nil
+		default:
+			statement = append(statement, t)
+			p.pos++
+		}
+	}
+	return declarations
+}
+
+// declaredName finds the variable in e.g. static const u16 sName[] or
+// const struct Trainer gTrainers[TRAINERS_COUNT]
+func declaredName(statement []token) string {
+	depth := 0
+	for i := len(statement) - 1; i >= 0; i-- {
+		switch statement[i].text {
+		case "]":
+			depth++
+		case "[":
+			depth--
+		default:
+			if depth == 0 && statement[i].kind == tokenIdent {
+				return statement[i].text
+			}
+		}
+	}
+	return ""
+}
+
+// enum reads enum { A, B = 5, C } as constants, each value given as tokens
+// so it can be evaluated once every constant is known
+func (p *parser) enum(enums map[string][]token) {
+	start := p.pos + 1
+	p.skipBlock()
+	body := p.tokens[start : p.pos-1]
+	previous := []token{{kind: tokenNumber, text: "-1"}}
+	for _, member := range splitTopLevel(body) {
+		if len(member) == 0 || member[0].kind != tokenIdent {
+			continue
+		}
+		value := append(append([]token{{kind: tokenPunct, text: "("}}, previous...), token{kind: tokenPunct, text: ")"}, token{kind: tokenPunct, text: "+"}, token{kind: tokenNumber, text: "1"})
+		if len(member) > 2 && member[1].text == "=" {
+			value = member[2:]
+		}
+		enums[member[0].text] = value
+		previous = []token{{kind: tokenIdent, text: member[0].text}}
+	}
+}
+
+func (p *parser) skipBlock() {
+	depth := 0
+	for !p.done() {
+		switch p.peek().text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		p.pos++
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// value reads one initializer
+func (p *parser) value() *cValue {
+	if p.peek().text == "{" {
+		return p.list()
+	}
+	value := &cValue{}
+	depth := 0
+	for !p.done() {
+		t := p.peek()
+		if t.kind == tokenPunct {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth == 0 {
+					return value
+				}
+				depth--
+			case ",", ";", "=":
+				if depth == 0 {
+					return value
+				}
+			}
+		}
+		value.expr = append(value.expr, t)
+		p.pos++
+	}
+	return value
+}
+
+// list reads a braced initializer list
+func (p *parser) list() *cValue {
+	value := &cValue{braced: true}
+	p.pos++
+	for !p.done() {
+		t := p.peek()
+		if t.text == "}" {
+			p.pos++
+			return value
+		}
+		if t.text == "," {
+			p.pos++
+			continue
+		}
+		var entry cEntry
+		switch {
+		case t.text == "." && p.pos+2 < len(p.tokens) && p.tokens[p.pos+2].text == "=":
+			entry.field = p.tokens[p.pos+1].text
+			p.pos += 3
+		case t.text == "[":
+			end := p.pos + 1
+			for depth := 1; end < len(p.tokens); end++ {
+				if p.tokens[end].text == "[" {
+					depth++
+				}
+				if p.tokens[end].text == "]" {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			if end+1 < len(p.tokens) && p.tokens[end+1].text == "=" {
+				entry.index = p.tokens[p.pos+1 : end]
+				p.pos = end + 2
+			}
+		}
+		entry.value = p.value()
+		value.entries = append(value.entries, entry)
+		if p.pos < len(p.tokens) && p.peek().text != "," && p.peek().text != "}" {
+			// Not an initializer we understand, skip the token to make progress
+			p.pos++
+		}
+	}
+	return value
+}
+
+// indexName returns the constant of an [INDEX] designator, e.g. MOVE_POUND
+// for [MOVE_POUND - 1]
+func indexName(index []token) string {
+	for _, t := range index {
+		if t.kind == tokenIdent {
+			return t.text
+		}
+	}
+	return ""
+}
+
+// constants evaluates #defines and enum members that are integers
+type constants struct {
+	definitions map[string][]token
+	values      map[string]int
+	evaluating  map[string]bool
+}
+
+func newConstants() *constants {
+	return &constants{definitions: make(map[string][]token), values: make(map[string]int), evaluating: make(map[string]bool)}
+}
+
+// value returns the integer value of a constant
+func (c *constants) value(name string) (int, bool) {
+	if value, ok := c.values[name]; ok {
+		return value, true
+	}
+	definition, ok := c.definitions[name]
+	if !ok || c.evaluating[name] {
+		return 0, false
+	}
+	c.evaluating[name] = true
+	defer delete(c.evaluating, name)
+	value, ok := c.eval(definition)
+	if ok {
+		c.values[name] = value
+	}
+	return value, ok
+}
+
+// eval computes simple integer expressions: numbers, constants, + - * << and
+// parentheses, as used by the constant headers
+func (c *constants) eval(tokens []token) (int, bool) {
+	e := &evaluator{tokens: tokens, constants: c}
+	value, ok := e.sum()
+	return value, ok && e.pos == len(e.tokens)
+}
+
+type evaluator struct {
+	tokens    []token
+	pos       int
+	constants *constants
+}
+
+func (e *evaluator) sum() (int, bool) {
+	left, ok := e.product()
+	for ok && e.pos < len(e.tokens) {
+		switch e.tokens[e.pos].text {
+		case "+", "-":
+			operator := e.tokens[e.pos].text
+			e.pos++
+			var right int
+			if right, ok = e.product(); operator == "+" {
+				left += right
+			} else {
+				left -= right
+			}
+		case "<":
+			if e.pos+1 < len(e.tokens) && e.tokens[e.pos+1].text == "<" {
+				e.pos += 2
+				var right int
+				right, ok = e.product()
+				left <<= right
+				continue
+			}
+			return left, ok
+		default:
+			return left, ok
+		}
+	}
+	return left, ok
+}
+
+func (e *evaluator) product() (int, bool) {
+	left, ok := e.operand()
+	for ok && e.pos < len(e.tokens) && e.tokens[e.pos].text == "*" {
+		e.pos++
+		var right int
+		right, ok = e.operand()
+		left *= right
+	}
+	return left, ok
+}
+
+func (e *evaluator) operand() (int, bool) {
+	if e.pos >= len(e.tokens) {
+		return 0, false
+	}
+	t := e.tokens[e.pos]
+	e.pos++
+	switch {
+	case t.text == "(":
+		value, ok := e.sum()
+		if !ok || e.pos >= len(e.tokens) || e.tokens[e.pos].text != ")" {
+			return 0, false
+		}
+		e.pos++
+		return value, true
+	case t.text == "-":
+		value, ok := e.operand()
+		return -value, ok
+	case t.kind == tokenNumber:
+		return parseNumber(t.text)
+	case t.kind == tokenIdent:
+		return e.constants.value(t.text)
+	}
+	return 0, false
+}
+
+func parseNumber(text string) (int, bool) {
+	text = strings.TrimRight(text, "uUlL")
+	value, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// number evaluates a value as an integer
+func (c *constants) number(value *cValue) (int, bool) {
+	if value == nil || value.braced {
+		return 0, false
+	}
+	return c.eval(value.expr)
+}
@@ -0,0 +1,198 @@
+package decompimporter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// tokenTexts writes tokens as line:text so tests can compare where each one
+// was read along with what it is
+func tokenTexts(tokens []token) []string {
+	texts := []string{}
+	for _, t := range tokens {
+		texts = append(texts, fmt.Sprintf("%d:%s", t.line, t.text))
+	}
+	return texts
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		defines map[string][]string
+	}{
+		{
+			name:   "identifiers, numbers and punctuation",
+			source: "const u16 sMoves[] = {MOVE_POUND, 0x1F};",
+			want:   []string{"1:const", "1:u16", "1:sMoves", "1:[", "1:]", "1:=", "1:{", "1:MOVE_POUND", "1:,", "1:0x1F", "1:}", "1:;"},
+		},
+		{
+			name:   "line comments",
+			source: "a // b c\nd",
+			want:   []string{"1:a", "2:d"},
+		},
+		{
+			name:   "block comments keep the line count",
+			source: "a /* b\nc\n*/ d\ne",
+			want:   []string{"1:a", "3:d", "4:e"},
+		},
+		{
+			name:   "block comment on one line",
+			source: "a /* b */ c",
+			want:   []string{"1:a", "1:c"},
+		},
+		{
+			name:   "unterminated block comment",
+			source: "a /* b\nc",
+			want:   []string{"1:a"},
+		},
+		{
+			name:   "comment markers inside strings",
+			source: "\"// not a comment\" '/'",
+			want:   []string{"1:\"// not a comment\"", "1:'/'"},
+		},
+		{
+			name:   "escaped quotes",
+			source: `_("say \"hi\"") x`,
+			want:   []string{`1:_`, `1:(`, `1:"say \"hi\""`, `1:)`, `1:x`},
+		},
+		{
+			name:   "windows line endings",
+			source: "a\r\n// b\r\nc",
+			want:   []string{"1:a", "3:c"},
+		},
+		{
+			name:    "defines",
+			source:  "#define MOVE_POUND 1\n  #define TWICE(x) ((x) * 2)\n#include \"global.h\"\nMOVE_POUND",
+			want:    []string{"4:MOVE_POUND"},
+			defines: map[string][]string{"MOVE_POUND": {"1:1"}},
+		},
+		{
+			name:    "continued defines keep the line count",
+			source:  "#define FLAGS \\\n    (1 << 2) \\\n    | 3\nx",
+			want:    []string{"4:x"},
+			defines: map[string][]string{"FLAGS": {"1:(", "1:1", "1:<", "1:<", "1:2", "1:)", "1:|", "1:3"}},
+		},
+		{
+			name:   "hashes inside a line are not directives",
+			source: "a # b",
+			want:   []string{"1:a", "1:#", "1:b"},
+		},
+		{
+			name:    "directives after a comment",
+			source:  "/* header */ #define A 2\nA",
+			want:    []string{"2:A"},
+			defines: map[string][]string{"A": {"1:2"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, defines := tokenize(test.source)
+			if got := tokenTexts(tokens); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("tokens are %v, want %v", got, test.want)
+			}
+			gotDefines := map[string][]string{}
+			for name, value := range defines {
+				gotDefines[name] = tokenTexts(value)
+			}
+			if test.defines == nil {
+				test.defines = map[string][]string{}
+			}
+			if !reflect.DeepEqual(gotDefines, test.defines) {
+				t.Errorf("defines are %v, want %v", gotDefines, test.defines)
+			}
+		})
+	}
+}
+
+func TestConstants(t *testing.T) {
+	source := "#define BASE 10\n" +
+		"#define NEXT (BASE + 1)\n" +
+		"#define SHIFTED (1 << 3)\n" +
+		"#define SCALED NEXT * 2 - 1\n" +
+		"#define LOOP LOOP\n" +
+		"#define NAME gText\n" +
+		"enum { A, B = BASE * 3, C, D = -1 };\n"
+	tokens, defines := tokenize(source)
+	c := newConstants()
+	c.definitions = defines
+	p := &parser{tokens: tokens}
+	p.declarations("constants.h", c.definitions)
+
+	tests := []struct {
+		name string
+		want int
+		ok   bool
+	}{
+		{"BASE", 10, true},
+		{"NEXT", 11, true},
+		{"SHIFTED", 8, true},
+		{"SCALED", 21, true},
+		{"A", 0, true},
+		{"B", 30, true},
+		{"C", 31, true},
+		{"D", -1, true},
+		{"LOOP", 0, false},
+		{"NAME", 0, false},
+		{"MISSING", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := c.value(test.name)
+		if ok != test.ok || (ok && got != test.want) {
+			t.Errorf("%s = %d, %v, want %d, %v", test.name, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestDeclarations(t *testing.T) {
+	source := "static const u8 sName[] = _(\"POUND\");\n" +
+		"void Function(void) { int x = 1; }\n" +
+		"const struct Move gMoves[MOVES_COUNT] = {\n" +
+		"    [MOVE_POUND - 1] = { .power = 40, .type = TYPE_NORMAL, },\n" +
+		"    [MOVE_EMBER] = { .power = 40, .flags = FLAG_A | FLAG_B },\n" +
+		"};\n"
+	tokens, _ := tokenize(source)
+	p := &parser{tokens: tokens}
+	declarations := p.declarations("moves.h", map[string][]token{})
+	if len(declarations) != 2 {
+		t.Fatalf("got %d declarations, want 2", len(declarations))
+	}
+	if text, ok := declarations[0].value.text(); declarations[0].name != "sName" || !ok || text != "POUND" {
+		t.Errorf("first declaration is %s = %q", declarations[0].name, text)
+	}
+	moves := declarations[1]
+	if moves.name != "gMoves" || len(moves.value.items()) != 2 {
+		t.Fatalf("second declaration is %s with %d items", moves.name, len(moves.value.items()))
+	}
+	pound, ember := moves.value.entries[0], moves.value.entries[1]
+	if indexName(pound.index) != "MOVE_POUND" || indexName(ember.index) != "MOVE_EMBER" {
+		t.Errorf("indices are %s and %s", indexName(pound.index), indexName(ember.index))
+	}
+	if pound.value.field("type").ident() != "TYPE_NORMAL" || pound.value.field("missing") != nil {
+		t.Errorf("pound's type is %q", pound.value.field("type").ident())
+	}
+	if got := ember.value.field("flags").identifiers(); !reflect.DeepEqual(got, []string{"FLAG_A", "FLAG_B"}) {
+		t.Errorf("ember's flags are %v", got)
+	}
+	if power, ok := newConstants().number(ember.value.field("power")); !ok || power != 40 {
+		t.Errorf("ember's power is %d, %v", power, ok)
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := map[string]string{
+		`"POUND"`:                 "POUND",
+		`"A strong\nblow.\pNext"`: "A strong blow. Next",
+		`"Keeps \"quotes\""`:      `Keeps "quotes"`,
+		`"Scroll\lline"`:          "Scroll line",
+		`"Trailing\"`:             `Trailing\`,
+		`"Other \t escapes kept"`: "Other t escapes kept",
+	}
+	for literal, want := range tests {
+		if got := unquote(literal); got != want {
+			t.Errorf("unquote(%s) = %q, want %q", literal, got, want)
+		}
+	}
+}
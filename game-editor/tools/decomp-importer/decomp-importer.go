@@ -0,0 +1,1023 @@
+package decompimporter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	"github.com/zenith110/pokemon-engine-tools/tools-core/dialogs"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// decompSources are the files read from a pokeemerald or pokefirered
+// checkout, relative to its root. Each is optional except the species
+// constants, later versions split some of them into directories.
+var decompSources = []string{
+	"include/constants/species.h",
+	"include/constants/pokedex.h",
+	"include/constants/moves.h",
+	"include/constants/opponents.h",
+	"src/data/pokemon/species_info.h",
+	"src/data/pokemon/species_info/*.h",
+	"src/data/pokemon/base_stats.h",
+	"src/data/text/species_names.h",
+	"src/data/pokemon/pokedex_entries.h",
+	"src/data/pokemon/pokedex_text.h",
+	"src/data/battle_moves.h",
+	"src/data/moves_info.h",
+	"src/data/text/move_names.h",
+	"src/data/text/move_descriptions.h",
+	"src/data/text/abilities.h",
+	"src/data/abilities.h",
+	"src/data/pokemon/level_up_learnsets.h",
+	"src/data/pokemon/level_up_learnsets/*.h",
+	"src/data/pokemon/level_up_learnset_pointers.h",
+	"src/data/pokemon/egg_moves.h",
+	"src/data/pokemon/evolution.h",
+	"src/data/items.h",
+	"src/data/text/trainer_class_names.h",
+	"src/data/trainers.h",
+	"src/data/trainer_parties.h",
+}
+
+// physicalTypes decide whether a move is physical or special in the third
+// generation games, which have no per move category
+var physicalTypes = map[string]bool{"Normal": true, "Fighting": true, "Flying": true, "Poison": true, "Ground": true, "Rock": true, "Bug": true, "Ghost": true, "Steel": true}
+
+// evolutionMethods maps the decomp evolution methods onto the methods of the
+// evolution dialog. Approximated ones lose a condition the editor cannot
+// express and are reported.
+var evolutionMethods = map[string]string{
+	"EVO_LEVEL":            "level-up",
+	"EVO_ITEM":             "stone",
+	"EVO_TRADE":            "trade",
+	"EVO_TRADE_ITEM":       "trade",
+	"EVO_FRIENDSHIP":       "friendship",
+	"EVO_FRIENDSHIP_DAY":   "friendship",
+	"EVO_FRIENDSHIP_NIGHT": "friendship",
+	"EVO_LEVEL_ATK_GT_DEF": "level-up",
+	"EVO_LEVEL_ATK_EQ_DEF": "level-up",
+	"EVO_LEVEL_ATK_LT_DEF": "level-up",
+	"EVO_LEVEL_SILCOON":    "level-up",
+	"EVO_LEVEL_CASCOON":    "level-up",
+	"EVO_LEVEL_NINJASK":    "level-up",
+	"EVO_LEVEL_SHEDINJA":   "level-up",
+}
+
+var approximatedEvolutions = map[string]bool{
+	"EVO_LEVEL_ATK_GT_DEF": true,
+	"EVO_LEVEL_ATK_EQ_DEF": true,
+	"EVO_LEVEL_ATK_LT_DEF": true,
+	"EVO_LEVEL_SILCOON":    true,
+	"EVO_LEVEL_CASCOON":    true,
+	"EVO_LEVEL_NINJASK":    true,
+	"EVO_LEVEL_SHEDINJA":   true,
+}
+
+// DecompImport asks to import the data of a decomp checkout at Source. By
+// default imported records replace records with the same ID and everything
+// else in the project is kept, Replace drops the project's species, moves and
+// trainers first. Trainer classes are only ever added.
+type DecompImport struct {
+	Source  string `json:"source"`
+	DryRun  bool   `json:"dryRun"`
+	Replace bool   `json:"replace"`
+}
+
+// UnmappedConstant is a constant of the decomp data that has no counterpart
+// in the editor's models. Record is where it was first seen, Count how often.
+type UnmappedConstant struct {
+	Constant string `json:"constant"`
+	Record   string `json:"record"`
+	Reason   string `json:"reason"`
+	Count    int    `json:"count"`
+}
+
+// DecompImportReport tells what an import read and converted. Nothing is
+// written when it was a dry run.
+type DecompImportReport struct {
+	Files          []string           `json:"files"`
+	Pokemon        int                `json:"pokemon"`
+	Moves          int                `json:"moves"`
+	Trainers       int                `json:"trainers"`
+	TrainerClasses int                `json:"trainerClasses"`
+	Unmapped       []UnmappedConstant `json:"unmapped"`
+	Applied        bool               `json:"applied"`
+}
+
+type DecompImporterApp struct {
+	app *core.App
+}
+
+// NewDecompImporterApp creates a new DecompImporterApp struct
+func NewDecompImporterApp(app *core.App) *DecompImporterApp {
+	return &DecompImporterApp{
+		app: app,
+	}
+}
+
+// GrabDecompCheckout asks for the root of a pokeemerald or pokefirered
+// checkout
+func (a *DecompImporterApp) GrabDecompCheckout() string {
+	selection, err := dialogs.Directory(a.app.Ctx, "Select decomp checkout")
+	if err != nil {
+		fmt.Printf("error is %v while selecting the decomp checkout!\n", err)
+		return ""
+	}
+	return selection
+}
+
+// ImportDecomp converts the species, moves, learnsets, evolutions and
+// trainer parties of a pokeemerald or pokefirered checkout into the current
+// project's pokemon.toml, moves.toml, trainers.toml and trainerclasses.toml,
+// as one undoable journal entry
+func (a *DecompImporterApp) ImportDecomp(request DecompImport) (DecompImportReport, error) {
+	var report DecompImportReport
+	if a.app.DataDirectory == "" {
+		return report, errors.New("no project selected")
+	}
+	if request.Source == "" {
+		return report, errors.New("no decomp checkout given")
+	}
+	types, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return report, err
+	}
+	data, err := readDecomp(strings.ReplaceAll(request.Source, "\\", "/"))
+	if err != nil {
+		return report, err
+	}
+	report.Files = data.files
+	converted := data.convert(types)
+	report.Pokemon = len(converted.pokemon)
+	report.Moves = len(converted.moves)
+	report.Trainers = len(converted.trainers)
+	report.TrainerClasses = len(converted.trainerClasses)
+	report.Unmapped = data.unmapped.list()
+	if request.DryRun {
+		return report, nil
+	}
+
+	defer a.app.Journal.Begin(fmt.Sprintf("Import decomp data from %s", filepath.Base(request.Source)))()
+	dataDirectory := a.app.DataDirectory
+	if len(converted.moves) > 0 {
+		err := repository.NewMoveRepository(dataDirectory).Update(func(records *[]Models.Move) error {
+			mergeRecords(records, converted.moves, request.Replace, func(move Models.Move) string { return strconv.Itoa(move.ID) })
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if len(converted.pokemon) > 0 {
+		err := repository.NewPokemonRepository(dataDirectory).Update(func(records *[]Models.Pokemon) error {
+			mergeRecords(records, converted.pokemon, request.Replace, func(pokemon Models.Pokemon) string { return pokemon.ID })
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if len(converted.trainerClasses) > 0 {
+		err := repository.NewTrainerClassRepository(dataDirectory).Update(func(records *[]coreModels.TrainerClass) error {
+			// Classes are only added, the music picked for existing ones is kept
+			known := make(map[string]bool, len(*records))
+			for _, class := range *records {
+				known[class.Name] = true
+			}
+			for _, class := range converted.trainerClasses {
+				if !known[class.Name] {
+					*records = append(*records, class)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if len(converted.trainers) > 0 {
+		err := repository.NewTrainerRepository(dataDirectory).Update(func(records *[]coreModels.Trainer) error {
+			mergeRecords(records, converted.trainers, request.Replace, func(trainer coreModels.Trainer) string { return trainer.ID })
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// mergeRecords puts imported into records, replacing records with the same
+// ID in place and appending the others. With replace only imported is kept.
+func mergeRecords[R any](records *[]R, imported []R, replace bool, id func(R) string) {
+	if replace {
+		*records = imported
+		return
+	}
+	positions := make(map[string]int, len(*records))
+	for index, record := range *records {
+		positions[id(record)] = index
+	}
+	for _, record := range imported {
+		if index, ok := positions[id(record)]; ok {
+			(*records)[index] = record
+			continue
+		}
+		positions[id(record)] = len(*records)
+		*records = append(*records, record)
+	}
+}
+
+// indexedEntry is one [CONSTANT] = value entry of any table in the sources
+type indexedEntry struct {
+	constant string
+	value    *cValue
+}
+
+// decompData is everything read from the checkout
+type decompData struct {
+	files        []string
+	constants    *constants
+	declarations map[string]*cValue
+	entries      []indexedEntry
+	// names are the display names of constants, from the text tables
+	names map[string]string
+	// texts are string variables such as dex entries and move descriptions
+	texts map[string]string
+	// pointers are table entries naming a string variable
+	pointers map[string]string
+	unmapped *unmappedConstants
+}
+
+// readDecomp parses every known source file present in the checkout
+func readDecomp(root string) (*decompData, error) {
+	if _, err := os.Stat(filepath.Join(root, "include/constants/species.h")); err != nil {
+		return nil, fmt.Errorf("%s is not a pokeemerald or pokefirered checkout, include/constants/species.h is missing", root)
+	}
+	data := &decompData{
+		constants:    newConstants(),
+		declarations: make(map[string]*cValue),
+		names:        make(map[string]string),
+		texts:        make(map[string]string),
+		pointers:     make(map[string]string),
+		unmapped:     newUnmappedConstants(),
+	}
+	for _, pattern := range decompSources {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, path := range matches {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", path, err)
+			}
+			relative, _ := filepath.Rel(root, path)
+			data.read(filepath.ToSlash(relative), string(source))
+		}
+	}
+	data.index()
+	return data, nil
+}
+
+func (d *decompData) read(file string, source string) {
+	d.files = append(d.files, file)
+	tokens, defines := tokenize(source)
+	for name, value := range defines {
+		d.constants.definitions[name] = value
+	}
+	p := &parser{tokens: tokens}
+	for _, declaration := range p.declarations(file, d.constants.definitions) {
+		d.declarations[declaration.name] = declaration.value
+		if text, ok := declaration.value.text(); ok {
+			d.texts[declaration.name] = text
+		}
+		for _, entry := range declaration.value.entries {
+			if entry.index == nil {
+				continue
+			}
+			d.entries = append(d.entries, indexedEntry{constant: indexName(entry.index), value: entry.value})
+		}
+	}
+}
+
+// index collects the names and string pointers of the tables
+func (d *decompData) index() {
+	for _, entry := range d.entries {
+		if text, ok := entry.value.text(); ok && !entry.value.braced {
+			d.names[entry.constant] = text
+			continue
+		}
+		if name := entry.value.ident(); name != "" {
+			if text, ok := d.texts[name]; ok {
+				d.pointers[entry.constant] = text
+			}
+			continue
+		}
+		for _, field := range []string{"name", "speciesName"} {
+			if text, ok := entry.value.field(field).text(); ok {
+				d.names[entry.constant] = text
+			}
+		}
+	}
+}
+
+// entriesOf returns the table entries for constants with prefix that have
+// field, such as the species of the species info table
+func (d *decompData) entriesOf(prefix string, field string) []indexedEntry {
+	var entries []indexedEntry
+	for _, entry := range d.entries {
+		if strings.HasPrefix(entry.constant, prefix) && entry.value.field(field) != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// converted are the records ready to be written
+type converted struct {
+	pokemon        []Models.Pokemon
+	moves          []Models.Move
+	trainers       []coreModels.Trainer
+	trainerClasses []coreModels.TrainerClass
+}
+
+// conversion keeps what later steps look up by decomp constant
+type conversion struct {
+	data    *decompData
+	moves   map[string]Models.Move
+	species map[string]*Models.Pokemon
+	// learnsets are the level up moves of each species, for default
+	// trainer movesets
+	learnsets map[string][]learnedMove
+	// types are the project's types, imported types take their casing
+	types []coreModels.TypeDefinition
+}
+
+type learnedMove struct {
+	level int
+	move  string
+}
+
+func (d *decompData) convert(types []coreModels.TypeDefinition) converted {
+	c := &conversion{
+		data:      d,
+		types:     types,
+		moves:     make(map[string]Models.Move),
+		species:   make(map[string]*Models.Pokemon),
+		learnsets: make(map[string][]learnedMove),
+	}
+	var result converted
+	result.moves = c.convertMoves()
+	result.pokemon = c.convertSpecies()
+	result.trainers, result.trainerClasses = c.convertTrainers()
+	return result
+}
+
+func (c *conversion) convertMoves() []Models.Move {
+	var moves []Models.Move
+	for _, entry := range c.data.entriesOf("MOVE_", "pp") {
+		if entry.constant == "MOVE_NONE" {
+			continue
+		}
+		id, ok := c.data.constants.value(entry.constant)
+		if !ok {
+			c.data.unmapped.add(entry.constant, entry.constant, "move has no number in include/constants/moves.h, skipped")
+			continue
+		}
+		move := Models.Move{
+			ID:           id,
+			Name:         c.data.displayName(entry.constant, "MOVE_"),
+			Power:        c.integer(entry.value.field("power")),
+			Pp:           c.integer(entry.value.field("pp")),
+			Accuracy:     c.integer(entry.value.field("accuracy")),
+			Type:         c.typeName(entry.value.field("type").ident(), entry.constant),
+			Descriptions: []Models.Descriptions{},
+			Effects:      []Models.Effects{},
+		}
+		move.KindOfMove = c.moveKind(entry, move)
+		if description := c.describe(entry.value.field("description"), entry.constant); description != "" {
+			move.Descriptions = append(move.Descriptions, Models.Descriptions{Description: description})
+		}
+		if effect := entry.value.field("effect").ident(); effect != "" && effect != "EFFECT_HIT" {
+			text := humanize(strings.TrimPrefix(effect, "EFFECT_"))
+			if chance := c.integer(entry.value.field("secondaryEffectChance")); chance > 0 {
+				text = fmt.Sprintf("%s, %d%% chance", text, chance)
+			}
+			move.Effects = append(move.Effects, Models.Effects{EffectText: text})
+		}
+		c.moves[entry.constant] = move
+		moves = append(moves, move)
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].ID < moves[j].ID })
+	return moves
+}
+
+// moveKind reads the category of later decomps, or applies the third
+// generation rule that the type decides
+func (c *conversion) moveKind(entry indexedEntry, move Models.Move) string {
+	for _, field := range []string{"category", "split"} {
+		category := entry.value.field(field).ident()
+		switch {
+		case strings.HasSuffix(category, "PHYSICAL"):
+			return "Physical"
+		case strings.HasSuffix(category, "SPECIAL"):
+			return "Special"
+		case strings.HasSuffix(category, "STATUS"):
+			return "Status"
+		}
+	}
+	switch {
+	case move.Power == 0:
+		return "Status"
+	case physicalTypes[move.Type]:
+		return "Physical"
+	}
+	return "Special"
+}
+
+func (c *conversion) convertSpecies() []Models.Pokemon {
+	stats := c.data.entriesOf("SPECIES_", "baseHP")
+	_, hasDex := c.data.constants.definitions["NATIONAL_DEX_BULBASAUR"]
+	owners := make(map[string]string)
+	var order []string
+	for _, entry := range stats {
+		id, ok := c.dexNumber(entry, hasDex)
+		if !ok {
+			c.data.unmapped.add(entry.constant, entry.constant, "species has no number, skipped")
+			continue
+		}
+		if owner, taken := owners[id]; taken {
+			c.data.unmapped.add(entry.constant, entry.constant, fmt.Sprintf("shares national dex number %s with %s, skipped", id, owner))
+			continue
+		}
+		owners[id] = entry.constant
+		pokemon := &Models.Pokemon{
+			ID:      id,
+			Species: c.data.displayName(entry.constant, "SPECIES_"),
+			Stats: Models.Stats{
+				Hp:             c.integer(entry.value.field("baseHP")),
+				Attack:         c.integer(entry.value.field("baseAttack")),
+				Defense:        c.integer(entry.value.field("baseDefense")),
+				SpecialAttack:  c.integer(entry.value.field("baseSpAttack")),
+				SpecialDefense: c.integer(entry.value.field("baseSpDefense")),
+				Speed:          c.integer(entry.value.field("baseSpeed")),
+			},
+			Abilities:  []Models.Abilities{},
+			Moves:      []Models.Moves{},
+			Evolutions: []Models.Evolutions{},
+		}
+		pokemon.Types = c.speciesTypes(entry)
+		pokemon.Abilities = c.speciesAbilities(entry)
+		pokemon.DexEntry = c.dexEntry(entry)
+		c.species[entry.constant] = pokemon
+		order = append(order, entry.constant)
+	}
+
+	for _, constant := range order {
+		entry := c.speciesEntry(constant, stats)
+		pokemon := c.species[constant]
+		for _, learned := range c.levelUpLearnset(entry) {
+			if name, ok := c.moveName(learned.move, pokemon.Species); ok {
+				pokemon.Moves = append(pokemon.Moves, Models.Moves{Name: name, Level: learned.level, Method: "level-up"})
+				c.learnsets[constant] = append(c.learnsets[constant], learned)
+			}
+		}
+		for _, move := range c.eggMoves(entry) {
+			if name, ok := c.moveName(move, pokemon.Species); ok {
+				pokemon.Moves = append(pokemon.Moves, Models.Moves{Name: name, Level: 1, Method: "egg"})
+			}
+		}
+		pokemon.Evolutions = c.evolutions(entry, pokemon)
+	}
+
+	pokemon := make([]Models.Pokemon, 0, len(order))
+	for _, constant := range order {
+		pokemon = append(pokemon, *c.species[constant])
+	}
+	sort.SliceStable(pokemon, func(i, j int) bool {
+		left, _ := strconv.Atoi(pokemon[i].ID)
+		right, _ := strconv.Atoi(pokemon[j].ID)
+		return left < right
+	})
+	return pokemon
+}
+
+func (c *conversion) speciesEntry(constant string, entries []indexedEntry) indexedEntry {
+	for _, entry := range entries {
+		if entry.constant == constant {
+			return entry
+		}
+	}
+	return indexedEntry{constant: constant}
+}
+
+// dexNumber is the national dex number of a species, which the project uses
+// as its ID, or its species number when the checkout has no dex constants
+func (c *conversion) dexNumber(entry indexedEntry, hasDex bool) (string, bool) {
+	if number, ok := c.data.constants.number(entry.value.field("natDexNum")); ok {
+		return strconv.Itoa(number), true
+	}
+	if hasDex {
+		number, ok := c.data.constants.value("NATIONAL_DEX_" + strings.TrimPrefix(entry.constant, "SPECIES_"))
+		if ok && number > 0 {
+			return strconv.Itoa(number), true
+		}
+		return "", false
+	}
+	number, ok := c.data.constants.value(entry.constant)
+	return strconv.Itoa(number), ok && number > 0
+}
+
+func (c *conversion) speciesTypes(entry indexedEntry) []string {
+	var constants []string
+	if types := entry.value.field("types"); types != nil {
+		items := types.items()
+		if _, args, ok := types.call(); ok {
+			items = args
+		}
+		for _, item := range items {
+			constants = append(constants, item.ident())
+		}
+	} else {
+		constants = append(constants, entry.value.field("type1").ident(), entry.value.field("type2").ident())
+	}
+	var types []string
+	for _, constant := range constants {
+		if constant == "" {
+			continue
+		}
+		name := c.typeName(constant, entry.constant)
+		if len(types) == 0 || types[0] != name {
+			types = append(types, name)
+		}
+	}
+	return types
+}
+
+func (c *conversion) speciesAbilities(entry indexedEntry) []Models.Abilities {
+	var constants []string
+	if abilities := entry.value.field("abilities"); abilities != nil {
+		for _, item := range abilities.items() {
+			constants = append(constants, item.ident())
+		}
+	} else {
+		constants = append(constants, entry.value.field("ability1").ident(), entry.value.field("ability2").ident())
+	}
+	hidden := entry.value.field("hiddenAbility").ident()
+	if hidden != "" {
+		for len(constants) < 2 {
+			constants = append(constants, "")
+		}
+		constants = append(constants, hidden)
+	}
+	abilities := []Models.Abilities{}
+	seen := make(map[string]bool)
+	for index, constant := range constants {
+		if constant == "" || constant == "ABILITY_NONE" || seen[constant] {
+			continue
+		}
+		seen[constant] = true
+		abilities = append(abilities, Models.Abilities{Name: c.data.displayName(constant, "ABILITY_"), IsHidden: index >= 2})
+	}
+	return abilities
+}
+
+// dexEntry reads the description of a species from the species info of
+// later decomps or from the pokedex entries
+func (c *conversion) dexEntry(entry indexedEntry) string {
+	if description := c.describe(entry.value.field("description"), ""); description != "" {
+		return description
+	}
+	dex := "NATIONAL_DEX_" + strings.TrimPrefix(entry.constant, "SPECIES_")
+	for _, dexEntry := range c.data.entriesOf(dex, "description") {
+		if dexEntry.constant == dex {
+			return c.describe(dexEntry.value.field("description"), "")
+		}
+	}
+	return ""
+}
+
+// describe reads a text given inline or as the name of a string variable,
+// falling back to a description table entry for constant
+func (c *conversion) describe(value *cValue, constant string) string {
+	if text, ok := value.text(); ok {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	if text, ok := c.data.texts[value.ident()]; ok {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	return strings.Join(strings.Fields(c.data.pointers[constant]), " ")
+}
+
+// levelUpLearnset finds the level up moves of a species through the species
+// info, the learnset pointer table or the learnset's name
+func (c *conversion) levelUpLearnset(entry indexedEntry) []learnedMove {
+	name := entry.value.field("levelUpLearnset").ident()
+	if name == "" {
+		if pointers, ok := c.data.declarations["gLevelUpLearnsets"]; ok {
+			for _, pointer := range pointers.entries {
+				if indexName(pointer.index) == entry.constant {
+					name = pointer.value.ident()
+				}
+			}
+		}
+	}
+	if name == "" {
+		name = "s" + camelCase(strings.TrimPrefix(entry.constant, "SPECIES_")) + "LevelUpLearnset"
+	}
+	learnset, ok := c.data.declarations[name]
+	if !ok {
+		return nil
+	}
+	var moves []learnedMove
+	for _, item := range learnset.items() {
+		macro, args, ok := item.call()
+		if !ok || macro != "LEVEL_UP_MOVE" || len(args) != 2 {
+			continue
+		}
+		level, ok := c.data.constants.number(args[0])
+		if !ok {
+			continue
+		}
+		moves = append(moves, learnedMove{level: level, move: args[1].ident()})
+	}
+	return moves
+}
+
+// eggMoves reads egg_moves(SPECIES, MOVE, ...) of pokeemerald or the egg
+// move learnset of later decomps
+func (c *conversion) eggMoves(entry indexedEntry) []string {
+	var moves []string
+	if learnset, ok := c.data.declarations[entry.value.field("eggMoveLearnset").ident()]; ok {
+		for _, item := range learnset.items() {
+			if move := item.ident(); move != "" && move != "MOVE_UNAVAILABLE" {
+				moves = append(moves, move)
+			}
+		}
+		return moves
+	}
+	eggMoves, ok := c.data.declarations["gEggMoves"]
+	if !ok {
+		return nil
+	}
+	for _, item := range eggMoves.items() {
+		macro, args, ok := item.call()
+		if !ok || macro != "egg_moves" || len(args) == 0 || "SPECIES_"+args[0].ident() != entry.constant {
+			continue
+		}
+		for _, arg := range args[1:] {
+			moves = append(moves, arg.ident())
+		}
+	}
+	return moves
+}
+
+// moveName returns the name of an imported move, reporting moves the
+// checkout's move data does not define
+func (c *conversion) moveName(constant string, record string) (string, bool) {
+	if move, ok := c.moves[constant]; ok {
+		return move.Name, true
+	}
+	if constant == "" || constant == "MOVE_NONE" {
+		return "", false
+	}
+	c.data.unmapped.add(constant, record, "move is not in the move data, dropped")
+	return "", false
+}
+
+// evolutions reads the evolution table of pokeemerald or the evolutions of
+// the species info of later decomps
+func (c *conversion) evolutions(entry indexedEntry, pokemon *Models.Pokemon) []Models.Evolutions {
+	var methods []*cValue
+	if value := entry.value.field("evolutions"); value != nil {
+		if _, args, ok := value.call(); ok {
+			methods = args
+		} else {
+			methods = value.items()
+		}
+	} else if table, ok := c.data.declarations["gEvolutionTable"]; ok {
+		for _, evolution := range table.entries {
+			if indexName(evolution.index) == entry.constant {
+				methods = evolution.value.items()
+			}
+		}
+	}
+
+	evolutions := []Models.Evolutions{}
+	for _, method := range methods {
+		items := method.items()
+		if len(items) < 3 {
+			continue
+		}
+		kind, target := items[0].ident(), items[2].ident()
+		evolved, ok := c.species[target]
+		if !ok {
+			c.data.unmapped.add(target, pokemon.Species, "evolution target is not an imported species, dropped")
+			continue
+		}
+		name, known := evolutionMethods[kind]
+		if !known {
+			name = strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(kind, "EVO_"), "_", "-"))
+			c.data.unmapped.add(kind, pokemon.Species, fmt.Sprintf("evolution method has no counterpart, imported as %q", name))
+		} else if approximatedEvolutions[kind] {
+			c.data.unmapped.add(kind, pokemon.Species, "imported as level-up, the extra condition is dropped")
+		}
+		evolutions = append(evolutions, Models.Evolutions{
+			Name:        evolved.Species,
+			Methods:     []string{name, c.evolutionParameter(kind, name, items[1])},
+			PokemonID:   evolved.ID,
+			EvolutionID: fmt.Sprintf("%s-%s", pokemon.ID, evolved.ID),
+		})
+	}
+	return evolutions
+}
+
+// evolutionParameter is the second evolution method, the level, stone or
+// item the evolution needs
+func (c *conversion) evolutionParameter(kind string, method string, parameter *cValue) string {
+	switch {
+	case kind == "EVO_FRIENDSHIP_DAY":
+		return "day"
+	case kind == "EVO_FRIENDSHIP_NIGHT":
+		return "night"
+	case method == "level-up":
+		if level, ok := c.data.constants.number(parameter); ok {
+			return strconv.Itoa(level)
+		}
+	}
+	item := parameter.ident()
+	if item == "" || item == "ITEM_NONE" {
+		if number, ok := c.data.constants.number(parameter); ok && number != 0 {
+			return strconv.Itoa(number)
+		}
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(item, "ITEM_"), "_", "-"))
+}
+
+func (c *conversion) convertTrainers() ([]coreModels.Trainer, []coreModels.TrainerClass) {
+	var trainers []coreModels.Trainer
+	var classes []coreModels.TrainerClass
+	seenClasses := make(map[string]bool)
+	for _, entry := range c.data.entriesOf("TRAINER_", "trainerClass") {
+		if entry.constant == "TRAINER_NONE" {
+			continue
+		}
+		name, _ := entry.value.field("trainerName").text()
+		party := c.party(entry)
+		if party == nil {
+			c.data.unmapped.add(entry.constant, entry.constant, "trainer party was not found, skipped")
+			continue
+		}
+		id := strings.ToLower(strings.TrimPrefix(entry.constant, "TRAINER_"))
+		if number, ok := c.data.constants.value(entry.constant); ok {
+			id = strconv.Itoa(number)
+		}
+		class := c.data.displayName(entry.value.field("trainerClass").ident(), "TRAINER_CLASS_")
+		trainer := coreModels.Trainer{
+			Name:      titleCase(name),
+			ID:        id,
+			ClassType: class,
+		}
+		trainer.AI = c.trainerAI(entry, trainer.Name)
+		for _, mon := range party.items() {
+			if pokemon, ok := c.partyPokemon(mon, trainer.Name); ok {
+				trainer.Pokemons = append(trainer.Pokemons, pokemon)
+			}
+		}
+		if class != "" && !seenClasses[class] {
+			seenClasses[class] = true
+			classes = append(classes, coreModels.TrainerClass{Data: Models.Data{Name: class}})
+		}
+		trainers = append(trainers, trainer)
+	}
+	return trainers, classes
+}
+
+// trainerAI converts a trainer's aiFlags and battle items. AI_SCRIPT_ and
+// AI_FLAG_ constants become the editor's AI flags, AI_FLAG_SMART_SWITCHING
+// the smart switch mode, and repeated items are counted.
+func (c *conversion) trainerAI(entry indexedEntry, trainer string) *coreModels.AIProfile {
+	var profile coreModels.AIProfile
+	for _, constant := range entry.value.field("aiFlags").identifiers() {
+		name := strings.TrimPrefix(strings.TrimPrefix(constant, "AI_SCRIPT_"), "AI_FLAG_")
+		if name == constant {
+			continue
+		}
+		if name == "SMART_SWITCHING" {
+			profile.Switching = core.SwitchSmart
+			continue
+		}
+		flag := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+		if core.AIFlagBit(flag) == -1 {
+			c.data.unmapped.add(constant, trainer, "AI flag has no counterpart, dropped")
+			continue
+		}
+		known := false
+		for _, existing := range profile.Flags {
+			known = known || existing == flag
+		}
+		if !known {
+			profile.Flags = append(profile.Flags, flag)
+		}
+	}
+	for _, item := range entry.value.field("items").items() {
+		constant := item.ident()
+		if constant == "" || constant == "ITEM_NONE" {
+			continue
+		}
+		name := c.data.displayName(constant, "ITEM_")
+		counted := false
+		for index := range profile.Items {
+			if profile.Items[index].Name == name {
+				profile.Items[index].Count++
+				counted = true
+			}
+		}
+		if !counted {
+			profile.Items = append(profile.Items, coreModels.AIItem{Name: name, Count: 1})
+		}
+	}
+	if len(profile.Flags) == 0 && len(profile.Items) == 0 && profile.Switching == "" {
+		return nil
+	}
+	return &profile
+}
+
+// party finds the party array a trainer points at, however the decomp
+// version spells it
+func (c *conversion) party(entry indexedEntry) *cValue {
+	for _, name := range entry.value.field("party").identifiers() {
+		if party, ok := c.data.declarations[name]; ok && party.braced {
+			return party
+		}
+	}
+	return nil
+}
+
+func (c *conversion) partyPokemon(mon *cValue, trainer string) (coreModels.TrainerPokemon, bool) {
+	constant := mon.field("species").ident()
+	species, ok := c.species[constant]
+	if !ok {
+		c.data.unmapped.add(constant, trainer, "party species is not an imported species, dropped")
+		return coreModels.TrainerPokemon{}, false
+	}
+	level := c.integer(mon.field("lvl"))
+	pokemon := coreModels.TrainerPokemon{Pokemons: Models.Pokemons{
+		Species: species.Species,
+		Level:   level,
+		ID:      species.ID,
+		Moves:   []string{},
+	}}
+	if item := mon.field("heldItem").ident(); item != "" && item != "ITEM_NONE" {
+		pokemon.HeldItem = c.data.displayName(item, "ITEM_")
+	}
+	if moves := mon.field("moves"); moves != nil {
+		for _, move := range moves.items() {
+			if name, ok := c.moveName(move.ident(), trainer); ok {
+				pokemon.Moves = append(pokemon.Moves, name)
+			}
+		}
+	} else {
+		pokemon.Moves = c.defaultMoves(constant, level)
+	}
+
+	// Party IVs are 0-255 in the third generation games, scaled to 0-31
+	iv := c.integer(mon.field("iv")) * core.MaxIV / 255
+	pokemon.IVs = &coreModels.StatSpread{HP: iv, Attack: iv, Defense: iv, Speed: iv, SpecialAttack: iv, SpecialDefense: iv}
+	core.ApplyStats(&pokemon, species.Stats)
+	return pokemon, true
+}
+
+// defaultMoves picks the moveset the games give a party Pokemon without
+// custom moves, the last four level up moves learned by its level
+func (c *conversion) defaultMoves(species string, level int) []string {
+	moves := []string{}
+	for _, learned := range c.learnsets[species] {
+		if learned.level > level {
+			break
+		}
+		name := c.moves[learned.move].Name
+		known := false
+		for _, move := range moves {
+			known = known || move == name
+		}
+		if known {
+			continue
+		}
+		if len(moves) == 4 {
+			moves = moves[1:]
+		}
+		moves = append(moves, name)
+	}
+	return moves
+}
+
+func (c *conversion) typeName(constant string, record string) string {
+	if constant == "" {
+		return ""
+	}
+	name := titleCase(strings.ReplaceAll(strings.TrimPrefix(constant, "TYPE_"), "_", " "))
+	for _, known := range c.types {
+		if strings.EqualFold(known.Name, name) {
+			return known.Name
+		}
+	}
+	c.data.unmapped.add(constant, record, fmt.Sprintf("type is not one of the project's types, imported as %q", name))
+	return name
+}
+
+func (c *conversion) integer(value *cValue) int {
+	number, _ := c.data.constants.number(value)
+	return number
+}
+
+// displayName is the name the text tables give constant, or the constant
+// itself made readable
+func (d *decompData) displayName(constant string, prefix string) string {
+	if constant == "" {
+		return ""
+	}
+	if name, ok := d.names[constant]; ok && strings.TrimSpace(name) != "" {
+		return titleCase(name)
+	}
+	return titleCase(strings.ReplaceAll(strings.TrimPrefix(constant, prefix), "_", " "))
+}
+
+// titleCase turns the all caps names of the third generation games into
+// names like Mr. Mime or Double-Edge, names already in mixed case are kept
+func titleCase(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.ToUpper(name) != name {
+		return name
+	}
+	runes := []rune(strings.ToLower(name))
+	for index := range runes {
+		if index == 0 || runes[index-1] == ' ' || runes[index-1] == '-' {
+			runes[index] = []rune(strings.ToUpper(string(runes[index])))[0]
+		}
+	}
+	return string(runes)
+}
+
+// humanize turns POISON_HIT into Poison hit
+func humanize(constant string) string {
+	text := strings.ToLower(strings.ReplaceAll(constant, "_", " "))
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// camelCase turns MR_MIME into MrMime, the way the decomps name learnsets
+func camelCase(constant string) string {
+	var name strings.Builder
+	for _, part := range strings.Split(strings.ToLower(constant), "_") {
+		if part != "" {
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return name.String()
+}
+
+// unmappedConstants collects unmapped constants once per constant and reason
+type unmappedConstants struct {
+	byKey map[string]*UnmappedConstant
+	order []string
+}
+
+func newUnmappedConstants() *unmappedConstants {
+	return &unmappedConstants{byKey: make(map[string]*UnmappedConstant)}
+}
+
+func (u *unmappedConstants) add(constant string, record string, reason string) {
+	if constant == "" {
+		return
+	}
+	key := constant + "\x00" + reason
+	if existing, ok := u.byKey[key]; ok {
+		existing.Count++
+		return
+	}
+	u.byKey[key] = &UnmappedConstant{Constant: constant, Record: record, Reason: reason, Count: 1}
+	u.order = append(u.order, key)
+}
+
+func (u *unmappedConstants) list() []UnmappedConstant {
+	list := make([]UnmappedConstant, 0, len(u.order))
+	for _, key := range u.order {
+		list = append(list, *u.byKey[key])
+	}
+	return list
+}
@@ -0,0 +1,381 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	"github.com/zenith110/pokemon-engine-tools/tools-core/dialogs"
+	"github.com/zenith110/pokemon-engine-tools/tools/validator"
+)
+
+// ManifestName is where the manifest sits inside an export archive
+const ManifestName = "manifest.json"
+
+// Kinds of export archives
+const (
+	KindFull  = "full"
+	KindPatch = "patch"
+)
+
+// ExportOptions asks for an export of the current project. Version labels
+// the archive and defaults to the build time. Previous is an earlier export
+// archive, when given only the files changed since it are bundled.
+type ExportOptions struct {
+	Destination string `json:"destination"`
+	Version     string `json:"version"`
+	Compact     bool   `json:"compact"`
+	Previous    string `json:"previous"`
+}
+
+// ManifestFile is one file of the exported data, Path is relative to the
+// project, e.g. data/toml/pokemon.toml
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Sha256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Manifest describes an export archive. Files always lists the whole
+// exported data so a later patch can be made against any export. A patch
+// only contains the Changed files and asks for Removed ones to be deleted.
+type Manifest struct {
+	Kind          string         `json:"kind"`
+	Project       string         `json:"project"`
+	Version       string         `json:"version"`
+	BaseVersion   string         `json:"baseVersion,omitempty"`
+	EngineVersion string         `json:"engineVersion"`
+	EngineSha     string         `json:"engineSha"`
+	BuiltAt       time.Time      `json:"builtAt"`
+	Compacted     bool           `json:"compacted"`
+	Files         []ManifestFile `json:"files"`
+	Changed       []string       `json:"changed,omitempty"`
+	Removed       []string       `json:"removed,omitempty"`
+}
+
+// ExportResult is the written archive, or the validation report that stopped
+// the export when Archive is empty
+type ExportResult struct {
+	Archive    string           `json:"archive"`
+	Manifest   Manifest         `json:"manifest"`
+	Validation validator.Report `json:"validation"`
+}
+
+// HasErrors reports a project that failed validation and was not exported
+func (r ExportResult) HasErrors() bool {
+	return r.Validation.HasErrors()
+}
+
+type ExportApp struct {
+	app *core.App
+}
+
+// NewExportApp creates a new ExportApp struct
+func NewExportApp(app *core.App) *ExportApp {
+	return &ExportApp{
+		app: app,
+	}
+}
+
+// GrabExportDestination asks for the folder export archives are written to
+func (a *ExportApp) GrabExportDestination() string {
+	selection, err := dialogs.Directory(a.app.Ctx, "Select export folder")
+	if err != nil {
+		fmt.Printf("error is %v while selecting the export folder!\n", err)
+		return ""
+	}
+	return selection
+}
+
+// GrabPreviousExport asks for an earlier export archive to make a patch
+// against
+func (a *ExportApp) GrabPreviousExport() string {
+	selection, err := dialogs.File(a.app.Ctx, "Select previous export", "Exports (*.zip)", "*.zip")
+	if err != nil {
+		fmt.Printf("error is %v while selecting the previous export!\n", err)
+		return ""
+	}
+	return selection
+}
+
+// ExportProject validates the current project and bundles its data folder
+// into a versioned zip archive with a manifest, leaving out editor-only
+// files. Nothing is written when validation finds errors.
+func (a *ExportApp) ExportProject(options ExportOptions) (ExportResult, error) {
+	var result ExportResult
+	dataDirectory := a.app.DataDirectory
+	if dataDirectory == "" {
+		return result, errors.New("no project selected")
+	}
+	if options.Destination == "" {
+		return result, errors.New("no export destination given")
+	}
+	report, err := validator.Validate(dataDirectory)
+	if err != nil {
+		return result, err
+	}
+	result.Validation = report
+	if report.HasErrors() {
+		return result, nil
+	}
+
+	builtAt := time.Now().UTC()
+	manifest := Manifest{
+		Kind:      KindFull,
+		Project:   filepath.Base(dataDirectory),
+		Version:   options.Version,
+		BuiltAt:   builtAt,
+		Compacted: options.Compact,
+	}
+	if manifest.Version == "" {
+		manifest.Version = builtAt.Format("20060102-150405")
+	}
+	if project, err := a.app.Projects.FindByPath(dataDirectory); err == nil {
+		manifest.Project = project.Name
+		manifest.EngineVersion = project.VersionOfEngine
+		// Projects cloned from the engine store the engine commit as their
+		// version, templates name an engine.toml version, which has no SHA
+		if isCommitHash(project.VersionOfEngine) {
+			manifest.EngineSha = project.VersionOfEngine
+		}
+	}
+
+	files, err := collectFiles(dataDirectory, options.Compact)
+	if err != nil {
+		return result, err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		sum := sha256.Sum256(files[path])
+		manifest.Files = append(manifest.Files, ManifestFile{Path: path, Sha256: hex.EncodeToString(sum[:]), Size: int64(len(files[path]))})
+	}
+
+	bundled := paths
+	if options.Previous != "" {
+		previous, err := ReadManifest(strings.ReplaceAll(options.Previous, "\\", "/"))
+		if err != nil {
+			return result, err
+		}
+		if previous.Compacted != options.Compact {
+			return result, fmt.Errorf("export %s was made with compact set to %t, patch it the same way", previous.Version, previous.Compacted)
+		}
+		manifest.Kind = KindPatch
+		manifest.BaseVersion = previous.Version
+		manifest.Changed, manifest.Removed = diffManifests(previous, manifest)
+		if len(manifest.Changed) == 0 && len(manifest.Removed) == 0 {
+			return result, fmt.Errorf("nothing changed since export %s", previous.Version)
+		}
+		bundled = manifest.Changed
+	}
+
+	name := fmt.Sprintf("%s-%s.zip", archiveName(manifest.Project), manifest.Version)
+	if manifest.Kind == KindPatch {
+		name = fmt.Sprintf("%s-%s-patch.zip", archiveName(manifest.Project), manifest.Version)
+	}
+	archive := fmt.Sprintf("%s/%s", strings.TrimSuffix(strings.ReplaceAll(options.Destination, "\\", "/"), "/"), name)
+	if err := writeArchive(archive, manifest, files, bundled); err != nil {
+		return result, err
+	}
+	result.Archive = archive
+	result.Manifest = manifest
+	return result, nil
+}
+
+// collectFiles reads everything below the project's data folder, leaving out
+// hidden files, editor backups and temporary files, and compacts TOML and
+// JSON when asked
+func collectFiles(dataDirectory string, compact bool) (map[string][]byte, error) {
+	root := filepath.Join(dataDirectory, "data")
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && path != root {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() || editorOnly(entry.Name()) {
+			return nil
+		}
+		relative, err := filepath.Rel(dataDirectory, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if compact {
+			if data, err = compactFile(relative, data); err != nil {
+				return err
+			}
+		}
+		files[relative] = data
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("the project has no data folder to export")
+	}
+	return files, err
+}
+
+// editorOnly reports files the editor leaves next to the data it saves
+func editorOnly(name string) bool {
+	return strings.HasSuffix(name, ".bak") || strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, "~")
+}
+
+// compactFile drops comments and formatting from TOML and whitespace from
+// JSON, other files are kept as they are
+func compactFile(path string, data []byte) ([]byte, error) {
+	switch {
+	case strings.HasSuffix(path, ".toml"):
+		var document map[string]any
+		if err := toml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("error compacting %s: %w", path, err)
+		}
+		return toml.Marshal(document)
+	case strings.HasSuffix(path, ".json"):
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, data); err != nil {
+			return nil, fmt.Errorf("error compacting %s: %w", path, err)
+		}
+		return compacted.Bytes(), nil
+	}
+	return data, nil
+}
+
+// diffManifests lists the files of current that are new or differ from
+// previous, and the files previous had that current no longer has
+func diffManifests(previous Manifest, current Manifest) ([]string, []string) {
+	before := make(map[string]string, len(previous.Files))
+	for _, file := range previous.Files {
+		before[file.Path] = file.Sha256
+	}
+	var changed, removed []string
+	for _, file := range current.Files {
+		if sum, ok := before[file.Path]; !ok || sum != file.Sha256 {
+			changed = append(changed, file.Path)
+		}
+		delete(before, file.Path)
+	}
+	for path := range before {
+		removed = append(removed, path)
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// ReadManifest reads the manifest of an export archive
+func ReadManifest(archive string) (Manifest, error) {
+	var manifest Manifest
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return manifest, fmt.Errorf("error opening export %s: %w", archive, err)
+	}
+	defer reader.Close()
+	file, err := reader.Open(ManifestName)
+	if err != nil {
+		return manifest, fmt.Errorf("%s is not an export, it has no %s", archive, ManifestName)
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("%s of %s is malformed: %w", ManifestName, archive, err)
+	}
+	return manifest, nil
+}
+
+// writeArchive writes the manifest and the bundled files to archive. It is
+// written to a temporary file first so a failed export leaves nothing behind,
+// and never replaces an earlier export.
+func writeArchive(archive string, manifest Manifest, files map[string][]byte, bundled []string) error {
+	if _, err := os.Stat(archive); err == nil {
+		return fmt.Errorf("%s already exists, export under another version", archive)
+	}
+	if err := os.MkdirAll(filepath.Dir(archive), 0755); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(archive), ".export-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	writer := zip.NewWriter(temp)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	if err := addFile(writer, ManifestName, manifestData, manifest.BuiltAt); err != nil {
+		temp.Close()
+		return err
+	}
+	for _, path := range bundled {
+		if err := addFile(writer, path, files[path], manifest.BuiltAt); err != nil {
+			temp.Close()
+			return fmt.Errorf("error bundling %s: %w", path, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), archive)
+}
+
+func addFile(writer *zip.Writer, name string, data []byte, modified time.Time) error {
+	entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, bytes.NewReader(data))
+	return err
+}
+
+// archiveName turns a project name into a file name
+func archiveName(project string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(project))
+	if name == "" {
+		return "project"
+	}
+	return name
+}
+
+// isCommitHash tells whether version is a full git commit SHA
+func isCommitHash(version string) bool {
+	if len(version) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(version)
+	return err == nil
+}
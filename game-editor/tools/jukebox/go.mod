@@ -6,6 +6,8 @@ replace github.com/zenith110/pokemon-engine-tools/models => ../../models
 
 replace github.com/zenith110/pokemon-engine-tools/parsing => ../../parsing
 
+replace github.com/zenith110/pokemon-engine-tools/repository => ../../repository
+
 go 1.22.2
 
 require (
@@ -17,6 +19,7 @@ require (
 require (
 	github.com/Microsoft/go-winio v0.6.1 // indirect
 	github.com/emirpasic/gods v1.18.1 // indirect
+	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
 	github.com/kevinburke/ssh_config v1.2.0 // indirect
@@ -27,7 +30,8 @@ require (
 	github.com/sergi/go-diff v1.3.2-0.20230802210424-5b0b94c5c0d3 // indirect
 	github.com/src-d/gcfg v1.4.0 // indirect
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
-	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250223140129-f44c17210e27 // indirect
+	github.com/zenith110/pokemon-engine-tools/repository v0.0.0-00010101000000-000000000000 // indirect
+	github.com/zenith110/pokemon-go-engine-toml-models v0.0.0-20250721010513-1bbc148091e8 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/mod v0.23.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
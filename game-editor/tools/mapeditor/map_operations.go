@@ -3,15 +3,16 @@ package mapeditor
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 )
 
 func (a *MapEditorApp) CreateMap(mapData coreModels.MapEditerMapData) map[string]any {
+	defer a.app.Journal.Begin("Create map")()
 	// Create the map JSON file
 	for _, mapItem := range mapData.Map {
 		// Create the JSON structure
@@ -117,7 +118,7 @@ func (a *MapEditorApp) CreateMap(mapData coreModels.MapEditerMapData) map[string
 		}
 
 		// Write JSON file
-		if err := os.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
+		if err := repository.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
 			return map[string]any{
 				"success":      false,
 				"errorMessage": fmt.Errorf("error writing map JSON: %w", err),
@@ -126,60 +127,13 @@ func (a *MapEditorApp) CreateMap(mapData coreModels.MapEditerMapData) map[string
 	}
 
 	// Update the TOML file
-	mapTomlPath := fmt.Sprintf("%s/data/toml/maps.toml", a.app.DataDirectory)
-	var existingData coreModels.MapEditerMapData
-
-	file, err := os.Open(mapTomlPath)
-	if err == nil {
-		defer file.Close()
-		fileData, err := io.ReadAll(file)
-		if err == nil && len(fileData) > 0 {
-			// Try to unmarshal existing data
-			if err := toml.Unmarshal(fileData, &existingData); err == nil {
-				// Append new data
-				existingData.Map = append(existingData.Map, mapData.Map...)
-			} else {
-				// Bad TOML, replace with new data
-				existingData = mapData
+	maps := repository.NewMapRepository(a.app.DataDirectory)
+	for _, mapItem := range mapData.Map {
+		if err := maps.Insert(mapItem); err != nil {
+			return map[string]any{
+				"success":      false,
+				"errorMessage": err.Error(),
 			}
-		} else {
-			// File is empty or unreadable, replace with new data
-			existingData = mapData
-		}
-	} else if os.IsNotExist(err) {
-		// File does not exist, use new data
-		existingData = mapData
-	} else {
-		// Some other error opening file
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("Error opening maps.toml: %v", err),
-		}
-	}
-
-	// Marshal to TOML
-	out, err := toml.Marshal(existingData)
-	if err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("Error marshaling maps TOML: %v", err),
-		}
-	}
-
-	// Ensure directory exists
-	mapDir := filepath.Dir(mapTomlPath)
-	if err := os.MkdirAll(mapDir, 0755); err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("Error creating map directory: %v", err),
-		}
-	}
-
-	// Write TOML file
-	if err := os.WriteFile(mapTomlPath, out, 0644); err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("Error writing maps TOML: %v", err),
 		}
 	}
 
@@ -190,6 +144,7 @@ func (a *MapEditorApp) CreateMap(mapData coreModels.MapEditerMapData) map[string
 }
 
 func (a *MapEditorApp) UpdateMapJson(mapData coreModels.MapJsonData) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Edit map %s", mapData.Name))()
 	// Create the file path
 	jsonFilePath := fmt.Sprintf("%s/data/assets/maps/%s.json", a.app.DataDirectory, mapData.Name)
 
@@ -212,7 +167,7 @@ func (a *MapEditorApp) UpdateMapJson(mapData coreModels.MapJsonData) map[string]
 	}
 
 	// Write JSON file
-	if err := os.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
+	if err := repository.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
 		return map[string]any{
 			"success":      false,
 			"errorMessage": fmt.Errorf("error writing map JSON: %w", err),
@@ -226,6 +181,7 @@ func (a *MapEditorApp) UpdateMapJson(mapData coreModels.MapJsonData) map[string]
 }
 
 func (a *MapEditorApp) UpdateMapJsonWithPath(mapData coreModels.MapJsonData, filePath string) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Edit map %s", mapData.Name))()
 	// Use the provided file path
 	jsonFilePath := fmt.Sprintf("%s/%s", a.app.DataDirectory, filePath)
 
@@ -248,7 +204,7 @@ func (a *MapEditorApp) UpdateMapJsonWithPath(mapData coreModels.MapJsonData, fil
 	}
 
 	// Write JSON file
-	if err := os.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
+	if err := repository.WriteFile(jsonFilePath, jsonBytes, 0644); err != nil {
 		return map[string]any{
 			"success":      false,
 			"errorMessage": fmt.Errorf("error writing map JSON: %w", err),
@@ -263,6 +219,7 @@ func (a *MapEditorApp) UpdateMapJsonWithPath(mapData coreModels.MapJsonData, fil
 
 // RenameMapFile renames a map JSON file when the map name changes
 func (a *MapEditorApp) RenameMapFile(oldFilePath string, newFilePath string) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Rename map file %s", oldFilePath))()
 	oldFullPath := fmt.Sprintf("%s/%s", a.app.DataDirectory, oldFilePath)
 	newFullPath := fmt.Sprintf("%s/%s", a.app.DataDirectory, newFilePath)
 
@@ -283,8 +240,15 @@ func (a *MapEditorApp) RenameMapFile(oldFilePath string, newFilePath string) map
 		}
 	}
 
-	// Rename the file
-	if err := os.Rename(oldFullPath, newFullPath); err != nil {
+	// Rename the file, copying it so both halves land in the journal
+	fileData, err := os.ReadFile(oldFullPath)
+	if err == nil {
+		err = repository.WriteFile(newFullPath, fileData, 0644)
+	}
+	if err == nil {
+		err = repository.RemoveFile(oldFullPath)
+	}
+	if err != nil {
 		return map[string]any{
 			"success":      false,
 			"errorMessage": fmt.Errorf("error renaming file: %w", err),
@@ -318,6 +282,7 @@ func (a *MapEditorApp) CheckFileExists(filePath string) map[string]any {
 
 // DeleteMapFile deletes a map JSON file
 func (a *MapEditorApp) DeleteMapFile(filePath string) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete map file %s", filePath))()
 	fullPath := fmt.Sprintf("%s/%s", a.app.DataDirectory, filePath)
 
 	// Check if file exists
@@ -329,7 +294,7 @@ func (a *MapEditorApp) DeleteMapFile(filePath string) map[string]any {
 	}
 
 	// Delete the file
-	if err := os.Remove(fullPath); err != nil {
+	if err := repository.RemoveFile(fullPath); err != nil {
 		return map[string]any{
 			"success":      false,
 			"errorMessage": fmt.Errorf("error deleting file: %w", err),
@@ -343,68 +308,11 @@ func (a *MapEditorApp) DeleteMapFile(filePath string) map[string]any {
 }
 
 func (a *MapEditorApp) UpdateTomlMapEntryByID(updatedMap coreModels.Map) map[string]any {
-	// Read the existing TOML file
-	mapTomlPath := fmt.Sprintf("%s/data/toml/maps.toml", a.app.DataDirectory)
-	var existingData coreModels.MapEditerMapData
-
-	file, err := os.Open(mapTomlPath)
-	if err != nil {
+	defer a.app.Journal.Begin(fmt.Sprintf("Update map %d", updatedMap.ID))()
+	if err := repository.NewMapRepository(a.app.DataDirectory).Replace(updatedMap); err != nil {
 		return map[string]any{
 			"success":      false,
-			"errorMessage": fmt.Errorf("error opening maps.toml: %w", err),
-		}
-	}
-	defer file.Close()
-
-	fileData, err := io.ReadAll(file)
-	if err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Errorf("error reading maps.toml: %w", err),
-		}
-	}
-
-	// Unmarshal existing data
-	if len(fileData) > 0 {
-		if err := toml.Unmarshal(fileData, &existingData); err != nil {
-			return map[string]any{
-				"success":      false,
-				"errorMessage": fmt.Errorf("error parsing existing maps.toml: %w", err),
-			}
-		}
-	}
-
-	// Find and update the map with the specified ID
-	mapFound := false
-	for i, mapItem := range existingData.Map {
-		if mapItem.ID == updatedMap.ID {
-			// Ensure the updated map has the same ID
-			existingData.Map[i] = updatedMap
-			mapFound = true
-			break
-		}
-	}
-
-	if !mapFound {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("map with ID %d not found", updatedMap.ID),
-		}
-	}
-
-	// Marshal and write back to TOML
-	out, err := toml.Marshal(existingData)
-	if err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Errorf("error marshaling updated maps TOML: %w", err),
-		}
-	}
-
-	if err := os.WriteFile(mapTomlPath, out, 0644); err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Errorf("error writing updated maps TOML: %w", err),
+			"errorMessage": err.Error(),
 		}
 	}
 
@@ -415,56 +323,20 @@ func (a *MapEditorApp) UpdateTomlMapEntryByID(updatedMap coreModels.Map) map[str
 }
 
 func (a *MapEditorApp) DeleteMapByID(id int) map[string]any {
-	mapTomlPath := fmt.Sprintf("%s/data/toml/maps.toml", a.app.DataDirectory)
-	var data coreModels.MapEditerMapData
-	var oldMap coreModels.Map
-	// Read the file
-	file, err := os.Open(mapTomlPath)
-	if err != nil {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Error opening file: %v", err)}
-	}
-	defer file.Close()
-
-	fileData, err := io.ReadAll(file)
-	if err != nil {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Error reading file: %v", err)}
-	}
-
-	if err := toml.Unmarshal(fileData, &data); err != nil {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Error parsing TOML: %v", err)}
-	}
-
-	// Filter out the map with the given ID
-	newMaps := make([]coreModels.Map, 0, len(data.Map))
-	mapFound := false
-	for _, m := range data.Map {
-		if m.ID != id {
-			newMaps = append(newMaps, m)
-		} else {
-			oldMap = m
-			mapFound = true
-		}
-	}
-
-	if !mapFound {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Map with ID %d not found", id)}
-	}
-
-	data.Map = newMaps
-
-	// Write back to TOML
-	out, err := toml.Marshal(data)
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete map %d", id))()
+	maps := repository.NewMapRepository(a.app.DataDirectory)
+	oldMap, err := maps.Find(strconv.Itoa(id))
 	if err != nil {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Error marshaling TOML: %v", err)}
+		return map[string]any{"success": false, "errorMessage": err.Error()}
 	}
-	if err := os.WriteFile(mapTomlPath, out, 0644); err != nil {
-		return map[string]any{"success": false, "errorMessage": fmt.Sprintf("Error writing TOML: %v", err)}
+	if err := maps.Delete(strconv.Itoa(id)); err != nil {
+		return map[string]any{"success": false, "errorMessage": err.Error()}
 	}
 
 	// Only try to delete JSON file if map name is not empty
 	if oldMap.Name != "" {
 		jsonFilePath := fmt.Sprintf("%s/data/assets/maps/%s.json", a.app.DataDirectory, oldMap.Name)
-		if removeJsonFileErr := os.Remove(jsonFilePath); removeJsonFileErr != nil {
+		if removeJsonFileErr := repository.RemoveFile(jsonFilePath); removeJsonFileErr != nil {
 			// Log the error but don't fail the operation since TOML was updated successfully
 			fmt.Printf("Warning: Could not delete JSON file %s: %v\n", jsonFilePath, removeJsonFileErr)
 		}
@@ -17,7 +17,10 @@ import (
 )
 
 // TileCache stores loaded tile images
-var tileCache = make(map[string]image.Image)
+var (
+	tileCache   = make(map[string]image.Image)
+	tileCacheMu sync.RWMutex
+)
 
 // SSE clients for progress updates
 var (
@@ -131,7 +134,10 @@ func loadTileImage(imageData string) (image.Image, error) {
 	fmt.Printf("DEBUG: About to check cache\n")
 
 	// Check cache first
-	if cached, exists := tileCache[imageData]; exists {
+	tileCacheMu.RLock()
+	cached, exists := tileCache[imageData]
+	tileCacheMu.RUnlock()
+	if exists {
 		fmt.Printf("Tile found in cache\n")
 		return cached, nil
 	}
@@ -252,7 +258,9 @@ func loadTileImage(imageData string) (image.Image, error) {
 	}
 
 	// Cache the image
+	tileCacheMu.Lock()
 	tileCache[imageData] = img
+	tileCacheMu.Unlock()
 	fmt.Printf("Tile cached successfully\n")
 	return img, nil
 }
@@ -3,25 +3,29 @@ package mapeditor
 import (
 	"encoding/base64"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
 	"time"
 
 	"encoding/json"
 	"image"
+	"strings"
 	"sync"
 
-	"github.com/pelletier/go-toml/v2"
-	"github.com/wailsapp/wails/v3/pkg/application"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 	core "github.com/zenith110/pokemon-engine-tools/tools-core"
 )
 
 // MapEditorApp struct for tileset operations
 type MapEditorApp struct {
-	app      *core.App
-	wailsApp *application.App
+	app    *core.App
+	events EventEmitter
+}
+
+// EventEmitter sends events to the frontend, the Wails application's event
+// manager in the GUI. Without one, as in editor-cli, nothing is sent.
+type EventEmitter interface {
+	Emit(name string, data ...any)
 }
 
 // RenderProgress tracks the progress of map rendering
@@ -43,14 +47,26 @@ var (
 
 // NewMapEditorApp creates a new MapEditorApp struct
 func NewMapEditorApp(app *core.App) *MapEditorApp {
+	// Tiles decoded from a tileset that changed on disk are stale
+	app.Watcher.Subscribe(func(change core.ProjectDataChange) {
+		if strings.HasPrefix(change.File, "data/assets/") {
+			clearTileCache()
+		}
+	})
 	return &MapEditorApp{
 		app: app,
 	}
 }
 
-// SetWailsApp sets the Wails application instance for event emission
-func (a *MapEditorApp) SetWailsApp(wailsApp *application.App) {
-	a.wailsApp = wailsApp
+func clearTileCache() {
+	tileCacheMu.Lock()
+	defer tileCacheMu.Unlock()
+	tileCache = make(map[string]image.Image)
+}
+
+// SetEventEmitter sets where render progress events are emitted
+func (a *MapEditorApp) SetEventEmitter(events EventEmitter) {
+	a.events = events
 }
 
 // RenderMap renders the map using Go backend
@@ -94,9 +110,9 @@ func (a *MapEditorApp) updateRenderProgress(current int, total int, message stri
 	renderProgressMutex.Unlock()
 
 	// Emit progress event to frontend using Wails v3
-	if a.wailsApp != nil {
+	if a.events != nil {
 		progressData, _ := json.Marshal(currentRenderProgress)
-		a.wailsApp.Event.Emit("map-render-progress", string(progressData))
+		a.events.Emit("map-render-progress", string(progressData))
 	}
 }
 
@@ -141,14 +157,14 @@ func (a *MapEditorApp) renderMapInBackground(req RenderRequest) {
 			renderProgressMutex.Unlock()
 
 			// Emit completion event with the rendered map data
-			if a.wailsApp != nil {
+			if a.events != nil {
 				eventData := map[string]any{
 					"success":   true,
 					"imageData": response.ImageData,
 					"message":   "Map rendering completed successfully",
 				}
 				fmt.Printf("Emitting map-render-complete event with imageData length: %d\n", len(response.ImageData))
-				a.wailsApp.Event.Emit("map-render-complete", eventData)
+				a.events.Emit("map-render-complete", eventData)
 			}
 
 			fmt.Printf("Map rendering completed successfully\n")
@@ -164,8 +180,8 @@ func (a *MapEditorApp) renderMapInBackground(req RenderRequest) {
 			renderProgressMutex.Unlock()
 
 			// Emit error event
-			if a.wailsApp != nil {
-				a.wailsApp.Event.Emit("map-render-error", map[string]any{
+			if a.events != nil {
+				a.events.Emit("map-render-error", map[string]any{
 					"success": false,
 					"error":   response.Error,
 					"message": "Map rendering failed",
@@ -191,8 +207,8 @@ func (a *MapEditorApp) renderMapInBackground(req RenderRequest) {
 		renderProgressMutex.Unlock()
 
 		// Emit timeout error event
-		if a.wailsApp != nil {
-			a.wailsApp.Event.Emit("map-render-error", map[string]any{
+		if a.events != nil {
+			a.events.Emit("map-render-error", map[string]any{
 				"success": false,
 				"error":   "Rendering timeout",
 				"message": "Map rendering timed out after 60 seconds",
@@ -208,7 +224,7 @@ func (a *MapEditorApp) StampTile(req StampRequest) StampResponse {
 
 // ClearTileCache clears the tile cache to free memory
 func (a *MapEditorApp) ClearTileCache() map[string]any {
-	tileCache = make(map[string]image.Image)
+	clearTileCache()
 	return map[string]any{
 		"success": true,
 		"message": "Tile cache cleared",
@@ -255,8 +271,8 @@ func (a *MapEditorApp) GetTilesetImageData(filePath string) map[string]any {
 }
 
 func (a *MapEditorApp) CreateTileset(createNewTilesetData coreModels.CreateNewTileset) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Create tileset %s", createNewTilesetData.NameOfTileset))()
 	localProjectTilesetPath := fmt.Sprintf("data/assets/tilesets/%s", createNewTilesetData.FileName)
-	var tilesetData coreModels.TilesetData
 	tileset := coreModels.Tileset{
 		TilesetWidth:       createNewTilesetData.TilesetWidth,
 		TilesetHeight:      createNewTilesetData.TilesetHeight,
@@ -265,65 +281,64 @@ func (a *MapEditorApp) CreateTileset(createNewTilesetData coreModels.CreateNewTi
 		TypeOfTileSet:      createNewTilesetData.TypeOfTileSet,
 		TilesetDescription: createNewTilesetData.Description,
 	}
-	tilesetTomlPath := fmt.Sprintf("%s/data/toml/tilesets.toml", a.app.DataDirectory)
-	tilesetData.Tilesets = append(tilesetData.Tilesets, tileset)
-	var existingTileset coreModels.TilesetData
-	file, err := os.Open(tilesetTomlPath)
-	if err == nil {
-		defer file.Close()
-		fileData, err := io.ReadAll(file)
-		if err == nil && len(fileData) > 0 {
-			// Try to unmarshal existing data
-			if err := toml.Unmarshal(fileData, &existingTileset); err == nil {
-				// Append new data
-				existingTileset.Tilesets = append(existingTileset.Tilesets, tilesetData.Tilesets...)
-			} else {
-				// Bad TOML, replace with new data
-				existingTileset = tilesetData
-			}
-		} else {
-			// File is empty or unreadable, replace with new data
-			existingTileset = tilesetData
-		}
-	} else if os.IsNotExist(err) {
-		// File does not exist, use new data
-		existingTileset = tilesetData
-	} else {
-		// Some other error opening file
+	if err := repository.NewTilesetRepository(a.app.DataDirectory).Insert(tileset); err != nil {
 		return map[string]any{
 			"success":      false,
-			"errorMessage": fmt.Sprintf("Error opening tilesets.toml: %v", err),
+			"errorMessage": err.Error(),
 		}
 	}
 
-	// Marshal to TOML
-	out, err := toml.Marshal(existingTileset)
+	return map[string]any{
+		"success": true,
+		"message": fmt.Sprintf("Successfully created tileset: %s", createNewTilesetData.NameOfTileset),
+	}
+}
+
+// UpdateTileset saves the tileset called name, tileset may rename it
+func (a *MapEditorApp) UpdateTileset(name string, tileset coreModels.Tileset) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Update tileset %s", name))()
+	tilesets := repository.NewTilesetRepository(a.app.DataDirectory)
+	err := tilesets.Update(func(records *[]coreModels.Tileset) error {
+		index := -1
+		for position, record := range *records {
+			switch {
+			case record.Name == name:
+				index = position
+			case record.Name == tileset.Name:
+				return &repository.Error{Kind: repository.ErrDuplicateID, File: tilesets.File(), ID: tileset.Name}
+			}
+		}
+		if index == -1 {
+			return &repository.Error{Kind: repository.ErrNotFound, File: tilesets.File(), ID: name}
+		}
+		(*records)[index] = tileset
+		return nil
+	})
 	if err != nil {
 		return map[string]any{
 			"success":      false,
-			"errorMessage": fmt.Sprintf("Error marshaling tileset TOML: %v", err),
+			"errorMessage": err.Error(),
 		}
 	}
 
-	// Ensure directory exists
-	tilesetDir := filepath.Dir(tilesetTomlPath)
-	if err := os.MkdirAll(tilesetDir, 0755); err != nil {
-		return map[string]any{
-			"success":      false,
-			"errorMessage": fmt.Sprintf("Error creating tileset directory: %v", err),
-		}
+	return map[string]any{
+		"success": true,
+		"message": fmt.Sprintf("Successfully updated tileset: %s", tileset.Name),
 	}
+}
 
-	// Write TOML file
-	if err := os.WriteFile(tilesetTomlPath, out, 0644); err != nil {
+// DeleteTileset removes a tileset from tilesets.toml, its image is kept
+func (a *MapEditorApp) DeleteTileset(name string) map[string]any {
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete tileset %s", name))()
+	if err := repository.NewTilesetRepository(a.app.DataDirectory).Delete(name); err != nil {
 		return map[string]any{
 			"success":      false,
-			"errorMessage": fmt.Sprintf("Error writing tileset TOML: %v", err),
+			"errorMessage": err.Error(),
 		}
 	}
 
 	return map[string]any{
 		"success": true,
-		"message": fmt.Sprintf("Successfully created tileset: %s", createNewTilesetData.NameOfTileset),
+		"message": fmt.Sprintf("Successfully deleted tileset: %s", name),
 	}
 }
@@ -1,14 +1,14 @@
 package moveeditor
 
 import (
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"slices"
 	"strconv"
+	"strings"
 
-	"github.com/pelletier/go-toml/v2"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 	core "github.com/zenith110/pokemon-engine-tools/tools-core"
 	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
 )
@@ -23,67 +23,115 @@ func NewMoveEditorApp(app *core.App) *MoveEditorApp {
 		app: app,
 	}
 }
-func ParseMovesFile(file *os.File) Models.AllMoves {
-	var moves Models.AllMoves
+func (a *MoveEditorApp) ParseMoves() (Models.AllMoves, error) {
+	return repository.NewMoveRepository(a.app.DataDirectory).Load()
+}
 
-	b, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
+func (a *MoveEditorApp) UpdateMove(updatedMove coreModels.UpdatedMove) error {
+	if err := a.checkMoveType(updatedMove.Name, updatedMove.Type); err != nil {
+		return err
 	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Update move %s", updatedMove.Id))()
+	return repository.NewMoveRepository(a.app.DataDirectory).Modify(updatedMove.Id, func(move *Models.Move) error {
+		move.Accuracy = updatedMove.Accuracy
+		move.Pp = updatedMove.PP
+		move.Power = updatedMove.Power
+		move.Type = updatedMove.Type
+		move.Name = updatedMove.Name
+		return nil
+	})
+}
 
-	err = toml.Unmarshal(b, &moves)
-	if err != nil {
-		panic(err)
+// CreateMove adds a move to moves.toml and returns its ID. A move without an
+// ID gets one past the highest, a taken ID is refused.
+func (a *MoveEditorApp) CreateMove(move Models.Move) (int, error) {
+	move.Name = strings.TrimSpace(move.Name)
+	if move.Name == "" {
+		return 0, errors.New("a move needs a name")
 	}
-	return moves
-}
-func (a *MoveEditorApp) ParseMoves() Models.AllMoves {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/moves.toml", a.app.DataDirectory))
+	if err := a.checkMoveType(move.Name, move.Type); err != nil {
+		return 0, err
+	}
+	moves := repository.NewMoveRepository(a.app.DataDirectory)
+	defer a.app.Journal.Begin(fmt.Sprintf("Create move %s", move.Name))()
+	err := moves.Update(func(records *[]Models.Move) error {
+		if move.ID == 0 {
+			for _, record := range *records {
+				move.ID = max(move.ID, record.ID)
+			}
+			move.ID++
+		}
+		if slices.ContainsFunc(*records, func(record Models.Move) bool { return record.ID == move.ID }) {
+			return &repository.Error{Kind: repository.ErrDuplicateID, File: moves.File(), ID: strconv.Itoa(move.ID)}
+		}
+		*records = append(*records, move)
+		return nil
+	})
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
-	defer file.Close()
-	moves := ParseMovesFile(file)
-	return moves
+	return move.ID, nil
 }
 
-func (a *MoveEditorApp) UpdateMove(updatedMove coreModels.UpdatedMove) {
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/moves.toml", a.app.DataDirectory))
+// checkMoveType refuses a move type that is not one of the project's types
+func (a *MoveEditorApp) checkMoveType(name string, moveType string) error {
+	definitions, err := core.ProjectTypes(a.app.DataDirectory)
 	if err != nil {
-		log.Fatalf("Error has occured while opening file to edit %v", err)
+		return err
+	}
+	if !slices.ContainsFunc(definitions, func(definition coreModels.TypeDefinition) bool { return strings.EqualFold(definition.Name, moveType) }) {
+		return fmt.Errorf("%s has the unknown type %q", name, moveType)
 	}
+	return nil
+}
 
-	defer file.Close()
-	var moves Models.AllMoves
-	bytes, err := io.ReadAll(file)
+// DeleteMove removes a move from moves.toml, it is refused while species
+// learn it or trainers' pokemon know it
+func (a *MoveEditorApp) DeleteMove(id string) error {
+	moves := repository.NewMoveRepository(a.app.DataDirectory)
+	move, err := moves.Find(id)
 	if err != nil {
-		fmt.Printf("Error has occured reading data %v", err)
+		return err
 	}
-	err = toml.Unmarshal(bytes, &moves)
+	users, err := a.moveUsers(move.Name)
 	if err != nil {
-		fmt.Printf("Error has occured reading unmarshling into struct %v\n", err)
+		return err
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("move %s is still used by %s", move.Name, strings.Join(users, ", "))
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete move %s", move.Name))()
+	return moves.Delete(id)
+}
+
+// moveUsers names the species that learn the move called name and the
+// trainers whose party or party variants know it
+func (a *MoveEditorApp) moveUsers(name string) ([]string, error) {
+	var users []string
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return nil, err
 	}
-	for move := range moves.Move {
-		id := strconv.Itoa(moves.Move[move].ID)
-		if id == updatedMove.Id {
-			moves.Move[move].Accuracy = updatedMove.Accuracy
-			moves.Move[move].Pp = updatedMove.PP
-			moves.Move[move].Power = updatedMove.Power
-			moves.Move[move].Type = updatedMove.Type
-			moves.Move[move].Name = updatedMove.Name
+	for _, pokemon := range species {
+		if slices.ContainsFunc(pokemon.Moves, func(move Models.Moves) bool { return strings.EqualFold(move.Name, name) }) {
+			users = append(users, pokemon.Species)
 		}
 	}
-	data, err := toml.Marshal(moves)
-	if err != nil {
-		panic(fmt.Errorf("error had occured while creating move data!\n%v", err))
+	trainers, err := repository.NewTrainerRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return nil, err
 	}
-	os.Remove(fmt.Sprintf("%s/data/toml/moves.toml", a.app.DataDirectory))
-	f, err := os.OpenFile(fmt.Sprintf("%s/data/toml/moves.toml", a.app.DataDirectory), os.O_CREATE, 0644)
-	if err != nil {
-		log.Fatalf("Error occured while opening file %v\n", err)
+	knows := func(pokemon coreModels.TrainerPokemon) bool {
+		return slices.ContainsFunc(pokemon.Moves, func(move string) bool { return strings.EqualFold(move, name) })
 	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v\n", err)
+	for _, trainer := range trainers {
+		used := slices.ContainsFunc(trainer.Pokemons, knows)
+		for _, variant := range trainer.Variants {
+			used = used || slices.ContainsFunc(variant.Pokemons, knows)
+		}
+		if used {
+			users = append(users, "trainer "+trainer.Name)
+		}
 	}
+	return users, nil
 }
@@ -8,17 +8,16 @@ import (
 	"image/png"
 	"io"
 	"io/fs"
-	"log"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/andybons/gogif"
-	"github.com/pelletier/go-toml/v2"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
 	parsing "github.com/zenith110/pokemon-engine-tools/parsing"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	"github.com/zenith110/pokemon-engine-tools/tools-core/dialogs"
 	"github.com/zenith110/pokemon-go-engine-toml-models/models"
 )
 
@@ -32,8 +31,25 @@ func NewOverworldEditorApp(app *core.App) *OverworldEditorApp {
 		app: app,
 	}
 }
-func (a *OverworldEditorApp) ParseOverworldData() {
-
+func (a *OverworldEditorApp) ParseOverworldData() ([]coreModels.OverworldDataJson, error) {
+	overworlds, err := repository.NewOverworldRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return nil, err
+	}
+	var overworldsData []coreModels.OverworldDataJson
+	for _, overworld := range overworlds {
+		overworldsData = append(overworldsData, coreModels.OverworldDataJson{
+			ID:             overworld.ID,
+			OverworldId:    overworld.ID,
+			Name:           overworld.Name,
+			IsPlayer:       overworld.IsPlayer,
+			SwimmingFrames: overworld.Swimming.OverworldDirectionFrames,
+			SurfingFrames:  overworld.Surfing.OverworldDirectionFrames,
+			RunningFrames:  overworld.Running.OverworldDirectionFrames,
+			WalkingFrames:  overworld.Walking.OverworldDirectionFrames,
+		})
+	}
+	return overworldsData, nil
 }
 func (a *OverworldEditorApp) CheckOverworldId() int {
 	// Gets all the files of a directory
@@ -86,15 +102,7 @@ func CreateImagesArray(filePath string) []image.Image {
 
 // Creates a frame, returns base64 version of the frame + base location in game engine
 func (a *OverworldEditorApp) CreateOverworldFrame(frameSetName string, frame int, overworldId int, direction string) map[string]string {
-	selection, err := runtime.OpenFileDialog(a.app.Ctx, runtime.OpenDialogOptions{
-		Title: "Select overworld frame",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "Images (*.png)",
-				Pattern:     "*.png",
-			},
-		},
-	})
+	selection, err := dialogs.File(a.app.Ctx, "Select overworld frame", "Images (*.png)", "*.png")
 	if err != nil {
 		panic(fmt.Errorf("error has occured while updating trainer sprite"))
 	}
@@ -220,11 +228,31 @@ func (a *OverworldEditorApp) CreteOverworldGif(frameSetName string, frame int, o
 
 }
 
-func (a *OverworldEditorApp) CreateOverworldTomlEntry(overworldData coreModels.OverworldDataJson) {
-	fmt.Print(overworldData)
-	var overworlds []models.Overworld
+func (a *OverworldEditorApp) CreateOverworldTomlEntry(overworldData coreModels.OverworldDataJson) error {
+	defer a.app.Journal.Begin(fmt.Sprintf("Create overworld %s", overworldData.Name))()
+	return repository.NewOverworldRepository(a.app.DataDirectory).Insert(overworldRecord(overworldData))
+}
 
-	overworld := models.Overworld{
+// UpdateOverworldTomlEntry replaces the overworld with overworldData's ID
+func (a *OverworldEditorApp) UpdateOverworldTomlEntry(overworldData coreModels.OverworldDataJson) error {
+	defer a.app.Journal.Begin(fmt.Sprintf("Update overworld %s", overworldData.Name))()
+	return repository.NewOverworldRepository(a.app.DataDirectory).Replace(overworldRecord(overworldData))
+}
+
+// DeleteOverworld removes an overworld from overworlds.toml, its frames in
+// data/assets/overworlds are kept
+func (a *OverworldEditorApp) DeleteOverworld(id string) error {
+	overworlds := repository.NewOverworldRepository(a.app.DataDirectory)
+	overworld, err := overworlds.Find(id)
+	if err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete overworld %s", overworld.Name))()
+	return overworlds.Delete(id)
+}
+
+func overworldRecord(overworldData coreModels.OverworldDataJson) models.Overworld {
+	return models.Overworld{
 		Name:     overworldData.Name,
 		ID:       overworldData.ID,
 		IsPlayer: overworldData.IsPlayer,
@@ -232,7 +260,7 @@ func (a *OverworldEditorApp) CreateOverworldTomlEntry(overworldData coreModels.O
 			OverworldDirectionFrames: overworldData.SwimmingFrames,
 		},
 		Surfing: models.Surfing{
-			OverworldDirectionFrames: overworldData.SwimmingFrames,
+			OverworldDirectionFrames: overworldData.SurfingFrames,
 		},
 		Running: models.Running{
 			OverworldDirectionFrames: overworldData.RunningFrames,
@@ -241,22 +269,4 @@ func (a *OverworldEditorApp) CreateOverworldTomlEntry(overworldData coreModels.O
 			OverworldDirectionFrames: overworldData.WalkingFrames,
 		},
 	}
-	overworlds = append(overworlds, overworld)
-	overworldsHolder := models.OverworldsHolder{
-		Overworlds: overworlds,
-	}
-	data, err := toml.Marshal(overworldsHolder)
-	if err != nil {
-		fmt.Printf("error while creating overworld data! %v\n", err)
-	}
-
-	f, err := os.OpenFile(fmt.Sprintf("%s/data/assets/toml/overworlds.toml", a.app.DataDirectory), os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		log.Fatalf("Error occured while opening file %v\n", err)
-
-	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v\n", err)
-	}
 }
@@ -0,0 +1,314 @@
+package pokemoneditor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// CreatePokemon adds a species to pokemon.toml under the next free numeric ID
+// and returns that ID
+func (a *PokemonEditorApp) CreatePokemon(species models.SpeciesJson) (string, error) {
+	if err := a.checkSpecies(species); err != nil {
+		return "", err
+	}
+	var id string
+	defer a.app.Journal.Begin(fmt.Sprintf("Create pokemon %s", strings.TrimSpace(species.Name)))()
+	err := repository.NewPokemonRepository(a.app.DataDirectory).Update(func(records *[]Models.Pokemon) error {
+		id = nextSpeciesID(*records)
+		*records = append(*records, speciesRecord(Models.Pokemon{ID: id}, species))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdatePokemon saves the name, types, dex entry, base stats, abilities and
+// moves of a species, its evolutions and assets are left as they are
+func (a *PokemonEditorApp) UpdatePokemon(id string, species models.SpeciesJson) error {
+	if err := a.checkSpecies(species); err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Update pokemon %s", id))()
+	return repository.NewPokemonRepository(a.app.DataDirectory).Modify(id, func(pokemon *Models.Pokemon) error {
+		*pokemon = speciesRecord(*pokemon, species)
+		return nil
+	})
+}
+
+// ClonePokemon copies a species under the next free numeric ID and returns the
+// copy's ID. The copy keeps the original's evolutions, and its sprites, icon
+// and cry are copied to the new ID, assets the original lacks stay missing.
+func (a *PokemonEditorApp) ClonePokemon(id string) (string, error) {
+	pokemons := repository.NewPokemonRepository(a.app.DataDirectory)
+	pokemon, err := pokemons.Find(id)
+	if err != nil {
+		return "", err
+	}
+	var clone string
+	defer a.app.Journal.Begin(fmt.Sprintf("Clone pokemon %s", pokemon.Species))()
+	err = pokemons.Update(func(records *[]Models.Pokemon) error {
+		index := slices.IndexFunc(*records, func(record Models.Pokemon) bool { return record.ID == id })
+		if index == -1 {
+			return &repository.Error{Kind: repository.ErrNotFound, File: pokemons.File(), ID: id}
+		}
+		copied := (*records)[index]
+		clone = nextSpeciesID(*records)
+		copied.ID = clone
+		copied.Species = fmt.Sprintf("%s (copy)", copied.Species)
+		copied.Types = slices.Clone(copied.Types)
+		copied.Abilities = slices.Clone(copied.Abilities)
+		copied.Moves = slices.Clone(copied.Moves)
+		copied.Evolutions = slices.Clone(copied.Evolutions)
+		for evolution := range copied.Evolutions {
+			copied.Evolutions[evolution].EvolutionID = uuid.NewString()
+		}
+		*records = append(*records, copied)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := a.copyAssets(id, clone); err != nil {
+		return clone, fmt.Errorf("%s was cloned as %s without all of its assets: %w", pokemon.Species, clone, err)
+	}
+	return clone, nil
+}
+
+// copyAssets copies the sprites, icon and cry of species from to species to,
+// skipping the ones from does not have
+func (a *PokemonEditorApp) copyAssets(from string, to string) error {
+	targets := core.PokemonAssets(to).All()
+	for index, asset := range core.PokemonAssets(from).All() {
+		data, err := os.ReadFile(fmt.Sprintf("%s/data/assets/%s", a.app.DataDirectory, asset))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := repository.WriteFile(fmt.Sprintf("%s/data/assets/%s", a.app.DataDirectory, targets[index]), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPokemonReferences names the trainers, species and maps that still use a
+// species, deleting it is refused unless they are cascaded
+func (a *PokemonEditorApp) GetPokemonReferences(id string) ([]string, error) {
+	references, err := a.speciesReferences(id)
+	if err != nil {
+		return nil, err
+	}
+	return references.all(), nil
+}
+
+// DeletePokemon removes a species from pokemon.toml. While trainers, other
+// species' evolutions or map encounters use it the delete is refused, unless
+// cascade is set, which removes those party pokemon, evolutions and encounters
+// too.
+func (a *PokemonEditorApp) DeletePokemon(id string, cascade bool) error {
+	pokemons := repository.NewPokemonRepository(a.app.DataDirectory)
+	pokemon, err := pokemons.Find(id)
+	if err != nil {
+		return err
+	}
+	references, err := a.speciesReferences(id)
+	if err != nil {
+		return err
+	}
+	if users := references.all(); len(users) > 0 && !cascade {
+		return fmt.Errorf("pokemon %s is still used by %s", pokemon.Species, strings.Join(users, ", "))
+	}
+
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete pokemon %s", pokemon.Species))()
+	if len(references.trainers) > 0 {
+		err := repository.NewTrainerRepository(a.app.DataDirectory).Update(func(trainers *[]models.Trainer) error {
+			for index := range *trainers {
+				trainer := &(*trainers)[index]
+				trainer.Pokemons = slices.DeleteFunc(trainer.Pokemons, func(p models.TrainerPokemon) bool { return p.ID == id })
+				for variant := range trainer.Variants {
+					trainer.Variants[variant].Pokemons = slices.DeleteFunc(trainer.Variants[variant].Pokemons, func(p models.TrainerPokemon) bool { return p.ID == id })
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(references.maps) > 0 {
+		err := repository.NewMapRepository(a.app.DataDirectory).Update(func(maps *[]models.Map) error {
+			for index := range *maps {
+				mapData := &(*maps)[index]
+				mapData.GrassEncounters = slices.DeleteFunc(mapData.GrassEncounters, func(e models.GrassEncounters) bool { return e.ID == id })
+				mapData.WaterEncounters = slices.DeleteFunc(mapData.WaterEncounters, func(e models.WaterEncounters) bool { return e.ID == id })
+				mapData.CaveEncounters = slices.DeleteFunc(mapData.CaveEncounters, func(e models.CaveEncounters) bool { return e.ID == id })
+				mapData.FishingEncounters = slices.DeleteFunc(mapData.FishingEncounters, func(e models.FishingEncounters) bool { return e.ID == id })
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return pokemons.Update(func(records *[]Models.Pokemon) error {
+		index := slices.IndexFunc(*records, func(record Models.Pokemon) bool { return record.ID == id })
+		if index == -1 {
+			return &repository.Error{Kind: repository.ErrNotFound, File: pokemons.File(), ID: id}
+		}
+		*records = slices.Delete(*records, index, index+1)
+		for index := range *records {
+			(*records)[index].Evolutions = slices.DeleteFunc((*records)[index].Evolutions, func(e Models.Evolutions) bool { return e.PokemonID == id })
+		}
+		return nil
+	})
+}
+
+// references are the records that use a species, by data file
+type references struct {
+	trainers []string
+	pokemon  []string
+	maps     []string
+}
+
+func (r references) all() []string {
+	var users []string
+	for _, name := range r.trainers {
+		users = append(users, "trainer "+name)
+	}
+	for _, name := range r.pokemon {
+		users = append(users, "pokemon "+name)
+	}
+	for _, name := range r.maps {
+		users = append(users, "map "+name)
+	}
+	return users
+}
+
+// speciesReferences collects the trainers whose party or party variants,
+// the other species whose evolutions and the maps whose encounters use id
+func (a *PokemonEditorApp) speciesReferences(id string) (references, error) {
+	var result references
+	usesSpecies := func(p models.TrainerPokemon) bool { return p.ID == id }
+
+	trainers, err := repository.NewTrainerRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return result, err
+	}
+	for _, trainer := range trainers {
+		used := slices.ContainsFunc(trainer.Pokemons, usesSpecies)
+		for _, variant := range trainer.Variants {
+			used = used || slices.ContainsFunc(variant.Pokemons, usesSpecies)
+		}
+		if used {
+			result.trainers = append(result.trainers, trainer.Name)
+		}
+	}
+
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return result, err
+	}
+	for _, pokemon := range species {
+		if pokemon.ID != id && slices.ContainsFunc(pokemon.Evolutions, func(e Models.Evolutions) bool { return e.PokemonID == id }) {
+			result.pokemon = append(result.pokemon, pokemon.Species)
+		}
+	}
+
+	maps, err := repository.NewMapRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return result, err
+	}
+	for _, mapData := range maps {
+		if slices.ContainsFunc(mapData.GrassEncounters, func(e models.GrassEncounters) bool { return e.ID == id }) ||
+			slices.ContainsFunc(mapData.WaterEncounters, func(e models.WaterEncounters) bool { return e.ID == id }) ||
+			slices.ContainsFunc(mapData.CaveEncounters, func(e models.CaveEncounters) bool { return e.ID == id }) ||
+			slices.ContainsFunc(mapData.FishingEncounters, func(e models.FishingEncounters) bool { return e.ID == id }) {
+			result.maps = append(result.maps, mapData.Name)
+		}
+	}
+	return result, nil
+}
+
+// checkSpecies refuses species without a name, with other than one or two
+// types of the project or with a base stat outside 1-255
+func (a *PokemonEditorApp) checkSpecies(species models.SpeciesJson) error {
+	if strings.TrimSpace(species.Name) == "" {
+		return errors.New("a pokemon needs a species name")
+	}
+	if len(species.Types) == 0 || len(species.Types) > 2 {
+		return fmt.Errorf("%s needs one or two types, not %d", species.Name, len(species.Types))
+	}
+	definitions, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return err
+	}
+	for _, pokemonType := range species.Types {
+		if !slices.ContainsFunc(definitions, func(definition models.TypeDefinition) bool { return strings.EqualFold(definition.Name, pokemonType) }) {
+			return fmt.Errorf("%s has the unknown type %q", species.Name, pokemonType)
+		}
+	}
+	stats := map[string]int{
+		"hp":              species.HP,
+		"attack":          species.Attack,
+		"defense":         species.Defense,
+		"special attack":  species.SpecialAttack,
+		"special defense": species.SpecialDefense,
+		"speed":           species.Speed,
+	}
+	for _, stat := range []string{"hp", "attack", "defense", "special attack", "special defense", "speed"} {
+		if stats[stat] < 1 || stats[stat] > 255 {
+			return fmt.Errorf("%s has a base %s of %d, it has to be 1-255", species.Name, stat, stats[stat])
+		}
+	}
+	return nil
+}
+
+// speciesRecord puts the editable fields of species on pokemon
+func speciesRecord(pokemon Models.Pokemon, species models.SpeciesJson) Models.Pokemon {
+	pokemon.Species = strings.TrimSpace(species.Name)
+	pokemon.Types = species.Types
+	pokemon.DexEntry = species.DexEntry
+	pokemon.Abilities = species.Abilities
+	pokemon.Moves = species.Moves
+	pokemon.Stats = Models.Stats{
+		Hp:             species.HP,
+		Attack:         species.Attack,
+		Defense:        species.Defense,
+		SpecialAttack:  species.SpecialAttack,
+		SpecialDefense: species.SpecialDefense,
+		Speed:          species.Speed,
+	}
+	return pokemon
+}
+
+// nextSpeciesID is one past the highest numeric species ID, zero padded like
+// the project's IDs when they are ("001")
+func nextSpeciesID(records []Models.Pokemon) string {
+	highest, width := 0, 0
+	for _, record := range records {
+		number, err := strconv.Atoi(record.ID)
+		if err != nil {
+			continue
+		}
+		highest = max(highest, number)
+		if strings.HasPrefix(record.ID, "0") {
+			width = max(width, len(record.ID))
+		}
+	}
+	return fmt.Sprintf("%0*d", width, highest+1)
+}
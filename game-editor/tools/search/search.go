@@ -0,0 +1,238 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+// defaultLimit caps Search results when the caller passes no limit
+const defaultLimit = 100
+
+// snippetContext is how many bytes of text a snippet shows around a match
+const snippetContext = 30
+
+// Hit is one search result or usage. Type and ID name the record as the
+// project index does, Field is where the match or reference is.
+type Hit struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+type SearchApp struct {
+	app *core.App
+}
+
+// NewSearchApp creates a new SearchApp struct
+func NewSearchApp(app *core.App) *SearchApp {
+	return &SearchApp{
+		app: app,
+	}
+}
+
+// field is one searchable text of a document
+type field struct {
+	name string
+	text string
+}
+
+// document is everything searchable about one record
+type document struct {
+	entityType string
+	id         string
+	name       string
+	fields     []field
+}
+
+// Search finds query, ignoring case, in the species, moves, abilities, items,
+// trainers, trainer classes, maps, encounters and songs of the current
+// project. Name matches come first. limit <= 0 returns up to 100 hits.
+func (a *SearchApp) Search(query string, limit int) ([]Hit, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []Hit{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	documents, err := a.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	type rankedHit struct {
+		Hit
+		rank int
+	}
+	var ranked []rankedHit
+	for _, document := range documents {
+		for _, field := range document.fields {
+			position := strings.Index(strings.ToLower(field.text), query)
+			if position == -1 {
+				continue
+			}
+			rank := 3
+			if field.name == "name" {
+				switch {
+				case len(field.text) == len(query):
+					rank = 0
+				case position == 0:
+					rank = 1
+				default:
+					rank = 2
+				}
+			}
+			ranked = append(ranked, rankedHit{
+				Hit: Hit{
+					Type:    document.entityType,
+					ID:      document.id,
+					Name:    document.name,
+					Field:   field.name,
+					Snippet: snippet(field.text, position, len(query)),
+				},
+				rank: rank,
+			})
+			// One hit per record, for its best field
+			break
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
+	})
+
+	hits := make([]Hit, 0, min(limit, len(ranked)))
+	for _, hit := range ranked {
+		if len(hits) == limit {
+			break
+		}
+		hits = append(hits, hit.Hit)
+	}
+	return hits, nil
+}
+
+// snippet cuts the text around a match, marking cut ends with an ellipsis
+func snippet(text string, position int, length int) string {
+	start := max(position-snippetContext, 0)
+	end := min(position+length+snippetContext, len(text))
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	result := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(text) {
+		result += "…"
+	}
+	return result
+}
+
+// documents flattens the project index into searchable documents. Data files
+// that fail to parse are skipped so one broken file does not break search.
+func (a *SearchApp) documents() ([]document, error) {
+	if a.app.DataDirectory == "" {
+		return nil, errors.New("no project selected")
+	}
+	data, err := loadProject(a.app)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []document
+	abilities := make(map[string]bool)
+	for _, pokemon := range data.pokemon {
+		documents = append(documents, document{"pokemon", pokemon.ID, pokemon.Species, []field{
+			{"name", pokemon.Species},
+			{"types", strings.Join(pokemon.Types, " ")},
+			{"dexEntry", pokemon.DexEntry},
+		}})
+		for _, ability := range pokemon.Abilities {
+			if ability.Name == "" || abilities[strings.ToLower(ability.Name)] {
+				continue
+			}
+			abilities[strings.ToLower(ability.Name)] = true
+			documents = append(documents, document{"abilities", ability.Name, ability.Name, []field{{"name", ability.Name}}})
+		}
+	}
+	for _, move := range data.moves {
+		fields := []field{{"name", move.Name}, {"type", move.Type}, {"kindOfMove", move.KindOfMove}}
+		for _, description := range move.Descriptions {
+			fields = append(fields, field{"descriptions", description.Description})
+		}
+		for _, effect := range move.Effects {
+			fields = append(fields, field{"effects", effect.EffectText})
+		}
+		documents = append(documents, document{"moves", fmt.Sprint(move.ID), move.Name, fields})
+	}
+	for _, heldItem := range data.heldItems {
+		fields := []field{{"name", heldItem.Name}}
+		for _, functionality := range heldItem.Functionality {
+			fields = append(fields, field{"functionality", functionality.Description})
+		}
+		documents = append(documents, document{"helditems", heldItem.Name, heldItem.Name, fields})
+	}
+	for _, trainer := range data.trainers {
+		fields := []field{{"name", trainer.Name}, {"classType", trainer.ClassType}, {"sprite", trainer.Sprite}}
+		for _, party := range trainerParties(trainer) {
+			for _, pokemon := range party.pokemon {
+				fields = append(fields, field{"pokemon", pokemon.Species})
+			}
+		}
+		documents = append(documents, document{"trainers", trainer.ID, trainer.Name, fields})
+	}
+	for _, trainerClass := range data.trainerClasses {
+		documents = append(documents, document{"trainerclasses", trainerClass.Name, trainerClass.Name, []field{
+			{"name", trainerClass.Name},
+			{"music", trainerClass.Music},
+			{"encounterMusic", trainerClass.EncounterMusic},
+			{"sprite", trainerClass.Sprite},
+		}})
+	}
+	for _, mapData := range data.maps {
+		fields := []field{{"name", mapData.Name}}
+		for _, properties := range mapData.Properties {
+			fields = append(fields,
+				field{"properties.description", properties.Description},
+				field{"properties.typeOfMap", properties.TypeOfMap},
+				field{"properties.bgMusic", properties.BgMusic},
+			)
+		}
+		documents = append(documents, document{"maps", fmt.Sprint(mapData.ID), mapData.Name, fields})
+		for _, encounter := range encounters(mapData) {
+			documents = append(documents, document{"encounters", encounter.key(mapData.ID), fmt.Sprintf("%s on %s", encounter.name, mapData.Name), []field{
+				{"name", encounter.name},
+				{"timeOfDayToCatch", encounter.timeOfDay},
+			}})
+		}
+	}
+	for _, song := range songs(a.app.DataDirectory) {
+		documents = append(documents, document{"songs", song, song, []field{{"name", song}}})
+	}
+	return documents, nil
+}
+
+// songs lists the files of data/assets/music
+func songs(dataDirectory string) []string {
+	entries, err := os.ReadDir(fmt.Sprintf("%s/data/assets/music", dataDirectory))
+	if err != nil {
+		return nil
+	}
+	var songs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			songs = append(songs, entry.Name())
+		}
+	}
+	return songs
+}
@@ -0,0 +1,271 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// project is the current project as read from the index
+type project struct {
+	pokemon        []Models.Pokemon
+	moves          []Models.Move
+	heldItems      []Models.HeldItems
+	trainers       []coreModels.Trainer
+	trainerClasses []coreModels.TrainerClass
+	maps           []coreModels.Map
+}
+
+func loadProject(app *core.App) (project, error) {
+	var data project
+	var err error
+	if data.pokemon, err = records[Models.Pokemon](app, "pokemon"); err != nil {
+		return data, err
+	}
+	if data.moves, err = records[Models.Move](app, "moves"); err != nil {
+		return data, err
+	}
+	if data.heldItems, err = records[Models.HeldItems](app, "helditems"); err != nil {
+		return data, err
+	}
+	if data.trainers, err = records[coreModels.Trainer](app, "trainers"); err != nil {
+		return data, err
+	}
+	if data.trainerClasses, err = records[coreModels.TrainerClass](app, "trainerclasses"); err != nil {
+		return data, err
+	}
+	if data.maps, err = records[coreModels.Map](app, "maps"); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// records returns the typed records of entityType, or none when its data file
+// is malformed
+func records[R any](app *core.App, entityType string) ([]R, error) {
+	entries, err := app.Index.All(entityType)
+	if err != nil {
+		if app.DataDirectory == "" {
+			return nil, err
+		}
+		fmt.Printf("skipping %s while searching: %v\n", entityType, err)
+		return nil, nil
+	}
+	result := make([]R, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.Record.(R))
+	}
+	return result, nil
+}
+
+// encounter is one row of any of a map's encounter tables
+type encounter struct {
+	table     string
+	index     int
+	id        string
+	name      string
+	minLevel  int
+	maxLevel  int
+	rarity    int
+	timeOfDay string
+}
+
+func (e encounter) key(mapID int) string {
+	return fmt.Sprintf("%d/%s/%d", mapID, e.table, e.index)
+}
+
+func encounters(mapData coreModels.Map) []encounter {
+	var result []encounter
+	for index, e := range mapData.GrassEncounters {
+		result = append(result, encounter{"grassEncounters", index, e.ID, e.Name, e.MinLevel, e.MaxLevel, e.Rarity, e.TimeOfDayToCatch})
+	}
+	for index, e := range mapData.WaterEncounters {
+		result = append(result, encounter{"waterEncounters", index, e.ID, e.Name, e.MinLevel, e.MaxLevel, e.Rarity, e.TimeOfDayToCatch})
+	}
+	for index, e := range mapData.CaveEncounters {
+		result = append(result, encounter{"caveEncounters", index, e.ID, e.Name, e.MinLevel, e.MaxLevel, e.Rarity, e.TimeOfDayToCatch})
+	}
+	for index, e := range mapData.FishingEncounters {
+		result = append(result, encounter{"fishingEncounters", index, e.ID, e.Name, e.MinLevel, e.MaxLevel, e.Rarity, e.TimeOfDayToCatch})
+	}
+	return result
+}
+
+// FindUsages lists every record that refers to the given one. entityType is
+// moves (by ID or name), helditems, pokemon, songs and trainersprites (by
+// file name), abilities, trainerclasses or types.
+func (a *SearchApp) FindUsages(entityType string, id string) ([]Hit, error) {
+	data, err := loadProject(a.app)
+	if err != nil {
+		return nil, err
+	}
+	hits := []Hit{}
+	switch entityType {
+	case "moves":
+		name := id
+		for _, move := range data.moves {
+			if strconv.Itoa(move.ID) == id {
+				name = move.Name
+				break
+			}
+		}
+		for _, trainer := range data.trainers {
+			for _, party := range trainerParties(trainer) {
+				for slot, pokemon := range party.pokemon {
+					for _, move := range pokemon.Moves {
+						if strings.EqualFold(move, name) {
+							hits = append(hits, trainerHit(trainer, party.field+".moves", slot, pokemon))
+						}
+					}
+				}
+			}
+		}
+		for _, pokemon := range data.pokemon {
+			for _, move := range pokemon.Moves {
+				if strings.EqualFold(move.Name, name) {
+					hits = append(hits, Hit{"pokemon", pokemon.ID, pokemon.Species, "moves", fmt.Sprintf("learned at level %d (%s)", move.Level, move.Method)})
+				}
+			}
+		}
+	case "helditems":
+		for _, trainer := range data.trainers {
+			for _, party := range trainerParties(trainer) {
+				for slot, pokemon := range party.pokemon {
+					if strings.EqualFold(pokemon.HeldItem, id) {
+						hits = append(hits, trainerHit(trainer, party.field+".heldItem", slot, pokemon))
+					}
+				}
+			}
+		}
+		for _, trainer := range data.trainers {
+			for _, item := range aiItems(trainer.AI) {
+				if strings.EqualFold(item.Name, id) {
+					hits = append(hits, Hit{"trainers", trainer.ID, trainer.Name, "ai.items", fmt.Sprintf("used %d times in battle", item.Count)})
+				}
+			}
+		}
+		for _, trainerClass := range data.trainerClasses {
+			for _, item := range aiItems(trainerClass.AI) {
+				if strings.EqualFold(item.Name, id) {
+					hits = append(hits, Hit{"trainerclasses", trainerClass.Name, trainerClass.Name, "ai.items", fmt.Sprintf("used %d times in battle", item.Count)})
+				}
+			}
+		}
+	case "pokemon":
+		for _, trainer := range data.trainers {
+			for _, party := range trainerParties(trainer) {
+				for slot, pokemon := range party.pokemon {
+					if pokemon.ID == id {
+						hits = append(hits, trainerHit(trainer, party.field+".id", slot, pokemon))
+					}
+				}
+			}
+		}
+		for _, pokemon := range data.pokemon {
+			for _, evolution := range pokemon.Evolutions {
+				if evolution.PokemonID == id {
+					hits = append(hits, Hit{"pokemon", pokemon.ID, pokemon.Species, "evolutions", fmt.Sprintf("evolves into %s (%s)", evolution.Name, strings.Join(evolution.Methods, ", "))})
+				}
+			}
+		}
+		for _, mapData := range data.maps {
+			for _, encounter := range encounters(mapData) {
+				if encounter.id == id {
+					hits = append(hits, Hit{"maps", strconv.Itoa(mapData.ID), mapData.Name, encounter.table, fmt.Sprintf("%s Lv %d-%d, rarity %d", encounter.name, encounter.minLevel, encounter.maxLevel, encounter.rarity)})
+				}
+			}
+		}
+	case "songs":
+		for _, mapData := range data.maps {
+			for _, properties := range mapData.Properties {
+				if properties.BgMusic == id {
+					hits = append(hits, Hit{"maps", strconv.Itoa(mapData.ID), mapData.Name, "properties.bgMusic", "background music"})
+				}
+			}
+		}
+		for _, trainerClass := range data.trainerClasses {
+			if trainerClass.Music == id {
+				hits = append(hits, Hit{"trainerclasses", trainerClass.Name, trainerClass.Name, "music", "battle music"})
+			}
+			if trainerClass.EncounterMusic == id {
+				hits = append(hits, Hit{"trainerclasses", trainerClass.Name, trainerClass.Name, "encounterMusic", "encounter music"})
+			}
+		}
+	case "trainersprites":
+		for _, trainer := range data.trainers {
+			if trainer.Sprite == id {
+				hits = append(hits, Hit{"trainers", trainer.ID, trainer.Name, "sprite", trainer.Sprite})
+			}
+		}
+		for _, trainerClass := range data.trainerClasses {
+			if trainerClass.Sprite == id {
+				hits = append(hits, Hit{"trainerclasses", trainerClass.Name, trainerClass.Name, "sprite", trainerClass.Sprite})
+			}
+		}
+	case "abilities":
+		for _, pokemon := range data.pokemon {
+			for _, ability := range pokemon.Abilities {
+				if strings.EqualFold(ability.Name, id) {
+					snippet := "ability"
+					if ability.IsHidden {
+						snippet = "hidden ability"
+					}
+					hits = append(hits, Hit{"pokemon", pokemon.ID, pokemon.Species, "abilities", snippet})
+				}
+			}
+		}
+	case "types":
+		for _, pokemon := range data.pokemon {
+			for _, pokemonType := range pokemon.Types {
+				if strings.EqualFold(pokemonType, id) {
+					hits = append(hits, Hit{"pokemon", pokemon.ID, pokemon.Species, "types", strings.Join(pokemon.Types, "/")})
+				}
+			}
+		}
+		for _, move := range data.moves {
+			if strings.EqualFold(move.Type, id) {
+				hits = append(hits, Hit{"moves", strconv.Itoa(move.ID), move.Name, "type", move.Type})
+			}
+		}
+	case "trainerclasses":
+		for _, trainer := range data.trainers {
+			if trainer.ClassType == id {
+				hits = append(hits, Hit{"trainers", trainer.ID, trainer.Name, "classType", trainer.ClassType})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cannot find usages of %q records", entityType)
+	}
+	return hits, nil
+}
+
+// trainerParty is the base party of a trainer or one of its variants, field
+// is where it is stored in the trainer
+type trainerParty struct {
+	field   string
+	pokemon []coreModels.TrainerPokemon
+}
+
+func trainerParties(trainer coreModels.Trainer) []trainerParty {
+	parties := []trainerParty{{"pokemon", trainer.Pokemons}}
+	for _, variant := range trainer.Variants {
+		parties = append(parties, trainerParty{fmt.Sprintf("variants.%s.pokemon", variant.Name), variant.Pokemons})
+	}
+	return parties
+}
+
+// aiItems returns the battle items of an AI profile, none without one
+func aiItems(profile *coreModels.AIProfile) []coreModels.AIItem {
+	if profile == nil {
+		return nil
+	}
+	return profile.Items
+}
+
+func trainerHit(trainer coreModels.Trainer, field string, slot int, pokemon coreModels.TrainerPokemon) Hit {
+	return Hit{"trainers", trainer.ID, trainer.Name, field, fmt.Sprintf("slot %d: %s Lv %d", slot+1, pokemon.Species, pokemon.Level)}
+}
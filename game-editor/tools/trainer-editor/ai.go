@@ -0,0 +1,130 @@
+package trainereditor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+// GetAIFlags lists the AI flags a trainer or trainer class can use
+func (a *TrainerEditorApp) GetAIFlags() []core.AIFlag {
+	return core.AIFlags
+}
+
+// GetSwitchModes lists the switching logics a trainer AI can use
+func (a *TrainerEditorApp) GetSwitchModes() []string {
+	return core.SwitchModes
+}
+
+// UpdateTrainerAI replaces a trainer's own AI profile, an empty profile
+// leaves the trainer with its class's
+func (a *TrainerEditorApp) UpdateTrainerAI(trainerID string, profile coreModels.AIProfile) error {
+	if err := a.checkAIProfile(profile); err != nil {
+		return err
+	}
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(trainerID)
+	if err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Update AI of trainer %s", trainer.Name))()
+	return trainers.Modify(trainerID, func(trainer *coreModels.Trainer) error {
+		trainer.AI = aiProfile(profile)
+		return nil
+	})
+}
+
+// GetTrainerAI returns the AI a trainer battles with, its class's profile
+// with its own applied
+func (a *TrainerEditorApp) GetTrainerAI(trainerID string) (coreModels.TrainerAI, error) {
+	trainer, err := repository.NewTrainerRepository(a.app.DataDirectory).Find(trainerID)
+	if err != nil {
+		return coreModels.TrainerAI{}, err
+	}
+	classes, err := a.classProfiles()
+	if err != nil {
+		return coreModels.TrainerAI{}, err
+	}
+	return trainerAI(trainer, classes), nil
+}
+
+// ExportTrainerAI writes the resolved AI of every trainer to trainerai.toml
+// for the engine and returns how many trainers it holds
+func (a *TrainerEditorApp) ExportTrainerAI() (int, error) {
+	trainers, err := repository.NewTrainerRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return 0, err
+	}
+	classes, err := a.classProfiles()
+	if err != nil {
+		return 0, err
+	}
+	document := coreModels.TrainerAIToml{Trainers: []coreModels.TrainerAI{}}
+	for _, trainer := range trainers {
+		document.Trainers = append(document.Trainers, trainerAI(trainer, classes))
+	}
+	defer a.app.Journal.Begin("Export trainer AI")()
+	return len(document.Trainers), repository.NewTrainerAIRepository(a.app.DataDirectory).Save(document)
+}
+
+// classProfiles returns the AI profile of every trainer class by name
+func (a *TrainerEditorApp) classProfiles() (map[string]*coreModels.AIProfile, error) {
+	classes, err := repository.NewTrainerClassRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return nil, err
+	}
+	profiles := make(map[string]*coreModels.AIProfile, len(classes))
+	for _, class := range classes {
+		profiles[class.Name] = class.AI
+	}
+	return profiles, nil
+}
+
+func trainerAI(trainer coreModels.Trainer, classes map[string]*coreModels.AIProfile) coreModels.TrainerAI {
+	profile := core.ResolveAIProfile(classes[trainer.ClassType], trainer.AI)
+	return coreModels.TrainerAI{
+		ID:        trainer.ID,
+		Name:      trainer.Name,
+		Flags:     profile.Flags,
+		FlagMask:  core.AIFlagMask(profile.Flags),
+		Switching: profile.Switching,
+		Items:     profile.Items,
+	}
+}
+
+// aiProfile returns the profile to store, none when it sets nothing
+func aiProfile(profile coreModels.AIProfile) *coreModels.AIProfile {
+	if len(profile.Flags) == 0 && profile.Switching == "" && len(profile.Items) == 0 {
+		return nil
+	}
+	return &profile
+}
+
+// checkAIProfile refuses a profile with the problems core.CheckAIProfile
+// finds or with items that are not in helditems.toml
+func (a *TrainerEditorApp) checkAIProfile(profile coreModels.AIProfile) error {
+	problems := core.CheckAIProfile(profile)
+	if len(profile.Items) > 0 {
+		heldItems, err := repository.NewHeldItemRepository(a.app.DataDirectory).All()
+		if err != nil {
+			return err
+		}
+		known := make(map[string]bool)
+		for _, heldItem := range heldItems {
+			known[strings.ToLower(heldItem.Name)] = true
+		}
+		for _, item := range profile.Items {
+			if item.Name != "" && !known[strings.ToLower(item.Name)] {
+				problems = append(problems, fmt.Sprintf("item %s is not in helditems.toml", item.Name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid AI profile: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package trainereditor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+)
+
+// GetTrainerClasses lists the trainer classes, a project without
+// trainerclasses.toml has none
+func (a *TrainerEditorApp) GetTrainerClasses() ([]coreModels.TrainerClass, error) {
+	classes, err := repository.NewTrainerClassRepository(a.app.DataDirectory).All()
+	if errors.Is(err, repository.ErrMissingFile) {
+		return []coreModels.TrainerClass{}, nil
+	}
+	return classes, err
+}
+
+// CreateTrainerClass adds a trainer class, creating trainerclasses.toml when
+// the project has none. Names are unique.
+func (a *TrainerEditorApp) CreateTrainerClass(class coreModels.TrainerClass) error {
+	class.Name = strings.TrimSpace(class.Name)
+	if class.AI != nil {
+		class.AI = aiProfile(*class.AI)
+	}
+	if err := a.checkTrainerClass(class); err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Create trainer class %s", class.Name))()
+	return repository.NewTrainerClassRepository(a.app.DataDirectory).Insert(class)
+}
+
+// UpdateTrainerClass saves the class called name. When class has a new name
+// every trainer of the class is moved to it, the number of trainers moved is
+// returned. The class is put back when the trainers cannot be moved.
+func (a *TrainerEditorApp) UpdateTrainerClass(name string, class coreModels.TrainerClass) (int, error) {
+	class.Name = strings.TrimSpace(class.Name)
+	if class.AI != nil {
+		class.AI = aiProfile(*class.AI)
+	}
+	if err := a.checkTrainerClass(class); err != nil {
+		return 0, err
+	}
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	if class.Name != name {
+		// A malformed trainers.toml would leave the class renamed without its
+		// trainers, so it is refused before the class is written
+		if _, err := trainers.All(); err != nil && !errors.Is(err, repository.ErrMissingFile) {
+			return 0, err
+		}
+	}
+
+	defer a.app.Journal.Begin(fmt.Sprintf("Update trainer class %s", name))()
+	classes := repository.NewTrainerClassRepository(a.app.DataDirectory)
+	var previous coreModels.TrainerClass
+	err := classes.Update(func(records *[]coreModels.TrainerClass) error {
+		found := -1
+		for index, record := range *records {
+			if record.Name == class.Name && record.Name != name {
+				return &repository.Error{Kind: repository.ErrDuplicateID, File: classes.File(), ID: class.Name}
+			}
+			if record.Name == name {
+				found = index
+			}
+		}
+		if found < 0 {
+			return &repository.Error{Kind: repository.ErrNotFound, File: classes.File(), ID: name}
+		}
+		previous = (*records)[found]
+		(*records)[found] = class
+		return nil
+	})
+	if err != nil || class.Name == name {
+		return 0, err
+	}
+	moved := 0
+	err = trainers.Update(func(records *[]coreModels.Trainer) error {
+		for index := range *records {
+			if (*records)[index].ClassType == name {
+				(*records)[index].ClassType = class.Name
+				moved++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		restoreErr := classes.Modify(class.Name, func(record *coreModels.TrainerClass) error {
+			*record = previous
+			return nil
+		})
+		if restoreErr != nil {
+			return 0, fmt.Errorf("%w, and %s could not be renamed back to %s: %v", err, class.Name, name, restoreErr)
+		}
+		return 0, err
+	}
+	return moved, nil
+}
+
+// DeleteTrainerClass removes a trainer class, it is refused while trainers
+// still use it
+func (a *TrainerEditorApp) DeleteTrainerClass(name string) error {
+	trainers, err := repository.NewTrainerRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return err
+	}
+	var users []string
+	for _, trainer := range trainers {
+		if trainer.ClassType == name {
+			users = append(users, trainer.Name)
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("trainer class %s is still used by %s", name, strings.Join(users, ", "))
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete trainer class %s", name))()
+	return repository.NewTrainerClassRepository(a.app.DataDirectory).Delete(name)
+}
+
+func (a *TrainerEditorApp) checkTrainerClass(class coreModels.TrainerClass) error {
+	if class.Name == "" {
+		return fmt.Errorf("a trainer class needs a name")
+	}
+	if class.PrizeMultiplier < 0 {
+		return fmt.Errorf("the prize money multiplier of %s cannot be negative", class.Name)
+	}
+	if class.AI != nil {
+		return a.checkAIProfile(*class.AI)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package trainereditor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// partySize is the most pokemon a trainer, and a Showdown team, can hold
+const partySize = 6
+
+// showdownStats are the stat names of Showdown's EVs and IVs lines, in the
+// order Showdown writes them, with the stat each one sets
+var showdownStats = []struct {
+	name  string
+	field func(stats *coreModels.StatSpread) *int
+}{
+	{"HP", func(stats *coreModels.StatSpread) *int { return &stats.HP }},
+	{"Atk", func(stats *coreModels.StatSpread) *int { return &stats.Attack }},
+	{"Def", func(stats *coreModels.StatSpread) *int { return &stats.Defense }},
+	{"SpA", func(stats *coreModels.StatSpread) *int { return &stats.SpecialAttack }},
+	{"SpD", func(stats *coreModels.StatSpread) *int { return &stats.SpecialDefense }},
+	{"Spe", func(stats *coreModels.StatSpread) *int { return &stats.Speed }},
+}
+
+// UnknownShowdownName is a species, move, item or nature of a paste that is
+// not in the project
+type UnknownShowdownName struct {
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// InvalidShowdownValue is a level, EVs or IVs line of a paste with a value
+// out of range, Field is level, evs or ivs
+type InvalidShowdownValue struct {
+	Line   int    `json:"line"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ShowdownImport is the party read from a Showdown paste, it is only written
+// to the trainer when every name resolved and every value is in range
+type ShowdownImport struct {
+	Pokemons []coreModels.PokemonJson `json:"pokemons"`
+	Unknown  []UnknownShowdownName    `json:"unknown"`
+	Invalid  []InvalidShowdownValue   `json:"invalid"`
+	Applied  bool                     `json:"applied"`
+}
+
+// HasErrors reports whether the paste had names the project does not know
+// or values out of range
+func (r ShowdownImport) HasErrors() bool {
+	return len(r.Unknown) > 0 || len(r.Invalid) > 0
+}
+
+// showdownSet is one pokemon of a paste before its names are resolved
+type showdownSet struct {
+	line      int
+	species   string
+	item      string
+	level     int
+	nature    string
+	ivs       coreModels.StatSpread
+	evs       coreModels.StatSpread
+	moves     []string
+	moveLines []int
+	// lines of the Level, IVs and EVs lines, 0 when the paste has none
+	levelLine int
+	ivsLine   int
+	evsLine   int
+}
+
+// ExportTrainerShowdown writes a trainer's party in Showdown's paste format
+func (a *TrainerEditorApp) ExportTrainerShowdown(trainerID string) (string, error) {
+	trainer, err := repository.NewTrainerRepository(a.app.DataDirectory).Find(trainerID)
+	if err != nil {
+		return "", err
+	}
+	pokemon, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return "", err
+	}
+	abilities := map[string]string{}
+	for _, species := range pokemon {
+		for _, ability := range species.Abilities {
+			if !ability.IsHidden {
+				abilities[species.ID] = ability.Name
+				break
+			}
+		}
+	}
+
+	var paste strings.Builder
+	for index, member := range trainer.Pokemons {
+		if index > 0 {
+			paste.WriteString("\n")
+		}
+		paste.WriteString(member.Species)
+		if member.HeldItem != "" {
+			fmt.Fprintf(&paste, " @ %s", member.HeldItem)
+		}
+		paste.WriteString("\n")
+		if ability, ok := abilities[member.ID]; ok {
+			fmt.Fprintf(&paste, "Ability: %s\n", ability)
+		}
+		fmt.Fprintf(&paste, "Level: %d\n", member.Level)
+		if member.EVs != nil {
+			if spread := showdownSpreadLine(*member.EVs, 0); spread != "" {
+				fmt.Fprintf(&paste, "EVs: %s\n", spread)
+			}
+		}
+		if member.Nature != "" {
+			fmt.Fprintf(&paste, "%s Nature\n", strings.ToUpper(member.Nature[:1])+strings.ToLower(member.Nature[1:]))
+		}
+		if member.IVs != nil {
+			if spread := showdownSpreadLine(*member.IVs, core.MaxIV); spread != "" {
+				fmt.Fprintf(&paste, "IVs: %s\n", spread)
+			}
+		}
+		for _, move := range member.Moves {
+			if move != "" {
+				fmt.Fprintf(&paste, "- %s\n", move)
+			}
+		}
+	}
+	return paste.String(), nil
+}
+
+// ImportTrainerShowdown replaces a trainer's party with the team of a
+// Showdown paste. Species, moves and items are matched against the project
+// ignoring case, spaces and punctuation, the paste's IVs, EVs and nature are
+// stored and the stats derived from them.
+func (a *TrainerEditorApp) ImportTrainerShowdown(trainerID string, paste string) (ShowdownImport, error) {
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(trainerID)
+	if err != nil {
+		return ShowdownImport{}, err
+	}
+	sets := parseShowdown(paste)
+	if len(sets) == 0 {
+		return ShowdownImport{}, fmt.Errorf("the paste has no pokemon")
+	}
+	if len(sets) > partySize {
+		return ShowdownImport{}, fmt.Errorf("the paste has %d pokemon, a party holds at most %d", len(sets), partySize)
+	}
+	result, err := a.resolveShowdown(sets)
+	if err != nil || result.HasErrors() {
+		return result, err
+	}
+
+	defer a.app.Journal.Begin(fmt.Sprintf("Import Showdown team into trainer %s", trainer.Name))()
+	pokemons, err := a.trainerPokemons(result.Pokemons)
+	if err != nil {
+		return result, err
+	}
+	err = trainers.Modify(trainerID, func(trainer *coreModels.Trainer) error {
+		trainer.Pokemons = pokemons
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+func (a *TrainerEditorApp) resolveShowdown(sets []showdownSet) (ShowdownImport, error) {
+	pokemon, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return ShowdownImport{}, err
+	}
+	moves, err := repository.NewMoveRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return ShowdownImport{}, err
+	}
+	heldItems, err := repository.NewHeldItemRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return ShowdownImport{}, err
+	}
+	species := map[string]Models.Pokemon{}
+	for _, entry := range pokemon {
+		species[showdownKey(entry.Species)] = entry
+	}
+	moveNames := map[string]string{}
+	for _, move := range moves {
+		moveNames[showdownKey(move.Name)] = move.Name
+	}
+	itemNames := map[string]string{}
+	for _, item := range heldItems {
+		itemNames[showdownKey(item.Name)] = item.Name
+	}
+
+	result := ShowdownImport{Pokemons: []coreModels.PokemonJson{}}
+	unknown := func(line int, kind string, name string) {
+		result.Unknown = append(result.Unknown, UnknownShowdownName{Line: line, Kind: kind, Name: name})
+	}
+	invalid := func(line int, field string, err error) {
+		result.Invalid = append(result.Invalid, InvalidShowdownValue{Line: line, Field: field, Reason: err.Error()})
+	}
+	for _, set := range sets {
+		entry, known := species[showdownKey(set.species)]
+		if !known {
+			unknown(set.line, "species", set.species)
+		}
+		var item string
+		if set.item != "" {
+			var ok bool
+			if item, ok = itemNames[showdownKey(set.item)]; !ok {
+				unknown(set.line, "item", set.item)
+			}
+		}
+		setMoves := []string{}
+		for index, move := range set.moves {
+			name, ok := moveNames[showdownKey(move)]
+			if !ok {
+				unknown(set.moveLines[index], "move", move)
+				continue
+			}
+			setMoves = append(setMoves, name)
+		}
+		if set.nature != "" {
+			if _, ok := core.Natures[strings.ToLower(set.nature)]; !ok {
+				unknown(set.line, "nature", set.nature)
+			}
+		}
+		if err := core.CheckLevel(set.level); err != nil {
+			invalid(set.levelLine, "level", err)
+		}
+		if err := core.CheckIVs(set.ivs); err != nil {
+			invalid(set.ivsLine, "ivs", err)
+		}
+		if err := core.CheckEVs(set.evs); err != nil {
+			invalid(set.evsLine, "evs", err)
+		}
+		if !known {
+			continue
+		}
+
+		ivs, evs := set.ivs, set.evs
+		stats := core.CalculateStats(entry.Stats, set.level, ivs, evs, set.nature)
+		result.Pokemons = append(result.Pokemons, coreModels.PokemonJson{
+			Species:        entry.Species,
+			Level:          set.level,
+			HP:             stats.HP,
+			Attack:         stats.Attack,
+			Defense:        stats.Defense,
+			Speed:          stats.Speed,
+			SpecialAttack:  stats.SpecialAttack,
+			SpecialDefense: stats.SpecialDefense,
+			Moves:          setMoves,
+			HeldItem:       item,
+			ID:             entry.ID,
+			Front:          core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/front/%s_front.png", entry.ID)),
+			Icon:           core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/icons/%s/%s.gif", entry.ID, entry.ID)),
+			Cry:            core.AssetURL(a.app.DataDirectory, fmt.Sprintf("pokemon/cries/%s.wav", entry.ID)),
+			IVs:            &ivs,
+			EVs:            &evs,
+			Nature:         strings.ToLower(set.nature),
+		})
+	}
+	return result, nil
+}
+
+// parseShowdown splits a paste into its sets. Lines it does not use, like
+// Ability, Shiny or Tera Type, are skipped.
+func parseShowdown(paste string) []showdownSet {
+	var sets []showdownSet
+	var current *showdownSet
+	lines := strings.Split(strings.ReplaceAll(paste, "\r\n", "\n"), "\n")
+	for index, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			current = nil
+			continue
+		}
+		// Team headers of a Showdown export, === [gen3] Team ===
+		if strings.HasPrefix(line, "===") {
+			continue
+		}
+		if current == nil {
+			sets = append(sets, showdownSet{line: index + 1, level: 100, ivs: core.PerfectIVs})
+			current = &sets[len(sets)-1]
+			current.species, current.item = showdownHeader(line)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "-"):
+			move := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+			// Hidden Power [Fire] keeps only the move name
+			if bracket := strings.Index(move, "["); bracket > 0 {
+				move = strings.TrimSpace(move[:bracket])
+			}
+			current.moves = append(current.moves, move)
+			current.moveLines = append(current.moveLines, index+1)
+		case strings.HasPrefix(line, "Level:"):
+			if level, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Level:"))); err == nil {
+				current.level = level
+				current.levelLine = index + 1
+			}
+		case strings.HasPrefix(line, "EVs:"):
+			showdownSpread(strings.TrimPrefix(line, "EVs:"), &current.evs)
+			current.evsLine = index + 1
+		case strings.HasPrefix(line, "IVs:"):
+			showdownSpread(strings.TrimPrefix(line, "IVs:"), &current.ivs)
+			current.ivsLine = index + 1
+		case strings.HasSuffix(line, " Nature"):
+			current.nature = strings.TrimSpace(strings.TrimSuffix(line, " Nature"))
+		}
+	}
+	return sets
+}
+
+// showdownHeader reads the species and item of a set's first line, written
+// as Nickname (Species) (M) @ Item with everything but the species optional
+func showdownHeader(line string) (string, string) {
+	var item string
+	if at := strings.LastIndex(line, " @ "); at >= 0 {
+		item = strings.TrimSpace(line[at+3:])
+		line = strings.TrimSpace(line[:at])
+	}
+	line = strings.TrimSuffix(strings.TrimSuffix(line, " (M)"), " (F)")
+	if strings.HasSuffix(line, ")") {
+		if open := strings.LastIndex(line, "("); open >= 0 {
+			return strings.TrimSpace(line[open+1 : len(line)-1]), item
+		}
+	}
+	return strings.TrimSpace(line), item
+}
+
+// showdownSpread fills stats from a spread like 252 Atk / 4 SpD / 252 Spe
+func showdownSpread(spread string, stats *coreModels.StatSpread) {
+	for _, part := range strings.Split(spread, "/") {
+		value, name, ok := strings.Cut(strings.TrimSpace(part), " ")
+		if !ok {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		for _, stat := range showdownStats {
+			if strings.EqualFold(stat.name, strings.TrimSpace(name)) {
+				*stat.field(stats) = number
+			}
+		}
+	}
+}
+
+// showdownSpreadLine writes the stats of a spread that differ from the
+// default Showdown assumes, like 252 Atk / 4 SpD / 252 Spe
+func showdownSpreadLine(stats coreModels.StatSpread, standard int) string {
+	var parts []string
+	for _, stat := range showdownStats {
+		if value := *stat.field(&stats); value != standard {
+			parts = append(parts, fmt.Sprintf("%d %s", value, stat.name))
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// showdownKey is the form names are compared in, so Thunder Shock matches
+// ThunderShock and Mr. Mime matches Mr Mime
+func showdownKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
@@ -0,0 +1,191 @@
+package trainereditor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+func TestShowdownHeader(t *testing.T) {
+	tests := []struct {
+		line    string
+		species string
+		item    string
+	}{
+		{"Pikachu", "Pikachu", ""},
+		{"Pikachu @ Light Ball", "Pikachu", "Light Ball"},
+		{"Sparky (Pikachu) @ Light Ball", "Pikachu", "Light Ball"},
+		{"Sparky (Pikachu) (M) @ Light Ball", "Pikachu", "Light Ball"},
+		{"Pikachu (F)", "Pikachu", ""},
+		{"Mr. Mime (M)", "Mr. Mime", ""},
+		{"Fred (Mr. Mime) @ Leftovers", "Mr. Mime", "Leftovers"},
+		{"  Snorlax  @  Chesto Berry ", "Snorlax", "Chesto Berry"},
+	}
+	for _, test := range tests {
+		species, item := showdownHeader(test.line)
+		if species != test.species || item != test.item {
+			t.Errorf("showdownHeader(%q) = %q, %q, want %q, %q", test.line, species, item, test.species, test.item)
+		}
+	}
+}
+
+func TestParseShowdown(t *testing.T) {
+	const paste = "=== [gen3] Team ===\r\n" +
+		"\r\n" +
+		"Sparky (Pikachu) (M) @ Light Ball\r\n" +
+		"Ability: Static\r\n" +
+		"Level: 50\r\n" +
+		"EVs: 252 Atk / 4 SpD / 252 Spe\r\n" +
+		"Jolly Nature\r\n" +
+		"IVs: 0 SpA\r\n" +
+		"- Thunderbolt\r\n" +
+		"- Hidden Power [Ice]\r\n" +
+		"\r\n" +
+		"Snorlax\r\n" +
+		"Level: abc\r\n" +
+		"- Body Slam\r\n"
+	sets := parseShowdown(paste)
+	if len(sets) != 2 {
+		t.Fatalf("got %d sets, want 2", len(sets))
+	}
+
+	ivs := core.PerfectIVs
+	ivs.SpecialAttack = 0
+	want := showdownSet{
+		line:      3,
+		species:   "Pikachu",
+		item:      "Light Ball",
+		level:     50,
+		nature:    "Jolly",
+		ivs:       ivs,
+		evs:       coreModels.StatSpread{Attack: 252, SpecialDefense: 4, Speed: 252},
+		moves:     []string{"Thunderbolt", "Hidden Power"},
+		moveLines: []int{9, 10},
+		levelLine: 5,
+		evsLine:   6,
+		ivsLine:   8,
+	}
+	if !reflect.DeepEqual(sets[0], want) {
+		t.Errorf("first set is %+v, want %+v", sets[0], want)
+	}
+
+	// A level that is not a number keeps the default and has no line
+	want = showdownSet{
+		line:      12,
+		species:   "Snorlax",
+		level:     100,
+		ivs:       core.PerfectIVs,
+		moves:     []string{"Body Slam"},
+		moveLines: []int{14},
+	}
+	if !reflect.DeepEqual(sets[1], want) {
+		t.Errorf("second set is %+v, want %+v", sets[1], want)
+	}
+}
+
+func TestShowdownSpread(t *testing.T) {
+	tests := []struct {
+		spread string
+		want   coreModels.StatSpread
+	}{
+		{"252 Atk / 4 SpD / 252 Spe", coreModels.StatSpread{Attack: 252, SpecialDefense: 4, Speed: 252}},
+		{"4 hp/252 spa", coreModels.StatSpread{HP: 4, SpecialAttack: 252}},
+		{"999 Def", coreModels.StatSpread{Defense: 999}},
+		{"lots Atk / 12 / 8 Luck / 6 Spe", coreModels.StatSpread{Speed: 6}},
+	}
+	for _, test := range tests {
+		var got coreModels.StatSpread
+		showdownSpread(test.spread, &got)
+		if got != test.want {
+			t.Errorf("showdownSpread(%q) = %+v, want %+v", test.spread, got, test.want)
+		}
+	}
+}
+
+func TestShowdownSpreadLine(t *testing.T) {
+	evs := coreModels.StatSpread{Attack: 252, SpecialDefense: 4, Speed: 252}
+	if got := showdownSpreadLine(evs, 0); got != "252 Atk / 4 SpD / 252 Spe" {
+		t.Errorf("EVs line is %q", got)
+	}
+	if got := showdownSpreadLine(core.PerfectIVs, core.MaxIV); got != "" {
+		t.Errorf("perfect IVs line is %q, want none", got)
+	}
+}
+
+func TestShowdownKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"Thunder Shock": "thundershock",
+		"ThunderShock":  "thundershock",
+		"Mr. Mime":      "mrmime",
+		"Porygon-Z":     "porygonz",
+		"Farfetch’d":    "farfetchd",
+	} {
+		if got := showdownKey(name); got != want {
+			t.Errorf("showdownKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestResolveShowdown(t *testing.T) {
+	project := t.TempDir()
+	files := map[string]string{
+		"pokemon.toml":   "[[pokemon]]\nid = '25'\nspecies = 'Pikachu'\n\n[pokemon.stats]\nhp = 35\nattack = 55\ndefense = 40\nspecial-attack = 50\nspecial-defense = 50\nspeed = 90\n",
+		"moves.toml":     "[[move]]\nid = 85\nname = 'Thunderbolt'\n",
+		"helditems.toml": "[[heldItems]]\nname = 'Light Ball'\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(project, "data", "toml", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := NewTrainerEditorApp(core.NewProjectApp(project))
+
+	result, err := a.resolveShowdown(parseShowdown("Pikachu @ light ball\nLevel: 50\nEVs: 252 Spe\nTimid Nature\n- ThunderBolt\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.HasErrors() || len(result.Pokemons) != 1 {
+		t.Fatalf("got %+v, want one pokemon without errors", result)
+	}
+	pikachu := result.Pokemons[0]
+	if pikachu.HeldItem != "Light Ball" || !reflect.DeepEqual(pikachu.Moves, []string{"Thunderbolt"}) || pikachu.Nature != "timid" {
+		t.Errorf("names resolved to %q, %v, %q", pikachu.HeldItem, pikachu.Moves, pikachu.Nature)
+	}
+	if pikachu.Level != 50 || pikachu.HP != 110 || pikachu.Speed != 156 || pikachu.Attack != 67 {
+		t.Errorf("got level %d with %d HP, %d attack and %d speed, want 50, 110, 67 and 156", pikachu.Level, pikachu.HP, pikachu.Attack, pikachu.Speed)
+	}
+
+	paste := "Raichu @ Leftovers\nLevel: 0\nEVs: 999 Atk\nIVs: 40 Spe\nGrumpy Nature\n- Surf\n"
+	result, err = a.resolveShowdown(parseShowdown(paste))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantUnknown := []UnknownShowdownName{
+		{Line: 1, Kind: "species", Name: "Raichu"},
+		{Line: 1, Kind: "item", Name: "Leftovers"},
+		{Line: 6, Kind: "move", Name: "Surf"},
+		{Line: 1, Kind: "nature", Name: "Grumpy"},
+	}
+	if !reflect.DeepEqual(result.Unknown, wantUnknown) {
+		t.Errorf("unknown names are %+v, want %+v", result.Unknown, wantUnknown)
+	}
+	wantInvalid := []InvalidShowdownValue{
+		{Line: 2, Field: "level", Reason: "level 0 is not between 1 and 100"},
+		{Line: 4, Field: "ivs", Reason: "speed IV 40 is not between 0 and 31"},
+		{Line: 3, Field: "evs", Reason: "attack EV 999 is not between 0 and 255"},
+	}
+	if !reflect.DeepEqual(result.Invalid, wantInvalid) {
+		t.Errorf("invalid values are %+v, want %+v", result.Invalid, wantInvalid)
+	}
+	if len(result.Pokemons) != 0 {
+		t.Errorf("an unknown species was imported as %+v", result.Pokemons)
+	}
+}
@@ -0,0 +1,121 @@
+package trainereditor
+
+import (
+	"fmt"
+	"sort"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// StatsRecompute is the result of recomputing the stats of every trainer's
+// party
+type StatsRecompute struct {
+	Updated   int      `json:"updated"`
+	Unchanged int      `json:"unchanged"`
+	HandSet   int      `json:"handSet"`
+	Missing   []string `json:"missing"`
+}
+
+// baseStats returns the base stats of every species by ID
+func (a *TrainerEditorApp) baseStats() (map[string]Models.Stats, error) {
+	pokemon, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]Models.Stats, len(pokemon))
+	for _, species := range pokemon {
+		stats[species.ID] = species.Stats
+	}
+	return stats, nil
+}
+
+// GetNatures lists the natures a trainer pokemon can have
+func (a *TrainerEditorApp) GetNatures() []string {
+	var natures []string
+	for nature := range core.Natures {
+		natures = append(natures, nature)
+	}
+	sort.Strings(natures)
+	return natures
+}
+
+// CalculateTrainerPokemonStats fills in the stats of a party pokemon from its
+// species, level, IVs, EVs and nature without saving it. Pokemon without IVs
+// get perfect ones.
+func (a *TrainerEditorApp) CalculateTrainerPokemonStats(pokemon coreModels.PokemonJson) (coreModels.PokemonJson, error) {
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).Find(pokemon.ID)
+	if err != nil {
+		return pokemon, err
+	}
+	if pokemon.IVs == nil {
+		ivs := core.PerfectIVs
+		pokemon.IVs = &ivs
+	}
+	var evs coreModels.StatSpread
+	if pokemon.EVs != nil {
+		evs = *pokemon.EVs
+	}
+	stats := core.CalculateStats(species.Stats, pokemon.Level, *pokemon.IVs, evs, pokemon.Nature)
+	pokemon.HP = stats.HP
+	pokemon.Attack = stats.Attack
+	pokemon.Defense = stats.Defense
+	pokemon.Speed = stats.Speed
+	pokemon.SpecialAttack = stats.SpecialAttack
+	pokemon.SpecialDefense = stats.SpecialDefense
+	return pokemon, nil
+}
+
+// RecomputeTrainerStats derives the stats of every party and variant pokemon
+// that stores its IVs again, e.g. after a species' base stats changed. Pokemon with hand
+// typed stats are counted but left alone.
+func (a *TrainerEditorApp) RecomputeTrainerStats() (StatsRecompute, error) {
+	baseStats, err := a.baseStats()
+	if err != nil {
+		return StatsRecompute{}, err
+	}
+	recompute := func(trainers []coreModels.Trainer) StatsRecompute {
+		var result StatsRecompute
+		party := func(label string, pokemons []coreModels.TrainerPokemon) {
+			for slot := range pokemons {
+				pokemon := &pokemons[slot]
+				base, ok := baseStats[pokemon.ID]
+				switch {
+				case pokemon.IVs == nil:
+					result.HandSet++
+				case !ok:
+					result.Missing = append(result.Missing, fmt.Sprintf("%s slot %d: %s", label, slot+1, pokemon.Species))
+				case core.ApplyStats(pokemon, base):
+					result.Updated++
+				default:
+					result.Unchanged++
+				}
+			}
+		}
+		for _, trainer := range trainers {
+			party(trainer.Name, trainer.Pokemons)
+			for _, variant := range trainer.Variants {
+				party(fmt.Sprintf("%s (%s)", trainer.Name, variant.Name), variant.Pokemons)
+			}
+		}
+		return result
+	}
+
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	records, err := trainers.All()
+	if err != nil {
+		return StatsRecompute{}, err
+	}
+	if result := recompute(records); result.Updated == 0 {
+		return result, nil
+	}
+	defer a.app.Journal.Begin("Recompute trainer stats")()
+	var result StatsRecompute
+	err = trainers.Update(func(records *[]coreModels.Trainer) error {
+		result = recompute(*records)
+		return nil
+	})
+	return result, err
+}
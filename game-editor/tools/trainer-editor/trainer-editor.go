@@ -2,15 +2,14 @@ package trainereditor
 
 import (
 	"fmt"
-	"io"
-	"log"
 	"os"
 	"strings"
 
-	"github.com/pelletier/go-toml/v2"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/google/uuid"
 	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
 	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	"github.com/zenith110/pokemon-engine-tools/tools-core/dialogs"
 	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
 )
 
@@ -24,134 +23,168 @@ func NewTrainerEditorApp(app *core.App) *TrainerEditorApp {
 		app: app,
 	}
 }
-func (a *TrainerEditorApp) CreateTrainerData(trainerJson coreModels.TrainerJson) {
-	var pokemons []Models.Pokemons
-	for index := range trainerJson.Pokemons {
 
-		pokemon := Models.Pokemons{
-			Species:        trainerJson.Pokemons[index].Species,
-			Level:          trainerJson.Pokemons[index].Level,
-			Moves:          trainerJson.Pokemons[index].Moves,
-			HeldItem:       trainerJson.Pokemons[index].HeldItem,
-			HP:             trainerJson.Pokemons[index].HP,
-			Defense:        trainerJson.Pokemons[index].Defense,
-			Attack:         trainerJson.Pokemons[index].Attack,
-			SpecialAttack:  trainerJson.Pokemons[index].SpecialAttack,
-			SpecialDefense: trainerJson.Pokemons[index].SpecialDefense,
-			Speed:          trainerJson.Pokemons[index].Speed,
-			ID:             trainerJson.Pokemons[index].ID,
+// CreateTrainerData adds a trainer to trainers.toml and returns its ID. A
+// trainer without an ID gets a new UUID, a taken ID is refused.
+func (a *TrainerEditorApp) CreateTrainerData(trainerJson coreModels.TrainerJson) (string, error) {
+	pokemons, err := a.trainerPokemons(trainerJson.Pokemons)
+	if err != nil {
+		return "", err
+	}
+	variants, err := a.partyVariants(trainerJson.Variants)
+	if err != nil {
+		return "", err
+	}
+	var ai *coreModels.AIProfile
+	if trainerJson.AI != nil {
+		if err := a.checkAIProfile(*trainerJson.AI); err != nil {
+			return "", err
 		}
-
-		pokemons = append(pokemons, pokemon)
+		ai = aiProfile(*trainerJson.AI)
 	}
-
-	var trainers []Models.Trainers
-
-	trainer := Models.Trainers{
+	id := strings.TrimSpace(trainerJson.Id)
+	if id == "" {
+		id = uuid.NewString()
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Create trainer %s", trainerJson.Name))()
+	trainer := coreModels.Trainer{
 		Name:      trainerJson.Name,
 		Sprite:    trainerJson.Sprite,
-		ID:        trainerJson.Id,
+		ID:        id,
 		Pokemons:  pokemons,
 		ClassType: trainerJson.ClassType,
+		Variants:  variants,
+		AI:        ai,
 	}
-
-	trainers = append(trainers, trainer)
-	trainerConfig := Models.TrainerToml{
-		Trainers: trainers,
-	}
-	data, err := toml.Marshal(trainerConfig)
-	if err != nil {
-		panic(fmt.Errorf("error had occured while creating trainer data!\n%v", err))
-	}
-
-	// Write the encoded data to a file
-	f, err := os.OpenFile(fmt.Sprintf("%s/data/toml/trainers.toml", a.app.DataDirectory), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
-	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		panic(err)
+	if err := repository.NewTrainerRepository(a.app.DataDirectory).Insert(trainer); err != nil {
+		return "", err
 	}
+	return id, nil
 }
-func CheckFileExist(filepath string) bool {
-	_, error := os.Stat(filepath)
-	return error == nil
-}
-
-func (a *TrainerEditorApp) UpdateTrainer(trainerJson coreModels.TrainerJson) {
 
-	file, err := os.Open(fmt.Sprintf("%s/data/toml/trainers.toml", a.app.DataDirectory))
+// DeleteTrainer removes a trainer from trainers.toml
+func (a *TrainerEditorApp) DeleteTrainer(id string) error {
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(id)
 	if err != nil {
-		log.Fatalf("Error has occured while opening file to edit %v", err)
+		return err
 	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete trainer %s", trainer.Name))()
+	return trainers.Delete(id)
+}
 
-	defer file.Close()
-	var trainers Models.TrainerToml
-	bytes, err := io.ReadAll(file)
+// DuplicateTrainer copies a trainer and its party under a new UUID, right
+// after the original, and returns the copy's ID
+func (a *TrainerEditorApp) DuplicateTrainer(id string) (string, error) {
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(id)
 	if err != nil {
-		fmt.Printf("Error has occured reading data %v", err)
+		return "", err
 	}
-	err = toml.Unmarshal(bytes, &trainers)
+	duplicate := uuid.NewString()
+	defer a.app.Journal.Begin(fmt.Sprintf("Duplicate trainer %s", trainer.Name))()
+	err = trainers.Update(func(records *[]coreModels.Trainer) error {
+		for index := range *records {
+			if (*records)[index].ID != id {
+				continue
+			}
+			copied := (*records)[index]
+			copied.ID = duplicate
+			copied.Name = fmt.Sprintf("%s (copy)", copied.Name)
+			*records = append((*records)[:index+1], append([]coreModels.Trainer{copied}, (*records)[index+1:]...)...)
+			return nil
+		}
+		return &repository.Error{Kind: repository.ErrNotFound, File: trainers.File(), ID: id}
+	})
 	if err != nil {
-		fmt.Printf("Error has occured reading unmarshling into struct %v", err)
+		return "", err
 	}
-	for trainer := range trainers.Trainers {
-		if trainers.Trainers[trainer].ID == trainerJson.Id {
-			var pokemons []Models.Pokemons
-			for pokemonIndex := range trainerJson.Pokemons {
-				pokemon := Models.Pokemons{
-					ID:             trainerJson.Pokemons[pokemonIndex].ID,
-					HP:             trainerJson.Pokemons[pokemonIndex].HP,
-					Species:        trainerJson.Pokemons[pokemonIndex].Species,
-					Speed:          trainerJson.Pokemons[pokemonIndex].Speed,
-					Attack:         trainerJson.Pokemons[pokemonIndex].Attack,
-					Defense:        trainerJson.Pokemons[pokemonIndex].Defense,
-					SpecialAttack:  trainerJson.Pokemons[pokemonIndex].SpecialAttack,
-					SpecialDefense: trainerJson.Pokemons[pokemonIndex].SpecialDefense,
-					Moves:          trainerJson.Pokemons[pokemonIndex].Moves,
-					Level:          trainerJson.Pokemons[pokemonIndex].Level,
-					HeldItem:       trainerJson.Pokemons[pokemonIndex].HeldItem,
+	return duplicate, nil
+}
+
+// ReorderTrainers rewrites trainers.toml in the order of ids, which has to
+// list every trainer once
+func (a *TrainerEditorApp) ReorderTrainers(ids []string) error {
+	defer a.app.Journal.Begin("Reorder trainers")()
+	return repository.NewTrainerRepository(a.app.DataDirectory).Reorder(ids)
+}
+
+// trainerPokemons converts the editor's party into trainers.toml entries, the
+// stats of pokemon that set their IVs are derived from their species. Levels,
+// IVs, EVs and natures out of range are refused.
+func (a *TrainerEditorApp) trainerPokemons(party []coreModels.PokemonJson) ([]coreModels.TrainerPokemon, error) {
+	var baseStats map[string]Models.Stats
+	var pokemons []coreModels.TrainerPokemon
+	for index := range party {
+		if err := core.CheckSpread(party[index].Level, party[index].IVs, party[index].EVs, party[index].Nature); err != nil {
+			return nil, &repository.Error{
+				Kind: repository.ErrOutOfRange,
+				File: repository.NewTrainerRepository(a.app.DataDirectory).File(),
+				Err:  fmt.Errorf("slot %d (%s): %w", index+1, party[index].Species, err),
+			}
+		}
+		pokemon := coreModels.TrainerPokemon{
+			Pokemons: Models.Pokemons{
+				Species:        party[index].Species,
+				Level:          party[index].Level,
+				Moves:          party[index].Moves,
+				HeldItem:       party[index].HeldItem,
+				HP:             party[index].HP,
+				Defense:        party[index].Defense,
+				Attack:         party[index].Attack,
+				SpecialAttack:  party[index].SpecialAttack,
+				SpecialDefense: party[index].SpecialDefense,
+				Speed:          party[index].Speed,
+				ID:             party[index].ID,
+			},
+			IVs:    party[index].IVs,
+			EVs:    party[index].EVs,
+			Nature: party[index].Nature,
+		}
+		if pokemon.IVs != nil {
+			if baseStats == nil {
+				var err error
+				if baseStats, err = a.baseStats(); err != nil {
+					return nil, err
 				}
-				pokemons = append(pokemons, pokemon)
 			}
-			trainers.Trainers[trainer].Name = trainerJson.Name
-			trainers.Trainers[trainer].Pokemons = pokemons
-			trainers.Trainers[trainer].ClassType = trainerJson.ClassType
-			trainers.Trainers[trainer].Sprite = trainerJson.Sprite
+			base, ok := baseStats[pokemon.ID]
+			if !ok {
+				return nil, fmt.Errorf("species %s (%s) does not exist", pokemon.Species, pokemon.ID)
+			}
+			core.ApplyStats(&pokemon, base)
 		}
+		pokemons = append(pokemons, pokemon)
 	}
+	return pokemons, nil
+}
 
-	data, err := toml.Marshal(trainers)
-	if err != nil {
-		panic(fmt.Errorf("error had occured while creating trainer data!\n%v", err))
-	}
-	os.Remove(fmt.Sprintf("%s/data/toml/trainers.toml", a.app.DataDirectory))
-	f, err := os.OpenFile(fmt.Sprintf("%s/data/toml/trainers.toml", a.app.DataDirectory), os.O_CREATE, 0644)
+func CheckFileExist(filepath string) bool {
+	_, error := os.Stat(filepath)
+	return error == nil
+}
+
+func (a *TrainerEditorApp) UpdateTrainer(trainerJson coreModels.TrainerJson) error {
+	pokemons, err := a.trainerPokemons(trainerJson.Pokemons)
 	if err != nil {
-		log.Fatalf("Error occured while opening file %v", err)
-	}
-	defer f.Close()
-	if _, err := f.Write(data); err != nil {
-		fmt.Printf("Error occured while writing data %v", err)
+		return err
 	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Update trainer %s", trainerJson.Name))()
+	return repository.NewTrainerRepository(a.app.DataDirectory).Modify(trainerJson.Id, func(trainer *coreModels.Trainer) error {
+		trainer.Name = trainerJson.Name
+		trainer.Pokemons = pokemons
+		trainer.ClassType = trainerJson.ClassType
+		trainer.Sprite = trainerJson.Sprite
+		return nil
+	})
 }
 
 func (a *TrainerEditorApp) UpdateTrainerSprite() string {
-	selection, err := runtime.OpenFileDialog(a.app.Ctx, runtime.OpenDialogOptions{
-		Title: "Select trainer image",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "Images (*.png)",
-				Pattern:     "*.png",
-			},
-		},
-	})
+	selection, err := dialogs.File(a.app.Ctx, "Select trainer image", "Images (*.png)", "*.png")
 	if err != nil {
 		panic(fmt.Errorf("error has occured while updating trainer sprite"))
 	}
-	selectionUpdated := strings.ReplaceAll(selection, "\\", "/")
-	selectionSplit := strings.Split(selectionUpdated, "/")
+	selectionSplit := strings.Split(selection, "/")
 	selectionFinal := selectionSplit[len(selectionSplit)-1]
 	return selectionFinal
 }
@@ -0,0 +1,321 @@
+package trainereditor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// VariantOptions describes how GeneratePartyVariant derives a variant from a
+// trainer's base party
+type VariantOptions struct {
+	Name         string `json:"name"`
+	LevelOffset  int    `json:"levelOffset"`
+	Evolve       bool   `json:"evolve"`
+	UpgradeMoves bool   `json:"upgradeMoves"`
+	Save         bool   `json:"save"`
+}
+
+// VariantGeneration is a generated party variant and what was changed from
+// the base party
+type VariantGeneration struct {
+	Variant coreModels.PartyVariantJson `json:"variant"`
+	Changes []string                    `json:"changes"`
+	Saved   bool                        `json:"saved"`
+}
+
+// partyVariants converts the editor's variants into trainers.toml entries
+func (a *TrainerEditorApp) partyVariants(variants []coreModels.PartyVariantJson) ([]coreModels.PartyVariant, error) {
+	var result []coreModels.PartyVariant
+	for _, variant := range variants {
+		converted, err := a.partyVariant(variant)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+func (a *TrainerEditorApp) partyVariant(variant coreModels.PartyVariantJson) (coreModels.PartyVariant, error) {
+	name := strings.TrimSpace(variant.Name)
+	if name == "" {
+		return coreModels.PartyVariant{}, fmt.Errorf("a party variant needs a name")
+	}
+	if len(variant.Pokemons) > partySize {
+		return coreModels.PartyVariant{}, fmt.Errorf("party variant %s has %d pokemon, a party holds at most %d", name, len(variant.Pokemons), partySize)
+	}
+	pokemons, err := a.trainerPokemons(variant.Pokemons)
+	if err != nil {
+		return coreModels.PartyVariant{}, err
+	}
+	return coreModels.PartyVariant{Name: name, Pokemons: pokemons}, nil
+}
+
+// SavePartyVariant adds a named party variant to a trainer, or replaces the
+// variant of the same name
+func (a *TrainerEditorApp) SavePartyVariant(trainerID string, variant coreModels.PartyVariantJson) error {
+	converted, err := a.partyVariant(variant)
+	if err != nil {
+		return err
+	}
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(trainerID)
+	if err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Save party variant %s of trainer %s", converted.Name, trainer.Name))()
+	return trainers.Modify(trainerID, func(trainer *coreModels.Trainer) error {
+		for index := range trainer.Variants {
+			if trainer.Variants[index].Name == converted.Name {
+				trainer.Variants[index] = converted
+				return nil
+			}
+		}
+		trainer.Variants = append(trainer.Variants, converted)
+		return nil
+	})
+}
+
+// DeletePartyVariant removes the named party variant of a trainer
+func (a *TrainerEditorApp) DeletePartyVariant(trainerID string, name string) error {
+	trainers := repository.NewTrainerRepository(a.app.DataDirectory)
+	trainer, err := trainers.Find(trainerID)
+	if err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete party variant %s of trainer %s", name, trainer.Name))()
+	return trainers.Modify(trainerID, func(trainer *coreModels.Trainer) error {
+		for index := range trainer.Variants {
+			if trainer.Variants[index].Name == name {
+				trainer.Variants = append(trainer.Variants[:index], trainer.Variants[index+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("trainer %s has no party variant %s", trainer.Name, name)
+	})
+}
+
+// GeneratePartyVariant derives a party variant from a trainer's base party.
+// Levels move by the level offset, pokemon past their evolution level evolve
+// and moves learned between the old and the new level replace the oldest
+// level-up moves. Hand typed stats are kept, scaled by the levels and
+// evolutions gained. The variant is only stored when options.Save is set.
+func (a *TrainerEditorApp) GeneratePartyVariant(trainerID string, options VariantOptions) (VariantGeneration, error) {
+	options.Name = strings.TrimSpace(options.Name)
+	if options.Name == "" {
+		return VariantGeneration{}, fmt.Errorf("a party variant needs a name")
+	}
+	trainer, err := repository.NewTrainerRepository(a.app.DataDirectory).Find(trainerID)
+	if err != nil {
+		return VariantGeneration{}, err
+	}
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil {
+		return VariantGeneration{}, err
+	}
+	speciesByID := make(map[string]Models.Pokemon, len(species))
+	for _, pokemon := range species {
+		speciesByID[pokemon.ID] = pokemon
+	}
+
+	result := VariantGeneration{
+		Variant: coreModels.PartyVariantJson{Name: options.Name, Pokemons: []coreModels.PokemonJson{}},
+		Changes: []string{},
+	}
+	for slot, base := range trainer.Pokemons {
+		pokemon := base
+		pokemon.Moves = append([]string(nil), base.Moves...)
+		label := fmt.Sprintf("slot %d", slot+1)
+
+		pokemon.Level = min(max(base.Level+options.LevelOffset, 1), core.MaxLevel)
+		if pokemon.Level != base.Level {
+			result.Changes = append(result.Changes, fmt.Sprintf("%s: %s Lv %d -> Lv %d", label, base.Species, base.Level, pokemon.Level))
+		}
+		// line is every species the pokemon has been, their learnsets all
+		// count for the levels it gained
+		line := []Models.Pokemon{speciesByID[pokemon.ID]}
+		if options.Evolve {
+			// a species that evolves back into one it came from would loop
+			// forever, the chain stops at the first repeat
+			visited := map[string]bool{pokemon.ID: true}
+			for {
+				evolution, ok := levelEvolution(speciesByID[pokemon.ID], pokemon.Level)
+				if !ok || visited[evolution.PokemonID] {
+					break
+				}
+				target, ok := speciesByID[evolution.PokemonID]
+				if !ok {
+					break
+				}
+				visited[target.ID] = true
+				result.Changes = append(result.Changes, fmt.Sprintf("%s: %s evolves into %s", label, pokemon.Species, target.Species))
+				pokemon.ID = target.ID
+				pokemon.Species = target.Species
+				line = append(line, target)
+			}
+		}
+		if options.UpgradeMoves {
+			for _, move := range learnedBetween(line, base.Level, pokemon.Level, pokemon.Moves) {
+				replaced, ok := upgradeMoves(&pokemon, move, line)
+				if !ok {
+					continue
+				}
+				if replaced == "" {
+					result.Changes = append(result.Changes, fmt.Sprintf("%s: %s learns %s", label, pokemon.Species, move))
+				} else {
+					result.Changes = append(result.Changes, fmt.Sprintf("%s: %s forgets %s and learns %s", label, pokemon.Species, replaced, move))
+				}
+			}
+		}
+
+		// pokemon that store their IVs derive their stats again, hand typed
+		// stats are kept and only scaled by the levels gained and evolutions
+		// taken, so a deliberately weak party stays weak
+		if pokemon.IVs == nil {
+			if scaleHandStats(&pokemon, base.Level, line[0].Stats, speciesByID[pokemon.ID].Stats) {
+				result.Changes = append(result.Changes, fmt.Sprintf("%s: hand typed stats of %s scaled to Lv %d", label, pokemon.Species, pokemon.Level))
+			}
+		} else if stats, ok := speciesByID[pokemon.ID]; ok {
+			core.ApplyStats(&pokemon, stats.Stats)
+		}
+		result.Variant.Pokemons = append(result.Variant.Pokemons, pokemonJson(pokemon))
+	}
+
+	if !options.Save {
+		return result, nil
+	}
+	if err := a.SavePartyVariant(trainerID, result.Variant); err != nil {
+		return result, err
+	}
+	result.Saved = true
+	return result, nil
+}
+
+// scaleHandStats scales the hand typed stats of pokemon from fromLevel to its
+// level and from the base stats of the species it was to those of the one it
+// is, it reports whether a stat changed
+func scaleHandStats(pokemon *coreModels.TrainerPokemon, fromLevel int, from Models.Stats, to Models.Stats) bool {
+	scale := func(value int, fromBase int, toBase int) int {
+		if fromLevel > 0 {
+			value = value * pokemon.Level / fromLevel
+		}
+		if fromBase > 0 && toBase > 0 {
+			value = value * toBase / fromBase
+		}
+		return max(value, 1)
+	}
+	before := pokemon.Pokemons
+	pokemon.HP = scale(pokemon.HP, from.Hp, to.Hp)
+	pokemon.Attack = scale(pokemon.Attack, from.Attack, to.Attack)
+	pokemon.Defense = scale(pokemon.Defense, from.Defense, to.Defense)
+	pokemon.Speed = scale(pokemon.Speed, from.Speed, to.Speed)
+	pokemon.SpecialAttack = scale(pokemon.SpecialAttack, from.SpecialAttack, to.SpecialAttack)
+	pokemon.SpecialDefense = scale(pokemon.SpecialDefense, from.SpecialDefense, to.SpecialDefense)
+	return pokemon.HP != before.HP || pokemon.Attack != before.Attack || pokemon.Defense != before.Defense ||
+		pokemon.Speed != before.Speed || pokemon.SpecialAttack != before.SpecialAttack || pokemon.SpecialDefense != before.SpecialDefense
+}
+
+// levelEvolution returns the level-up evolution species can take at level
+func levelEvolution(species Models.Pokemon, level int) (Models.Evolutions, bool) {
+	for _, evolution := range species.Evolutions {
+		if len(evolution.Methods) < 2 || evolution.Methods[0] != "level-up" {
+			continue
+		}
+		evolutionLevel, err := strconv.Atoi(evolution.Methods[1])
+		if err == nil && evolutionLevel <= level {
+			return evolution, true
+		}
+	}
+	return Models.Evolutions{}, false
+}
+
+// learnedBetween lists the level-up moves the species of line learn after
+// oldLevel up to newLevel, in learning order, that known does not have yet
+func learnedBetween(line []Models.Pokemon, oldLevel int, newLevel int, known []string) []string {
+	var learned []Models.Moves
+	for _, species := range line {
+		for _, move := range species.Moves {
+			if move.Method != "level-up" || move.Level <= oldLevel || move.Level > newLevel {
+				continue
+			}
+			learned = append(learned, move)
+		}
+	}
+	sort.SliceStable(learned, func(i, j int) bool {
+		return learned[i].Level < learned[j].Level
+	})
+	var moves []string
+	for _, move := range learned {
+		if containsFold(known, move.Name) || containsFold(moves, move.Name) {
+			continue
+		}
+		moves = append(moves, move.Name)
+	}
+	return moves
+}
+
+// upgradeMoves teaches move to pokemon. An empty slot is used first, otherwise
+// the level-up move learned earliest is replaced and its name returned. Moves
+// from outside the learnset, e.g. TMs, are kept, so nothing is learned when
+// all four are.
+func upgradeMoves(pokemon *coreModels.TrainerPokemon, move string, line []Models.Pokemon) (string, bool) {
+	if len(pokemon.Moves) < 4 {
+		pokemon.Moves = append(pokemon.Moves, move)
+		return "", true
+	}
+	oldest, oldestLevel := -1, 0
+	for index, known := range pokemon.Moves {
+		for _, species := range line {
+			for _, learned := range species.Moves {
+				if learned.Method != "level-up" || !strings.EqualFold(learned.Name, known) {
+					continue
+				}
+				if oldest == -1 || learned.Level < oldestLevel {
+					oldest, oldestLevel = index, learned.Level
+				}
+			}
+		}
+	}
+	if oldest == -1 {
+		return "", false
+	}
+	replaced := pokemon.Moves[oldest]
+	pokemon.Moves[oldest] = move
+	return replaced, true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// pokemonJson converts a stored party pokemon back to the editor's shape
+func pokemonJson(pokemon coreModels.TrainerPokemon) coreModels.PokemonJson {
+	return coreModels.PokemonJson{
+		Species:        pokemon.Species,
+		Level:          pokemon.Level,
+		Moves:          pokemon.Moves,
+		HeldItem:       pokemon.HeldItem,
+		HP:             pokemon.HP,
+		Attack:         pokemon.Attack,
+		Defense:        pokemon.Defense,
+		Speed:          pokemon.Speed,
+		SpecialAttack:  pokemon.SpecialAttack,
+		SpecialDefense: pokemon.SpecialDefense,
+		ID:             pokemon.ID,
+		IVs:            pokemon.IVs,
+		EVs:            pokemon.EVs,
+		Nature:         pokemon.Nature,
+	}
+}
@@ -0,0 +1,210 @@
+package typeeditor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+type TypeEditorApp struct {
+	app *core.App
+}
+
+// NewTypeEditorApp creates a new TypeEditorApp struct
+func NewTypeEditorApp(app *core.App) *TypeEditorApp {
+	return &TypeEditorApp{
+		app: app,
+	}
+}
+
+// GetTypes lists the project's types with their colors, icons and type chart
+// row, the default types when the project has no types.toml
+func (a *TypeEditorApp) GetTypes() ([]coreModels.TypeDefinition, error) {
+	return core.ProjectTypes(a.app.DataDirectory)
+}
+
+// CreateType adds a type. A project without types.toml gets one holding the
+// default types and the new one.
+func (a *TypeEditorApp) CreateType(definition coreModels.TypeDefinition) error {
+	types := repository.NewTypeRepository(a.app.DataDirectory)
+	definitions, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return err
+	}
+	definition.Name = strings.TrimSpace(definition.Name)
+	if typeIndex(definitions, definition.Name) != -1 {
+		return &repository.Error{Kind: repository.ErrDuplicateID, File: types.File(), ID: definition.Name}
+	}
+	definitions = append(definitions, definition)
+	if err := checkTypes(definitions); err != nil {
+		return err
+	}
+	defer a.app.Journal.Begin(fmt.Sprintf("Create type %s", definition.Name))()
+	return types.Save(coreModels.TypesToml{Types: definitions})
+}
+
+// UpdateType saves the type called name. When definition has a new name
+// every pokemon, move and type chart row is moved to it, the number of
+// pokemon and moves moved is returned.
+func (a *TypeEditorApp) UpdateType(name string, definition coreModels.TypeDefinition) (int, error) {
+	types := repository.NewTypeRepository(a.app.DataDirectory)
+	definitions, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return 0, err
+	}
+	index := typeIndex(definitions, name)
+	if index == -1 {
+		return 0, &repository.Error{Kind: repository.ErrNotFound, File: types.File(), ID: name}
+	}
+	definition.Name = strings.TrimSpace(definition.Name)
+	if other := typeIndex(definitions, definition.Name); other != -1 && other != index {
+		return 0, &repository.Error{Kind: repository.ErrDuplicateID, File: types.File(), ID: definition.Name}
+	}
+	// name may differ from the stored one in case only, the stored one is
+	// what the type chart, pokemon and moves use
+	current := definitions[index].Name
+	renamed := definition.Name != current
+	definitions[index] = definition
+	if renamed {
+		renameDefending(definitions, current, definition.Name)
+	}
+	if err := checkTypes(definitions); err != nil {
+		return 0, err
+	}
+	// Update treats a missing data file as empty, so the files are checked
+	// first to keep a rename from creating empty ones
+	pokemon := repository.NewPokemonRepository(a.app.DataDirectory)
+	moves := repository.NewMoveRepository(a.app.DataDirectory)
+	var pokemonErr, movesErr error
+	if renamed {
+		_, pokemonErr = pokemon.All()
+		_, movesErr = moves.All()
+		for _, err := range []error{pokemonErr, movesErr} {
+			if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+				return 0, err
+			}
+		}
+	}
+
+	defer a.app.Journal.Begin(fmt.Sprintf("Update type %s", current))()
+	if err := types.Save(coreModels.TypesToml{Types: definitions}); err != nil || !renamed {
+		return 0, err
+	}
+	moved := 0
+	if pokemonErr == nil {
+		err = pokemon.Update(func(species *[]Models.Pokemon) error {
+			for index := range *species {
+				for slot, pokemonType := range (*species)[index].Types {
+					if strings.EqualFold(pokemonType, current) {
+						(*species)[index].Types[slot] = definition.Name
+						moved++
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+	}
+	if movesErr == nil {
+		err = moves.Update(func(records *[]Models.Move) error {
+			for index := range *records {
+				if strings.EqualFold((*records)[index].Type, current) {
+					(*records)[index].Type = definition.Name
+					moved++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// DeleteType removes a type and its multipliers from the type chart, it is
+// refused while pokemon or moves still have the type
+func (a *TypeEditorApp) DeleteType(name string) error {
+	types := repository.NewTypeRepository(a.app.DataDirectory)
+	definitions, err := core.ProjectTypes(a.app.DataDirectory)
+	if err != nil {
+		return err
+	}
+	index := typeIndex(definitions, name)
+	if index == -1 {
+		return &repository.Error{Kind: repository.ErrNotFound, File: types.File(), ID: name}
+	}
+	users, err := a.typeUsers(name)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("type %s is still used by %s", name, strings.Join(users, ", "))
+	}
+	definitions = slices.Delete(definitions, index, index+1)
+	renameDefending(definitions, name, "")
+	defer a.app.Journal.Begin(fmt.Sprintf("Delete type %s", name))()
+	return types.Save(coreModels.TypesToml{Types: definitions})
+}
+
+// typeUsers names the species and moves of the given type
+func (a *TypeEditorApp) typeUsers(name string) ([]string, error) {
+	var users []string
+	species, err := repository.NewPokemonRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return nil, err
+	}
+	for _, pokemon := range species {
+		if slices.ContainsFunc(pokemon.Types, func(pokemonType string) bool { return strings.EqualFold(pokemonType, name) }) {
+			users = append(users, pokemon.Species)
+		}
+	}
+	moves, err := repository.NewMoveRepository(a.app.DataDirectory).All()
+	if err != nil && !errors.Is(err, repository.ErrMissingFile) {
+		return nil, err
+	}
+	for _, move := range moves {
+		if strings.EqualFold(move.Type, name) {
+			users = append(users, move.Name)
+		}
+	}
+	return users, nil
+}
+
+func typeIndex(definitions []coreModels.TypeDefinition, name string) int {
+	return slices.IndexFunc(definitions, func(definition coreModels.TypeDefinition) bool {
+		return strings.EqualFold(definition.Name, name)
+	})
+}
+
+// renameDefending moves every type's multiplier against name to newName, an
+// empty newName drops it
+func renameDefending(definitions []coreModels.TypeDefinition, name string, newName string) {
+	for index := range definitions {
+		effectiveness := make(map[string]float64, len(definitions[index].Effectiveness))
+		for defending, multiplier := range definitions[index].Effectiveness {
+			switch {
+			case !strings.EqualFold(defending, name):
+				effectiveness[defending] = multiplier
+			case newName != "":
+				effectiveness[newName] = multiplier
+			}
+		}
+		definitions[index].Effectiveness = effectiveness
+	}
+}
+
+func checkTypes(definitions []coreModels.TypeDefinition) error {
+	if problems := core.CheckTypes(definitions); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,456 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	coreModels "github.com/zenith110/pokemon-engine-tools/models"
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+	Models "github.com/zenith110/pokemon-go-engine-toml-models/models"
+)
+
+// validation holds the loaded project while the checks run
+type validation struct {
+	dataDirectory string
+	diagnostics   []Diagnostic
+
+	pokemon        []Models.Pokemon
+	moves          []Models.Move
+	trainers       []coreModels.Trainer
+	trainerClasses []coreModels.TrainerClass
+	heldItems      []Models.HeldItems
+	maps           []coreModels.Map
+	tilesets       []coreModels.Tileset
+	overworlds     []Models.Overworld
+	types          []coreModels.TypeDefinition
+
+	pokemonIDs     map[string]bool
+	moveNames      map[string]bool
+	heldItemNames  map[string]bool
+	classNames     map[string]bool
+	typeNames      map[string]bool
+	referencedPath map[string]bool
+}
+
+func newValidation(dataDirectory string) *validation {
+	v := &validation{
+		dataDirectory:  dataDirectory,
+		pokemonIDs:     make(map[string]bool),
+		moveNames:      make(map[string]bool),
+		heldItemNames:  make(map[string]bool),
+		classNames:     make(map[string]bool),
+		typeNames:      make(map[string]bool),
+		referencedPath: make(map[string]bool),
+	}
+	var err error
+	if v.pokemon, err = repository.NewPokemonRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/pokemon.toml", err)
+	}
+	if v.moves, err = repository.NewMoveRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/moves.toml", err)
+	}
+	if v.trainers, err = repository.NewTrainerRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/trainers.toml", err)
+	}
+	if v.trainerClasses, err = repository.NewTrainerClassRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/trainerclasses.toml", err)
+	}
+	if v.heldItems, err = repository.NewHeldItemRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/helditems.toml", err)
+	}
+	if v.maps, err = repository.NewMapRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/maps.toml", err)
+	}
+	if v.tilesets, err = repository.NewTilesetRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/toml/tilesets.toml", err)
+	}
+	if v.overworlds, err = repository.NewOverworldRepository(dataDirectory).All(); err != nil {
+		v.loadFailed("data/assets/toml/overworlds.toml", err)
+	}
+	if v.types, err = core.ProjectTypes(dataDirectory); err != nil {
+		v.loadFailed("data/toml/types.toml", err)
+	}
+
+	for _, pokemon := range v.pokemon {
+		v.pokemonIDs[pokemon.ID] = true
+	}
+	for _, move := range v.moves {
+		v.moveNames[strings.ToLower(move.Name)] = true
+	}
+	for _, heldItem := range v.heldItems {
+		v.heldItemNames[strings.ToLower(heldItem.Name)] = true
+	}
+	for _, trainerClass := range v.trainerClasses {
+		v.classNames[trainerClass.Name] = true
+	}
+	for _, definition := range v.types {
+		v.typeNames[strings.ToLower(definition.Name)] = true
+	}
+	return v
+}
+
+// pokemonAssets are the files every species needs, relative to the project
+func pokemonAssets(id string) map[string]string {
+	return map[string]string{
+		"front":      fmt.Sprintf("data/assets/pokemon/front/%s_front.png", id),
+		"back":       fmt.Sprintf("data/assets/pokemon/back/%s_back.png", id),
+		"shinyFront": fmt.Sprintf("data/assets/pokemon/shinyfront/%s_front_shiny.png", id),
+		"shinyBack":  fmt.Sprintf("data/assets/pokemon/shinyback/%s_shiny_back.png", id),
+		"icon":       fmt.Sprintf("data/assets/pokemon/icons/%s/%s.gif", id, id),
+		"cry":        fmt.Sprintf("data/assets/pokemon/cries/%s.wav", id),
+	}
+}
+
+func (v *validation) checkPokemon() {
+	const file = "data/toml/pokemon.toml"
+	var ids []string
+	for _, pokemon := range v.pokemon {
+		ids = append(ids, pokemon.ID)
+		if pokemon.Species == "" {
+			v.add(SeverityError, CodeOutOfRange, file, pokemon.ID, "species", "species name is empty")
+		}
+		v.checkRange(file, pokemon.ID, "stats.hp", pokemon.Stats.Hp, 1, 255)
+		v.checkRange(file, pokemon.ID, "stats.attack", pokemon.Stats.Attack, 1, 255)
+		v.checkRange(file, pokemon.ID, "stats.defense", pokemon.Stats.Defense, 1, 255)
+		v.checkRange(file, pokemon.ID, "stats.special-attack", pokemon.Stats.SpecialAttack, 1, 255)
+		v.checkRange(file, pokemon.ID, "stats.special-defense", pokemon.Stats.SpecialDefense, 1, 255)
+		v.checkRange(file, pokemon.ID, "stats.speed", pokemon.Stats.Speed, 1, 255)
+		for _, pokemonType := range pokemon.Types {
+			v.checkType(file, pokemon.ID, "types", pokemonType)
+		}
+		for _, move := range pokemon.Moves {
+			if !v.moveNames[strings.ToLower(move.Name)] {
+				v.add(SeverityError, CodeBrokenReference, file, pokemon.ID, "moves", fmt.Sprintf("learnset move %q does not exist", move.Name))
+			}
+			v.checkRange(file, pokemon.ID, "moves.level", move.Level, 0, 100)
+		}
+		for _, evolution := range pokemon.Evolutions {
+			if !v.pokemonIDs[evolution.PokemonID] {
+				v.add(SeverityError, CodeBrokenReference, file, pokemon.ID, "evolutions.pokemonId", fmt.Sprintf("evolves into unknown pokemon %q", evolution.PokemonID))
+			}
+		}
+		for field, path := range pokemonAssets(pokemon.ID) {
+			v.referencedPath[path] = true
+			if !v.exists(path) {
+				severity := SeverityError
+				if strings.HasPrefix(field, "shiny") {
+					severity = SeverityWarning
+				}
+				v.add(severity, CodeMissingAsset, file, pokemon.ID, field, fmt.Sprintf("%s is missing", path))
+			}
+		}
+	}
+	v.checkDuplicates(file, ids)
+}
+
+func (v *validation) checkMoves() {
+	const file = "data/toml/moves.toml"
+	var ids []string
+	for _, move := range v.moves {
+		id := strconv.Itoa(move.ID)
+		ids = append(ids, id)
+		v.checkRange(file, id, "Power", move.Power, 0, 255)
+		v.checkRange(file, id, "accuracy", move.Accuracy, 0, 100)
+		v.checkRange(file, id, "pp", move.Pp, 1, 64)
+		v.checkType(file, id, "type", move.Type)
+	}
+	v.checkDuplicates(file, ids)
+}
+
+// checkType reports a type that is neither in types.toml nor, for a project
+// without it, one of the default types. An empty type is not checked.
+func (v *validation) checkType(file string, record string, field string, name string) {
+	if name != "" && !v.typeNames[strings.ToLower(name)] {
+		v.add(SeverityError, CodeBrokenReference, file, record, field, fmt.Sprintf("type %q does not exist", name))
+	}
+}
+
+func (v *validation) checkTypes() {
+	const file = "data/toml/types.toml"
+	for _, problem := range core.CheckTypes(v.types) {
+		v.add(SeverityError, CodeOutOfRange, file, "", "", problem)
+	}
+}
+
+func (v *validation) checkTrainers() {
+	const file = "data/toml/trainers.toml"
+	var ids []string
+	for _, trainer := range v.trainers {
+		ids = append(ids, trainer.ID)
+		if trainer.ClassType != "" && !v.classNames[trainer.ClassType] {
+			v.add(SeverityError, CodeBrokenReference, file, trainer.ID, "classType", fmt.Sprintf("trainer class %q does not exist", trainer.ClassType))
+		}
+		if trainer.Sprite != "" {
+			path := "data/assets/trainers_sprite/" + trainer.Sprite
+			v.referencedPath[path] = true
+			if !v.exists(path) {
+				v.add(SeverityError, CodeMissingAsset, file, trainer.ID, "sprite", fmt.Sprintf("%s is missing", path))
+			}
+		}
+		if len(trainer.Pokemons) == 0 {
+			v.add(SeverityWarning, CodeOutOfRange, file, trainer.ID, "pokemon", "trainer has no pokemon")
+		}
+		v.checkParty(file, trainer.ID, "pokemon", trainer.Pokemons)
+		variants := make(map[string]bool)
+		for _, variant := range trainer.Variants {
+			if variant.Name == "" {
+				v.add(SeverityError, CodeOutOfRange, file, trainer.ID, "variants.name", "party variant has no name")
+				continue
+			}
+			if variants[variant.Name] {
+				v.add(SeverityError, CodeDuplicateID, file, trainer.ID, "variants.name", fmt.Sprintf("party variant %q is defined more than once", variant.Name))
+			}
+			variants[variant.Name] = true
+			v.checkParty(file, trainer.ID, fmt.Sprintf("variants.%s.pokemon", variant.Name), variant.Pokemons)
+		}
+		v.checkAI(file, trainer.ID, trainer.AI)
+	}
+	v.checkDuplicates(file, ids)
+}
+
+// checkParty checks a trainer's party, field is where the party is stored
+// in the trainer, e.g. pokemon or the pokemon of a variant
+func (v *validation) checkParty(file string, record string, field string, party []coreModels.TrainerPokemon) {
+	if len(party) > 6 {
+		v.add(SeverityError, CodeOutOfRange, file, record, field, fmt.Sprintf("party has %d pokemon, at most 6 are allowed", len(party)))
+	}
+	for _, pokemon := range party {
+		if !v.pokemonIDs[pokemon.ID] {
+			v.add(SeverityError, CodeBrokenReference, file, record, field+".id", fmt.Sprintf("party pokemon %q (%s) does not exist", pokemon.ID, pokemon.Species))
+		}
+		v.checkRange(file, record, field+".level", pokemon.Level, 1, 100)
+		if len(pokemon.Moves) > 4 {
+			v.add(SeverityError, CodeOutOfRange, file, record, field+".moves", fmt.Sprintf("%s knows %d moves, at most 4 are allowed", pokemon.Species, len(pokemon.Moves)))
+		}
+		for _, move := range pokemon.Moves {
+			if move != "" && !v.moveNames[strings.ToLower(move)] {
+				v.add(SeverityError, CodeBrokenReference, file, record, field+".moves", fmt.Sprintf("move %q of %s does not exist", move, pokemon.Species))
+			}
+		}
+		if pokemon.HeldItem != "" && !v.heldItemNames[strings.ToLower(pokemon.HeldItem)] {
+			v.add(SeverityError, CodeBrokenReference, file, record, field+".heldItem", fmt.Sprintf("held item %q of %s does not exist", pokemon.HeldItem, pokemon.Species))
+		}
+		v.checkSpread(file, record, field, pokemon)
+	}
+}
+
+// checkSpread checks the IVs, EVs and nature a party pokemon's stats are
+// derived from
+func (v *validation) checkSpread(file string, record string, field string, pokemon coreModels.TrainerPokemon) {
+	if pokemon.Nature != "" {
+		if _, ok := core.Natures[strings.ToLower(pokemon.Nature)]; !ok {
+			v.add(SeverityError, CodeOutOfRange, file, record, field+".nature", fmt.Sprintf("nature %q of %s does not exist", pokemon.Nature, pokemon.Species))
+		}
+	}
+	if pokemon.IVs != nil {
+		for _, stat := range spreadValues(*pokemon.IVs) {
+			v.checkRange(file, record, field+".ivs."+stat.field, stat.value, 0, core.MaxIV)
+		}
+	}
+	if pokemon.EVs != nil {
+		total := 0
+		for _, stat := range spreadValues(*pokemon.EVs) {
+			v.checkRange(file, record, field+".evs."+stat.field, stat.value, 0, core.MaxEV)
+			total += stat.value
+		}
+		if total > core.MaxTotalEVs {
+			v.add(SeverityError, CodeOutOfRange, file, record, field+".evs", fmt.Sprintf("%s has %d EVs, at most %d are allowed", pokemon.Species, total, core.MaxTotalEVs))
+		}
+	}
+	if pokemon.IVs == nil && (pokemon.EVs != nil || pokemon.Nature != "") {
+		v.add(SeverityWarning, CodeOutOfRange, file, record, field+".ivs", fmt.Sprintf("%s has EVs or a nature but no IVs, its stats are not derived from them", pokemon.Species))
+	}
+}
+
+type spreadValue struct {
+	field string
+	value int
+}
+
+func spreadValues(spread coreModels.StatSpread) []spreadValue {
+	return []spreadValue{
+		{"hp", spread.HP},
+		{"attack", spread.Attack},
+		{"defense", spread.Defense},
+		{"speed", spread.Speed},
+		{"specialAttack", spread.SpecialAttack},
+		{"specialDefense", spread.SpecialDefense},
+	}
+}
+
+func (v *validation) checkTrainerClasses() {
+	const file = "data/toml/trainerclasses.toml"
+	var names []string
+	for _, trainerClass := range v.trainerClasses {
+		names = append(names, trainerClass.Name)
+		v.checkMusic(file, trainerClass.Name, "music", trainerClass.Music)
+		v.checkMusic(file, trainerClass.Name, "encounterMusic", trainerClass.EncounterMusic)
+		v.checkRange(file, trainerClass.Name, "prizeMultiplier", trainerClass.PrizeMultiplier, 0, 255)
+		if trainerClass.Sprite != "" {
+			path := "data/assets/trainers_sprite/" + trainerClass.Sprite
+			v.referencedPath[path] = true
+			if !v.exists(path) {
+				v.add(SeverityError, CodeMissingAsset, file, trainerClass.Name, "sprite", fmt.Sprintf("%s is missing", path))
+			}
+		}
+		v.checkAI(file, trainerClass.Name, trainerClass.AI)
+	}
+	v.checkDuplicates(file, names)
+}
+
+// checkAI checks an AI profile's flags, switch mode and items
+func (v *validation) checkAI(file string, record string, profile *coreModels.AIProfile) {
+	if profile == nil {
+		return
+	}
+	for _, problem := range core.CheckAIProfile(*profile) {
+		v.add(SeverityError, CodeOutOfRange, file, record, "ai", problem)
+	}
+	for _, item := range profile.Items {
+		if item.Name != "" && !v.heldItemNames[strings.ToLower(item.Name)] {
+			v.add(SeverityError, CodeBrokenReference, file, record, "ai.items", fmt.Sprintf("AI item %q does not exist", item.Name))
+		}
+	}
+}
+
+func (v *validation) checkHeldItems() {
+	const file = "data/toml/helditems.toml"
+	var names []string
+	for _, heldItem := range v.heldItems {
+		names = append(names, heldItem.Name)
+	}
+	v.checkDuplicates(file, names)
+}
+
+func (v *validation) checkMaps() {
+	const file = "data/toml/maps.toml"
+	var ids, names []string
+	for _, mapData := range v.maps {
+		id := strconv.Itoa(mapData.ID)
+		ids = append(ids, id)
+		names = append(names, mapData.Name)
+		if mapData.Width <= 0 || mapData.Height <= 0 {
+			v.add(SeverityError, CodeOutOfRange, file, id, "width", fmt.Sprintf("map size %dx%d is not positive", mapData.Width, mapData.Height))
+		}
+		for _, properties := range mapData.Properties {
+			if properties.FilePath != "" {
+				v.referencedPath[properties.FilePath] = true
+				if !v.exists(properties.FilePath) {
+					v.add(SeverityError, CodeMissingAsset, file, id, "properties.filePath", fmt.Sprintf("%s is missing", properties.FilePath))
+				}
+			}
+			if properties.TilesetImagePath != "" && !v.exists(properties.TilesetImagePath) {
+				v.add(SeverityError, CodeMissingAsset, file, id, "properties.tilesetImagePath", fmt.Sprintf("%s is missing", properties.TilesetImagePath))
+			}
+			v.checkMusic(file, id, "music", properties.BgMusic)
+		}
+		for _, encounter := range mapData.GrassEncounters {
+			v.checkEncounter(file, id, "grassEncounters", encounter.ID, encounter.MinLevel, encounter.MaxLevel, encounter.Rarity)
+		}
+		for _, encounter := range mapData.WaterEncounters {
+			v.checkEncounter(file, id, "waterEncounters", encounter.ID, encounter.MinLevel, encounter.MaxLevel, encounter.Rarity)
+		}
+		for _, encounter := range mapData.CaveEncounters {
+			v.checkEncounter(file, id, "caveEncounters", encounter.ID, encounter.MinLevel, encounter.MaxLevel, encounter.Rarity)
+		}
+		for _, encounter := range mapData.FishingEncounters {
+			v.checkEncounter(file, id, "fishingEncounters", encounter.ID, encounter.MinLevel, encounter.MaxLevel, encounter.Rarity)
+		}
+	}
+	v.checkDuplicates(file, ids)
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			v.add(SeverityError, CodeDuplicateID, file, name, "name", fmt.Sprintf("map name %s is used more than once, their JSON files collide", name))
+		}
+		seen[name] = true
+	}
+}
+
+func (v *validation) checkEncounter(file string, mapID string, field string, pokemonID string, minLevel int, maxLevel int, rarity int) {
+	if !v.pokemonIDs[pokemonID] {
+		v.add(SeverityError, CodeBrokenReference, file, mapID, field+".id", fmt.Sprintf("encounter pokemon %q does not exist", pokemonID))
+	}
+	v.checkRange(file, mapID, field+".minLevel", minLevel, 1, 100)
+	v.checkRange(file, mapID, field+".maxLevel", maxLevel, 1, 100)
+	if minLevel > maxLevel {
+		v.add(SeverityError, CodeOutOfRange, file, mapID, field+".minLevel", fmt.Sprintf("encounter %s has minLevel %d above maxLevel %d", pokemonID, minLevel, maxLevel))
+	}
+	v.checkRange(file, mapID, field+".rarity", rarity, 0, 100)
+}
+
+func (v *validation) checkMusic(file string, record string, field string, music string) {
+	if music == "" {
+		return
+	}
+	path := "data/assets/music/" + music
+	v.referencedPath[path] = true
+	if !v.exists(path) {
+		v.add(SeverityError, CodeMissingAsset, file, record, field, fmt.Sprintf("%s is missing", path))
+	}
+}
+
+func (v *validation) checkTilesets() {
+	const file = "data/toml/tilesets.toml"
+	var names []string
+	for _, tileset := range v.tilesets {
+		names = append(names, tileset.Name)
+		v.referencedPath[tileset.Path] = true
+		if !v.exists(tileset.Path) {
+			v.add(SeverityError, CodeMissingAsset, file, tileset.Name, "path", fmt.Sprintf("%s is missing", tileset.Path))
+		}
+	}
+	v.checkDuplicates(file, names)
+}
+
+func (v *validation) checkOverworlds() {
+	const file = "data/assets/toml/overworlds.toml"
+	var ids []string
+	for _, overworld := range v.overworlds {
+		ids = append(ids, overworld.ID)
+	}
+	v.checkDuplicates(file, ids)
+}
+
+// checkOrphanedAssets reports asset files nothing in the data files refers to
+func (v *validation) checkOrphanedAssets() {
+	for _, folder := range []string{
+		"data/assets/pokemon/front",
+		"data/assets/pokemon/back",
+		"data/assets/pokemon/shinyfront",
+		"data/assets/pokemon/shinyback",
+		"data/assets/pokemon/cries",
+		"data/assets/trainers_sprite",
+		"data/assets/music",
+		"data/assets/maps",
+		"data/assets/tilesets",
+	} {
+		entries, err := os.ReadDir(fmt.Sprintf("%s/%s", v.dataDirectory, folder))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := folder + "/" + entry.Name()
+			if entry.IsDir() || strings.HasSuffix(path, ".bak") || v.referencedPath[path] {
+				continue
+			}
+			v.add(SeverityWarning, CodeOrphanedAsset, path, "", "", "no data file refers to this asset")
+		}
+	}
+	icons, err := os.ReadDir(fmt.Sprintf("%s/data/assets/pokemon/icons", v.dataDirectory))
+	if err == nil {
+		for _, icon := range icons {
+			if icon.IsDir() && !v.pokemonIDs[icon.Name()] {
+				v.add(SeverityWarning, CodeOrphanedAsset, "data/assets/pokemon/icons/"+icon.Name(), "", "", "icon folder belongs to no pokemon")
+			}
+		}
+	}
+}
+
+func (v *validation) exists(path string) bool {
+	_, err := os.Stat(fmt.Sprintf("%s/%s", v.dataDirectory, path))
+	return err == nil
+}
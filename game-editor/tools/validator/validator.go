@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zenith110/pokemon-engine-tools/repository"
+	core "github.com/zenith110/pokemon-engine-tools/tools-core"
+)
+
+// Severity tells whether a diagnostic breaks the game or only looks wrong
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic codes
+const (
+	CodeMissingFile     = "missing-file"
+	CodeMalformedFile   = "malformed-file"
+	CodeDuplicateID     = "duplicate-id"
+	CodeBrokenReference = "broken-reference"
+	CodeOutOfRange      = "out-of-range"
+	CodeMissingAsset    = "missing-asset"
+	CodeOrphanedAsset   = "orphaned-asset"
+)
+
+// Diagnostic is one problem found in the project. File is relative to the
+// project, Record is the ID or name of the offending record if any.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	File     string   `json:"file"`
+	Record   string   `json:"record"`
+	Field    string   `json:"field"`
+	Message  string   `json:"message"`
+}
+
+// Report is the result of validating a whole project
+type Report struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	Errors      int          `json:"errors"`
+	Warnings    int          `json:"warnings"`
+}
+
+// HasErrors reports whether the project failed validation
+func (r Report) HasErrors() bool {
+	return r.Errors > 0
+}
+
+type ValidatorApp struct {
+	app *core.App
+}
+
+// NewValidatorApp creates a new ValidatorApp struct
+func NewValidatorApp(app *core.App) *ValidatorApp {
+	return &ValidatorApp{
+		app: app,
+	}
+}
+
+// ValidateProject checks the current project for broken references,
+// duplicate IDs, out of range values and missing or orphaned assets
+func (a *ValidatorApp) ValidateProject() (Report, error) {
+	return Validate(a.app.DataDirectory)
+}
+
+// Validate loads every data file of the project at dataDirectory and checks
+// them against each other and against the assets on disk
+func Validate(dataDirectory string) (Report, error) {
+	if dataDirectory == "" {
+		return Report{}, errors.New("no project selected")
+	}
+	v := newValidation(dataDirectory)
+	v.checkPokemon()
+	v.checkMoves()
+	v.checkTypes()
+	v.checkTrainers()
+	v.checkTrainerClasses()
+	v.checkHeldItems()
+	v.checkMaps()
+	v.checkTilesets()
+	v.checkOverworlds()
+	v.checkOrphanedAssets()
+	return v.report(), nil
+}
+
+// loadFailed records why a data file, given relative to the project, could
+// not be checked
+func (v *validation) loadFailed(file string, err error) {
+	if errors.Is(err, repository.ErrMissingFile) {
+		v.add(SeverityWarning, CodeMissingFile, file, "", "", "data file does not exist")
+		return
+	}
+	v.add(SeverityError, CodeMalformedFile, file, "", "", err.Error())
+}
+
+func (v *validation) add(severity Severity, code string, file string, record string, field string, message string) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		File:     file,
+		Record:   record,
+		Field:    field,
+		Message:  message,
+	})
+}
+
+func (v *validation) report() Report {
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		if v.diagnostics[i].File != v.diagnostics[j].File {
+			return v.diagnostics[i].File < v.diagnostics[j].File
+		}
+		if v.diagnostics[i].Record != v.diagnostics[j].Record {
+			return v.diagnostics[i].Record < v.diagnostics[j].Record
+		}
+		return v.diagnostics[i].Field < v.diagnostics[j].Field
+	})
+	report := Report{Diagnostics: v.diagnostics}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []Diagnostic{}
+	}
+	for _, diagnostic := range report.Diagnostics {
+		if diagnostic.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// checkRange reports value outside of [min, max]
+func (v *validation) checkRange(file string, record string, field string, value int, min int, max int) {
+	if value < min || value > max {
+		v.add(SeverityError, CodeOutOfRange, file, record, field, fmt.Sprintf("%s is %d, expected %d to %d", field, value, min, max))
+	}
+}
+
+// checkDuplicates reports every ID that occurs more than once
+func (v *validation) checkDuplicates(file string, ids []string) {
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			v.add(SeverityError, CodeDuplicateID, file, id, "id", fmt.Sprintf("ID %s is used by more than one record", id))
+		}
+		seen[id] = true
+	}
+}